@@ -15,6 +15,10 @@
 
 const (
 	NextSteps = iota
+	// PreRun hooks are invoked before a CLI command is executed, and
+	// can deny its execution, or amend it by setting flags and
+	// environment variables.
+	PreRun
 )
 
 // HookMessage represents a plugin hook response. Plugins
@@ -24,6 +28,22 @@
 type HookMessage struct {
 	Type     HookType
 	Template string
+
+	// The fields below are only used in responses to PreRun hooks.
+
+	// Deny prevents the command from being executed.
+	Deny bool `json:",omitempty"`
+	// Message is printed before the command is executed, or is
+	// returned as the reason for denying the command.
+	Message string `json:",omitempty"`
+	// Flags are flags to set on the command before it is executed,
+	// keyed by the (long) flag name. Flags are not set for commands
+	// that are provided by a plugin.
+	Flags map[string]string `json:",omitempty"`
+	// Env are environment variables ("KEY=value") to set for the
+	// execution of the command. Environment variables are only set for
+	// commands that are provided by a plugin.
+	Env []string `json:",omitempty"`
 }
 
 // TemplateReplaceSubcommandName returns a hook template string
@@ -1,6 +1,9 @@
 package manager
 
-import "os/exec"
+import (
+	"os"
+	"os/exec"
+)
 
 // Candidate represents a possible plugin candidate, for mocking purposes
 type Candidate interface {
@@ -16,6 +19,11 @@ func (c *candidate) Path() string {
 	return c.path
 }
 
+// Metadata runs the metadata subcommand of the candidate. The permissions of
+// the plugin are not known yet, so it is run with the environment of a plugin
+// that declares no permissions.
 func (c *candidate) Metadata() ([]byte, error) {
-	return exec.Command(c.path, MetadataSubcommandName).Output() // #nosec G204 -- ignore "Subprocess launched with a potential tainted input or cmd arguments"
+	cmd := exec.Command(c.path, MetadataSubcommandName) // #nosec G204 -- ignore "Subprocess launched with a potential tainted input or cmd arguments"
+	cmd.Env = (&Permissions{}).filterEnv(os.Environ())
+	return cmd.Output()
 }
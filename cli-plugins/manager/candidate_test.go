@@ -3,9 +3,11 @@
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/api"
 	"github.com/spf13/cobra"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
@@ -41,6 +43,9 @@ func TestValidateCandidate(t *testing.T) {
 		metaExperimental = `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "Experimental": true}`
 	)
 
+	defer func(v string) { cliVersion = v }(cliVersion)
+	cliVersion = "27.1.0"
+
 	fakeroot := &cobra.Command{Use: "docker"}
 	fakeroot.AddCommand(&cobra.Command{
 		Use: strings.TrimPrefix(builtinName, NamePrefix),
@@ -69,7 +74,13 @@ func TestValidateCandidate(t *testing.T) {
 		{name: "invalid schemaversion", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "xyzzy"}`}, invalid: `plugin SchemaVersion "xyzzy" is not valid`},
 		{name: "no vendor", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0"}`}, invalid: "plugin metadata does not define a vendor"},
 		{name: "empty vendor", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": ""}`}, invalid: "plugin metadata does not define a vendor"},
+		{name: "unsupported os", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "OperatingSystems": ["plan9"]}`}, invalid: "plugin only supports plan9, but the Docker CLI is running on " + runtime.GOOS},
+		{name: "cli too old", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "MinimumCLIVersion": "28.0.0"}`}, invalid: "plugin requires Docker CLI version 28.0.0 or newer, but the Docker CLI version is 27.1.0"},
+		{name: "cli too new", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "MaximumCLIVersion": "26.1.4"}`}, invalid: "plugin supports Docker CLI version 26.1.4 or older, but the Docker CLI version is 27.1.0"},
+		{name: "invalid cli version", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "MinimumCLIVersion": "latest"}`}, invalid: `plugin metadata has an invalid MinimumCLIVersion "latest"`},
+		{name: "api too new", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "MinimumAPIVersion": "9.99"}`}, invalid: "plugin requires API version 9.99, but the Docker CLI supports API version " + api.DefaultVersion + " or older"},
 		// This one should work
+		{name: "compatible", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing", "MinimumCLIVersion": "v27.0.0-rc.1", "MaximumCLIVersion": "27.1", "MinimumAPIVersion": "1.44", "OperatingSystems": ["` + runtime.GOOS + `"]}`}},
 		{name: "valid", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing"}`}},
 		{name: "experimental + allowing experimental", c: &fakeCandidate{path: goodPluginPath, exec: true, meta: metaExperimental}},
 	} {
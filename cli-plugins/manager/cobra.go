@@ -64,6 +64,14 @@ func AddPluginCommandStubs(dockerCli command.Cli, rootCmd *cobra.Command) (err e
 			if p.Err != nil {
 				annotations[CommandAnnotationPluginInvalid] = p.Err.Error()
 			}
+			// Annotate the requirements on the daemon, so that the stubs
+			// are hidden like built-in commands with these requirements.
+			if p.MinimumAPIVersion != "" {
+				annotations["version"] = p.MinimumAPIVersion
+			}
+			if p.Experimental {
+				annotations["experimental"] = ""
+			}
 			rootCmd.AddCommand(&cobra.Command{
 				Use:                p.Name,
 				Short:              p.ShortDescription,
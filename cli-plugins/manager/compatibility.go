@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/version"
+	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/pkg/errors"
+)
+
+var (
+	// cliVersion is the version of the Docker CLI that plugins are
+	// checked against. It is a variable for testing purposes.
+	cliVersion = version.Version
+
+	// versionRe matches the versions that plugins can declare, and the
+	// version of the Docker CLI. Pre-release and build information is
+	// ignored, so that "28.0.0-rc.1" is treated as "28.0.0".
+	versionRe = regexp.MustCompile(`^v?([0-9]+(?:\.[0-9]+)*)(?:[-+].*)?$`)
+)
+
+// pluginIncompatibleError is the cause of the error that is set as Plugin.Err
+// if the plugin declares requirements that are not met.
+type pluginIncompatibleError string
+
+func (e pluginIncompatibleError) Error() string {
+	return string(e)
+}
+
+func newIncompatibleError(msg string, args ...interface{}) error {
+	return &pluginError{cause: pluginIncompatibleError(fmt.Sprintf(msg, args...))}
+}
+
+// IsIncompatible is true if the given error is due to a plugin declaring
+// requirements that are not met by the Docker CLI or the Docker daemon.
+func IsIncompatible(err error) bool {
+	var e pluginIncompatibleError
+	return errors.As(err, &e)
+}
+
+// parseVersion returns the numeric part of the version, or false if the
+// version is not valid.
+func parseVersion(v string) (string, bool) {
+	m := versionRe.FindStringSubmatch(v)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// checkCompatibility checks the requirements that the plugin declares in its
+// metadata that do not depend on the Docker daemon, and returns a pluginError
+// describing why the plugin cannot be used with this Docker CLI, if any.
+// Incompatible plugins are marked with an error for which IsIncompatible
+// returns true.
+func checkCompatibility(meta Metadata) error {
+	if len(meta.OperatingSystems) > 0 && !containsString(meta.OperatingSystems, runtime.GOOS) {
+		return newIncompatibleError("plugin only supports %s, but the Docker CLI is running on %s", strings.Join(meta.OperatingSystems, ", "), runtime.GOOS)
+	}
+
+	minCLI, maxCLI := meta.MinimumCLIVersion, meta.MaximumCLIVersion
+	if minCLI != "" {
+		var ok bool
+		if minCLI, ok = parseVersion(minCLI); !ok {
+			return NewPluginError("plugin metadata has an invalid MinimumCLIVersion %q", meta.MinimumCLIVersion)
+		}
+	}
+	if maxCLI != "" {
+		var ok bool
+		if maxCLI, ok = parseVersion(maxCLI); !ok {
+			return NewPluginError("plugin metadata has an invalid MaximumCLIVersion %q", meta.MaximumCLIVersion)
+		}
+	}
+	// Development builds of the Docker CLI have no version to check against.
+	if current, ok := parseVersion(cliVersion); ok {
+		if minCLI != "" && versions.LessThan(current, minCLI) {
+			return newIncompatibleError("plugin requires Docker CLI version %s or newer, but the Docker CLI version is %s", meta.MinimumCLIVersion, cliVersion)
+		}
+		if maxCLI != "" && versions.GreaterThan(current, maxCLI) {
+			return newIncompatibleError("plugin supports Docker CLI version %s or older, but the Docker CLI version is %s", meta.MaximumCLIVersion, cliVersion)
+		}
+	}
+
+	if meta.MinimumAPIVersion != "" {
+		minAPI, ok := parseVersion(meta.MinimumAPIVersion)
+		if !ok {
+			return NewPluginError("plugin metadata has an invalid MinimumAPIVersion %q", meta.MinimumAPIVersion)
+		}
+		if versions.LessThan(api.DefaultVersion, minAPI) {
+			return newIncompatibleError("plugin requires API version %s, but the Docker CLI supports API version %s or older", meta.MinimumAPIVersion, api.DefaultVersion)
+		}
+	}
+	return nil
+}
+
+// checkDaemonRequirements checks the requirements that the plugin declares in
+// its metadata that depend on the Docker daemon. The daemon is only contacted
+// if the plugin declares such requirements.
+func checkDaemonRequirements(dockerCli command.Cli, meta Metadata) error {
+	if meta.MinimumAPIVersion != "" && versions.LessThan(dockerCli.CurrentVersion(), meta.MinimumAPIVersion) {
+		return newIncompatibleError("plugin requires API version %s, but the Docker daemon API version is %s", meta.MinimumAPIVersion, dockerCli.CurrentVersion())
+	}
+	if meta.Experimental && !dockerCli.ServerInfo().HasExperimental {
+		return newIncompatibleError("plugin requires a Docker daemon with experimental features enabled")
+	}
+	return nil
+}
+
+// supportsHook returns whether the plugin supports the given hook capability.
+// Plugins that do not declare their hook capabilities are assumed to support
+// all hooks.
+func (p *Plugin) supportsHook(capability string) bool {
+	return len(p.Hooks) == 0 || containsString(p.Hooks, capability)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
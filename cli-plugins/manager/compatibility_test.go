@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestCheckCompatibilityDevelopmentVersion(t *testing.T) {
+	defer func(v string) { cliVersion = v }(cliVersion)
+	cliVersion = "unknown-version"
+
+	err := checkCompatibility(Metadata{MinimumCLIVersion: "99.0.0", MaximumCLIVersion: "1.0.0"})
+	assert.Check(t, err)
+}
+
+func TestIsIncompatible(t *testing.T) {
+	defer func(v string) { cliVersion = v }(cliVersion)
+	cliVersion = "27.1.0"
+
+	err := checkCompatibility(Metadata{MinimumCLIVersion: "28.0.0"})
+	assert.Check(t, IsIncompatible(err))
+	assert.Check(t, !IsNotFound(err))
+
+	err = checkCompatibility(Metadata{MinimumCLIVersion: "not-a-version"})
+	assert.Check(t, is.ErrorContains(err, "invalid MinimumCLIVersion"))
+	assert.Check(t, !IsIncompatible(err))
+}
+
+func TestCheckDaemonRequirements(t *testing.T) {
+	cli := test.NewFakeCli(nil)
+	assert.Check(t, checkDaemonRequirements(cli, Metadata{}))
+
+	err := checkDaemonRequirements(cli, Metadata{MinimumAPIVersion: "9.99"})
+	assert.Check(t, is.ErrorContains(err, "plugin requires API version 9.99, but the Docker daemon API version is "))
+	assert.Check(t, IsIncompatible(err))
+
+	err = checkDaemonRequirements(cli, Metadata{Experimental: true})
+	assert.Check(t, is.Error(err, "plugin requires a Docker daemon with experimental features enabled"))
+	assert.Check(t, IsIncompatible(err))
+}
+
+func TestSupportsHook(t *testing.T) {
+	p := Plugin{}
+	assert.Check(t, p.supportsHook(HookCapabilityNextSteps))
+	assert.Check(t, p.supportsHook(HookCapabilityPreRun))
+
+	p.Hooks = []string{HookCapabilityNextSteps}
+	assert.Check(t, p.supportsHook(HookCapabilityNextSteps))
+	assert.Check(t, !p.supportsHook(HookCapabilityPreRun))
+}
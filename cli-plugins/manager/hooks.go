@@ -3,26 +3,64 @@
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli-plugins/hooks"
 	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
+const (
+	// preRunHooksKey is the key in the configuration of a plugin that
+	// holds the comma-separated list of commands for which the plugin's
+	// pre-run hook is invoked.
+	preRunHooksKey = "pre-run-hooks"
+
+	// preRunHooksTimeoutKey is the key in the configuration of a plugin
+	// that holds how long the plugin's pre-run hook may take, such as "10s".
+	preRunHooksTimeoutKey = "pre-run-hooks-timeout"
+
+	// defaultPreRunHooksTimeout is how long a pre-run hook may take if no
+	// timeout is configured for the plugin.
+	defaultPreRunHooksTimeout = 5 * time.Second
+)
+
 // HookPluginData is the type representing the information
 // that plugins declaring support for hooks get passed when
-// being invoked following a CLI command execution.
+// being invoked following a CLI command execution, or, for
+// pre-run hooks, before a CLI command is executed.
 type HookPluginData struct {
+	// Type is the type of hook that is being invoked. It is omitted for
+	// NextSteps hooks, which are invoked after the command was executed.
+	Type hooks.HookType `json:",omitempty"`
 	// RootCmd is a string representing the matching hook configuration
 	// which is currently being invoked. If a hook for `docker context` is
 	// configured and the user executes `docker context ls`, the plugin will
 	// be invoked with `context`.
-	RootCmd      string
+	RootCmd string
+	// Flags are the flags that were set on the command. Only the values of
+	// boolean flags are included, as the values of other flags may hold
+	// secrets, such as passwords.
 	Flags        map[string]string
 	CommandError string
+
+	// Command is the command that is about to be executed, such as
+	// `container run`. It is only set for PreRun hooks.
+	Command string `json:",omitempty"`
+	// Args are the arguments of the command that is about to be executed.
+	// For commands provided by a plugin, Args holds all arguments that are
+	// passed to the plugin, including flags, as these are not parsed by
+	// the CLI. It is only set for PreRun hooks.
+	Args []string `json:",omitempty"`
+	// Context is the name of the Docker context that is used for the
+	// command. It is only set for PreRun hooks.
+	Context string `json:",omitempty"`
 }
 
 // RunCLICommandHooks is the entrypoint into the hooks execution flow after
@@ -72,7 +110,7 @@ func invokeAndCollectHooks(ctx context.Context, dockerCli command.Cli, rootCmd,
 		}
 
 		p, err := GetPlugin(pluginName, dockerCli, rootCmd)
-		if err != nil {
+		if err != nil || p.Err != nil || !p.supportsHook(HookCapabilityNextSteps) {
 			continue
 		}
 
@@ -135,8 +173,14 @@ func appendNextSteps(nextSteps []string, processed []string) ([]string, bool) {
 // and, if the configuration includes a hook for the invoked command, returns
 // the configured hook string.
 func pluginMatch(pluginCfg map[string]string, subCmd string) (string, bool) {
-	configuredPluginHooks, ok := pluginCfg["hooks"]
-	if !ok || configuredPluginHooks == "" {
+	return configuredHooksMatch(pluginCfg["hooks"], subCmd)
+}
+
+// configuredHooksMatch takes a comma-separated list of commands for which
+// a hook is configured and, if it includes the invoked command, returns the
+// matching entry.
+func configuredHooksMatch(configuredPluginHooks string, subCmd string) (string, bool) {
+	if configuredPluginHooks == "" {
 		return "", false
 	}
 
@@ -197,3 +241,204 @@ func getNaiveFlags(args []string) map[string]string {
 	}
 	return flags
 }
+
+// RunCLICommandPreRunHooks is the entrypoint into the pre-run hooks execution
+// flow before a main CLI command is executed. It calls the hook subcommand of
+// all plugins that are configured with a pre-run hook for the command, and
+// returns an error if any of them denies the command. Flags that are
+// requested by the hooks are set before returning. Environment variables that
+// are requested by the hooks are ignored, as the CLI is already initialized
+// when the hooks are invoked.
+func RunCLICommandPreRunHooks(ctx context.Context, dockerCli command.Cli, rootCmd, subCommand *cobra.Command, args []string) error {
+	commandName := strings.TrimPrefix(subCommand.CommandPath(), rootCmd.Name()+" ")
+	subCmdStrs := []string{commandName}
+	if canonical := canonicalCommandName(rootCmd, subCommand); canonical != commandName {
+		subCmdStrs = append(subCmdStrs, canonical)
+	}
+	responses, err := invokePreRunHooks(ctx, dockerCli, rootCmd, subCmdStrs, HookPluginData{
+		Type:    hooks.PreRun,
+		Flags:   getCommandFlags(subCommand),
+		Command: commandName,
+		Args:    args,
+		Context: dockerCli.CurrentContext(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range responses {
+		names := make([]string, 0, len(r.Flags))
+		for name := range r.Flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := subCommand.Flags().Set(name, r.Flags[name]); err != nil {
+				return errors.Wrapf(err, "pre-run hook of plugin %s set an invalid flag %q", r.pluginName, name)
+			}
+		}
+		if len(r.Env) > 0 {
+			logrus.Warnf("Ignoring environment variables set by the pre-run hook of plugin %s: environment variables can only be set for plugin commands", r.pluginName)
+		}
+	}
+	return nil
+}
+
+// canonicalCommandName returns the name of the command that cmd is a shortcut
+// for, such as `container run` for `docker run`, as listed first in its
+// "aliases" annotation. It returns the name of cmd itself if it has no such
+// annotation.
+func canonicalCommandName(rootCmd, cmd *cobra.Command) string {
+	if aliases := cmd.Annotations["aliases"]; aliases != "" {
+		canonical, _, _ := strings.Cut(aliases, ",")
+		return strings.TrimPrefix(strings.TrimSpace(canonical), rootCmd.Name()+" ")
+	}
+	return strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+}
+
+// RunPluginPreRunHooks is the entrypoint for the pre-run hooks execution flow
+// before a plugin command is executed by the CLI. It returns an error if any
+// of the hooks denies the command, and otherwise the environment variables
+// that are requested by the hooks, to be set for the plugin command. Flags
+// that are requested by the hooks are ignored, as the CLI cannot set them on
+// commands that are provided by plugins.
+func RunPluginPreRunHooks(ctx context.Context, dockerCli command.Cli, rootCmd *cobra.Command, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	responses, err := invokePreRunHooks(ctx, dockerCli, rootCmd, []string{strings.Join(args, " ")}, HookPluginData{
+		Type:    hooks.PreRun,
+		Flags:   getNaiveFlags(args),
+		Command: args[0],
+		Args:    args[1:],
+		Context: dockerCli.CurrentContext(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var envs []string
+	for _, r := range responses {
+		if len(r.Flags) > 0 {
+			logrus.Warnf("Ignoring flags set by the pre-run hook of plugin %s: flags cannot be set on plugin commands", r.pluginName)
+		}
+		envs = append(envs, r.Env...)
+	}
+	return envs, nil
+}
+
+// preRunHookResponse is the response of a plugin's pre-run hook.
+type preRunHookResponse struct {
+	pluginName string
+	hooks.HookMessage
+}
+
+// invokePreRunHooks calls the pre-run hook of the plugins that are configured
+// for any of the given names of the command, in order of the plugin name, and
+// collects their responses.
+// Unlike hooks that run after a command, pre-run hooks are used to enforce
+// policy, so a plugin that cannot be found, fails, or does not respond within
+// its timeout prevents the command from being executed.
+func invokePreRunHooks(ctx context.Context, dockerCli command.Cli, rootCmd *cobra.Command, subCmdStrs []string, hookData HookPluginData) ([]preRunHookResponse, error) {
+	pluginsCfg := dockerCli.ConfigFile().Plugins
+	pluginNames := make([]string, 0, len(pluginsCfg))
+	for pluginName := range pluginsCfg {
+		pluginNames = append(pluginNames, pluginName)
+	}
+	sort.Strings(pluginNames)
+
+	var responses []preRunHookResponse
+	for _, pluginName := range pluginNames {
+		cfg := pluginsCfg[pluginName]
+		var match string
+		var ok bool
+		for _, subCmdStr := range subCmdStrs {
+			if match, ok = configuredHooksMatch(cfg[preRunHooksKey], subCmdStr); ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		timeout, err := preRunHooksTimeout(cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pre-run hook configuration for plugin %s", pluginName)
+		}
+
+		p, err := GetPlugin(pluginName, dockerCli, rootCmd)
+		if err == nil {
+			err = p.Err
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to run pre-run hook of plugin %s", pluginName)
+		}
+		if !p.supportsHook(HookCapabilityPreRun) {
+			return nil, errors.Errorf("failed to run pre-run hook of plugin %s: plugin does not support pre-run hooks", pluginName)
+		}
+
+		hookData.RootCmd = match
+		hookMessage, err := runPreRunHook(ctx, p, hookData, timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to run pre-run hook of plugin %s", pluginName)
+		}
+		if hookMessage.Deny {
+			if hookMessage.Message == "" {
+				return nil, errors.Errorf("command denied by plugin %s", pluginName)
+			}
+			return nil, errors.Errorf("command denied by plugin %s: %s", pluginName, hookMessage.Message)
+		}
+		if hookMessage.Message != "" {
+			_, _ = fmt.Fprintln(dockerCli.Err(), hookMessage.Message)
+		}
+		responses = append(responses, preRunHookResponse{pluginName: pluginName, HookMessage: hookMessage})
+	}
+	return responses, nil
+}
+
+// runPreRunHook invokes the hook subcommand of the plugin, and parses its
+// response. The plugin is terminated if it does not respond within timeout.
+func runPreRunHook(ctx context.Context, p *Plugin, hookData HookPluginData, timeout time.Duration) (hooks.HookMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	hookReturn, err := p.RunHook(ctx, hookData)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return hooks.HookMessage{}, errors.Errorf("no response within %s", timeout)
+		}
+		return hooks.HookMessage{}, err
+	}
+	return parsePreRunHookMessage(hookReturn)
+}
+
+// parsePreRunHookMessage parses the response of a pre-run hook.
+func parsePreRunHookMessage(hookReturn []byte) (hooks.HookMessage, error) {
+	var hookMessage hooks.HookMessage
+	if err := json.Unmarshal(hookReturn, &hookMessage); err != nil {
+		return hooks.HookMessage{}, errors.Wrap(err, "invalid response")
+	}
+	if hookMessage.Type != hooks.PreRun {
+		return hooks.HookMessage{}, errors.Errorf("unexpected hook type in response: %d", hookMessage.Type)
+	}
+	for _, env := range hookMessage.Env {
+		if k, _, ok := strings.Cut(env, "="); !ok || k == "" {
+			return hooks.HookMessage{}, errors.Errorf("invalid environment variable in response: %q", env)
+		}
+	}
+	return hookMessage, nil
+}
+
+// preRunHooksTimeout returns how long the pre-run hook of the plugin with the
+// given configuration may take.
+func preRunHooksTimeout(pluginCfg map[string]string) (time.Duration, error) {
+	v, ok := pluginCfg[preRunHooksTimeoutKey]
+	if !ok || v == "" {
+		return defaultPreRunHooksTimeout, nil
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.Wrap(err, preRunHooksTimeoutKey)
+	}
+	if timeout <= 0 {
+		return 0, errors.Errorf("%s: must be a positive duration", preRunHooksTimeoutKey)
+	}
+	return timeout, nil
+}
@@ -1,10 +1,19 @@
 package manager
 
 import (
+	"context"
+	"os"
+	"runtime"
 	"testing"
+	"time"
 
+	"github.com/docker/cli/cli-plugins/hooks"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/test"
+	"github.com/spf13/cobra"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
 )
 
 func TestGetNaiveFlags(t *testing.T) {
@@ -141,3 +150,258 @@ func TestAppendNextSteps(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePreRunHookMessage(t *testing.T) {
+	testCases := []struct {
+		doc         string
+		hookReturn  string
+		expected    hooks.HookMessage
+		expectedErr string
+	}{
+		{
+			doc:        "allow",
+			hookReturn: `{"Type":1}`,
+			expected:   hooks.HookMessage{Type: hooks.PreRun},
+		},
+		{
+			doc:        "deny",
+			hookReturn: `{"Type":1,"Deny":true,"Message":"not allowed"}`,
+			expected:   hooks.HookMessage{Type: hooks.PreRun, Deny: true, Message: "not allowed"},
+		},
+		{
+			doc:        "amend",
+			hookReturn: `{"Type":1,"Flags":{"label":"a=b"},"Env":["FOO=bar"]}`,
+			expected:   hooks.HookMessage{Type: hooks.PreRun, Flags: map[string]string{"label": "a=b"}, Env: []string{"FOO=bar"}},
+		},
+		{
+			doc:         "next steps",
+			hookReturn:  `{"Type":0,"Template":"hello"}`,
+			expectedErr: "unexpected hook type in response: 0",
+		},
+		{
+			doc:         "invalid env",
+			hookReturn:  `{"Type":1,"Env":["FOO"]}`,
+			expectedErr: `invalid environment variable in response: "FOO"`,
+		},
+		{
+			doc:         "invalid json",
+			hookReturn:  `not json`,
+			expectedErr: "invalid response",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			msg, err := parsePreRunHookMessage([]byte(tc.hookReturn))
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(msg, tc.expected))
+		})
+	}
+}
+
+func TestPreRunHooksTimeout(t *testing.T) {
+	timeout, err := preRunHooksTimeout(map[string]string{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(timeout, defaultPreRunHooksTimeout))
+
+	timeout, err = preRunHooksTimeout(map[string]string{"pre-run-hooks-timeout": "2s"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(timeout, 2*time.Second))
+
+	_, err = preRunHooksTimeout(map[string]string{"pre-run-hooks-timeout": "forever"})
+	assert.Check(t, is.ErrorContains(err, "pre-run-hooks-timeout"))
+
+	_, err = preRunHooksTimeout(map[string]string{"pre-run-hooks-timeout": "0s"})
+	assert.Check(t, is.ErrorContains(err, "must be a positive duration"))
+}
+
+func TestRunCLICommandPreRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-policy", `#!/bin/sh
+if [ "$1" = "docker-cli-plugin-metadata" ]; then
+	echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing"}'
+	exit 0
+fi
+case "$3" in
+*'do-not-send'*)
+	echo '{"Type":1,"Deny":true,"Message":"flag value was sent"}' ;;
+*'"privileged":"true"'*)
+	echo '{"Type":1,"Deny":true,"Message":"--privileged is not allowed"}' ;;
+*)
+	echo '{"Type":1,"Message":"checked by policy","Flags":{"label":"policy=checked"},"Env":["POLICY_CHECKED=1"]}' ;;
+esac
+`, fs.WithMode(0o777)),
+		fs.WithFile("docker-slow", `#!/bin/sh
+if [ "$1" = "docker-cli-plugin-metadata" ]; then
+	echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing"}'
+	exit 0
+fi
+sleep 5
+`, fs.WithMode(0o777)),
+	)
+	defer dir.Remove()
+
+	newCommand := func() (*cobra.Command, *cobra.Command) {
+		rootCmd := &cobra.Command{Use: "docker"}
+		runCmd := &cobra.Command{Use: "run"}
+		runCmd.Flags().Bool("privileged", false, "")
+		runCmd.Flags().String("label", "", "")
+		rootCmd.AddCommand(runCmd)
+		return rootCmd, runCmd
+	}
+
+	t.Run("allow and amend", func(t *testing.T) {
+		t.Setenv("POLICY_CHECKED", "")
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"policy": {"pre-run-hooks": "run"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.NilError(t, err)
+		label, err := runCmd.Flags().GetString("label")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(label, "policy=checked"))
+		// environment variables are only set for plugin commands
+		assert.Check(t, is.Equal(os.Getenv("POLICY_CHECKED"), ""))
+		assert.Check(t, is.Equal(cli.ErrBuffer().String(), "checked by policy\n"))
+	})
+
+	t.Run("only bool flag values are sent", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"policy": {"pre-run-hooks": "run"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{"--label", "do-not-send"}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.NilError(t, err)
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"policy": {"pre-run-hooks": "run"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{"--privileged"}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.Check(t, is.Error(err, "command denied by plugin policy: --privileged is not allowed"))
+	})
+
+	t.Run("canonical command", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"policy": {"pre-run-hooks": "container run"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		runCmd.Annotations = map[string]string{"aliases": "docker container run, docker run"}
+		assert.NilError(t, runCmd.ParseFlags([]string{"--privileged"}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.Check(t, is.Error(err, "command denied by plugin policy: --privileged is not allowed"))
+	})
+
+	t.Run("not configured for command", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"policy": {"pre-run-hooks": "build"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{"--privileged"}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.NilError(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"slow": {"pre-run-hooks": "run", "pre-run-hooks-timeout": "100ms"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.Check(t, is.Error(err, "failed to run pre-run hook of plugin slow: no response within 100ms"))
+	})
+
+	t.Run("plugin not found", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			CLIPluginsExtraDirs: []string{dir.Path()},
+			Plugins: map[string]map[string]string{
+				"missing": {"pre-run-hooks": "run"},
+			},
+		})
+		rootCmd, runCmd := newCommand()
+		assert.NilError(t, runCmd.ParseFlags([]string{}))
+
+		err := RunCLICommandPreRunHooks(context.Background(), cli, rootCmd, runCmd, []string{"alpine"})
+		assert.Check(t, is.ErrorContains(err, "failed to run pre-run hook of plugin missing"))
+	})
+}
+
+func TestInvokeAndCollectHooksSkipsInvalidPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-hints", `#!/bin/sh
+if [ "$1" = "docker-cli-plugin-metadata" ]; then
+	echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing"}'
+	exit 0
+fi
+echo '{"Template":"a hint"}'
+`, fs.WithMode(0o777)),
+	)
+	defer dir.Remove()
+
+	cli := test.NewFakeCli(nil)
+	cli.SetConfigFile(&configfile.ConfigFile{
+		CLIPluginsExtraDirs: []string{dir.Path()},
+		Plugins: map[string]map[string]string{
+			"hints": {"hooks": "image"},
+		},
+	})
+	rootCmd := &cobra.Command{Use: "docker"}
+	imageCmd := &cobra.Command{Use: "image"}
+	rootCmd.AddCommand(imageCmd)
+
+	nextSteps := invokeAndCollectHooks(context.Background(), cli, rootCmd, imageCmd, "image", map[string]string{}, "")
+	assert.Check(t, is.DeepEqual(nextSteps, []string{"a hint"}))
+
+	// plugins that are not allowed to run are skipped
+	cli.ConfigFile().Features = map[string]string{"plugin-allowlist": "true"}
+	nextSteps = invokeAndCollectHooks(context.Background(), cli, rootCmd, imageCmd, "image", map[string]string{}, "")
+	assert.Check(t, is.Len(nextSteps, 0))
+}
@@ -13,6 +13,7 @@
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/fvbommel/sortorder"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 )
@@ -49,6 +50,34 @@ func IsNotFound(err error) bool {
 	return ok
 }
 
+// IsValidName returns whether name is valid as the name of a CLI plugin.
+func IsValidName(name string) bool {
+	return pluginNameRe.MatchString(name)
+}
+
+// UserPluginPath returns the path of the executable of the named plugin in
+// the "cli-plugins" directory inside the CLIs [config.Path], which is where
+// plugins are installed for the current user.
+func UserPluginPath(name string) (string, error) {
+	return config.Path("cli-plugins", addExeSuffix(NamePrefix+name))
+}
+
+// ValidatePlugin runs the metadata subcommand of the plugin executable at
+// path, and validates the plugin as is done for the plugins that are found in
+// the plugin directories. The name of the plugin is derived from the basename
+// of path, and must not conflict with a command of rootcmd. It returns the
+// plugin, or an error if the plugin is not valid.
+func ValidatePlugin(path string, rootcmd *cobra.Command) (*Plugin, error) {
+	p, err := newPlugin(&candidate{path}, rootcmd.Commands())
+	if err != nil {
+		return nil, err
+	}
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return &p, nil
+}
+
 // getPluginDirs returns the platform-specific locations to search for plugins
 // in order of preference.
 //
@@ -129,6 +158,7 @@ func GetPlugin(name string, dockerCli command.Cli, rootcmd *cobra.Command) (*Plu
 		if err != nil {
 			return nil, err
 		}
+		applyAllowlist(dockerCli.ConfigFile(), &p)
 		if !IsNotFound(p.Err) {
 			p.ShadowedPaths = paths[1:]
 		}
@@ -151,6 +181,7 @@ func ListPlugins(dockerCli command.Cli, rootcmd *cobra.Command) ([]Plugin, error
 	var mu sync.Mutex
 	eg, _ := errgroup.WithContext(context.TODO())
 	cmds := rootcmd.Commands()
+	cfg := dockerCli.ConfigFile()
 	for _, paths := range candidates {
 		func(paths []string) {
 			eg.Go(func() error {
@@ -162,6 +193,7 @@ func(paths []string) {
 				if err != nil {
 					return err
 				}
+				applyAllowlist(cfg, &p)
 				if !IsNotFound(p.Err) {
 					p.ShadowedPaths = paths[1:]
 					mu.Lock()
@@ -187,6 +219,14 @@ func(paths []string) {
 // The rootcmd argument is referenced to determine the set of builtin commands in order to detect conficts.
 // The error returned satisfies the IsNotFound() predicate if no plugin was found or if the first candidate plugin was invalid somehow.
 func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command) (*exec.Cmd, error) {
+	cmd, _, err := PluginRunCommandWithMetadata(dockerCli, name, rootcmd)
+	return cmd, err
+}
+
+// PluginRunCommandWithMetadata is like PluginRunCommand, but also returns the
+// metadata of the plugin, which holds the permissions that must be enforced
+// while the plugin runs.
+func PluginRunCommandWithMetadata(dockerCli command.Cli, name string, rootcmd *cobra.Command) (*exec.Cmd, Metadata, error) {
 	// This uses the full original args, not the args which may
 	// have been provided by cobra to our caller. This is because
 	// they lack e.g. global options which we must propagate here.
@@ -194,12 +234,12 @@ func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command
 	if !pluginNameRe.MatchString(name) {
 		// We treat this as "not found" so that callers will
 		// fallback to their "invalid" command path.
-		return nil, errPluginNotFound(name)
+		return nil, Metadata{}, errPluginNotFound(name)
 	}
 	exename := addExeSuffix(NamePrefix + name)
 	pluginDirs, err := getPluginDirs(dockerCli.ConfigFile())
 	if err != nil {
-		return nil, err
+		return nil, Metadata{}, err
 	}
 
 	for _, d := range pluginDirs {
@@ -216,11 +256,18 @@ func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command
 		c := &candidate{path: path}
 		plugin, err := newPlugin(c, rootcmd.Commands())
 		if err != nil {
-			return nil, err
+			return nil, Metadata{}, err
 		}
+		applyAllowlist(dockerCli.ConfigFile(), &plugin)
 		if plugin.Err != nil {
+			if IsIncompatible(plugin.Err) || IsNotAllowed(plugin.Err) {
+				return nil, Metadata{}, errors.Wrapf(plugin.Err, "cannot run plugin %s", name)
+			}
 			// TODO: why are we not returning plugin.Err?
-			return nil, errPluginNotFound(name)
+			return nil, Metadata{}, errPluginNotFound(name)
+		}
+		if err := checkDaemonRequirements(dockerCli, plugin.Metadata); err != nil {
+			return nil, Metadata{}, errors.Wrapf(err, "cannot run plugin %s", name)
 		}
 		cmd := exec.Command(plugin.Path, args...) // #nosec G204 -- ignore "Subprocess launched with a potential tainted input or cmd arguments"
 
@@ -233,12 +280,15 @@ func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 
-		cmd.Env = append(cmd.Environ(), ReexecEnvvar+"="+os.Args[0])
+		// Plugins that declare their permissions only get the environment
+		// variables they need.
+		cmd.Env = plugin.Metadata.Permissions.filterEnv(cmd.Environ())
+		cmd.Env = append(cmd.Env, ReexecEnvvar+"="+os.Args[0])
 		cmd.Env = appendPluginResourceAttributesEnvvar(cmd.Env, rootcmd, plugin)
 
-		return cmd, nil
+		return cmd, plugin.Metadata, nil
 	}
-	return nil, errPluginNotFound(name)
+	return nil, Metadata{}, errPluginNotFound(name)
 }
 
 // IsPluginCommand checks if the given cmd is a plugin-stub.
@@ -9,6 +9,7 @@
 	"github.com/docker/cli/internal/test"
 	"github.com/spf13/cobra"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/fs"
 )
 
@@ -155,6 +156,84 @@ func TestListPluginsIsSorted(t *testing.T) {
 	assert.DeepEqual(t, names, []string{"aaa", "bbb"})
 }
 
+func TestPluginRunCommandIncompatible(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-future", `#!/bin/sh
+echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing","MinimumCLIVersion":"999.0.0"}'`, fs.WithMode(0o777)),
+	)
+	defer dir.Remove()
+
+	defer func(v string) { cliVersion = v }(cliVersion)
+	cliVersion = "27.1.0"
+
+	cli := test.NewFakeCli(nil)
+	cli.SetConfigFile(&configfile.ConfigFile{CLIPluginsExtraDirs: []string{dir.Path()}})
+
+	_, err := PluginRunCommand(cli, "future", &cobra.Command{})
+	assert.Error(t, err, "cannot run plugin future: plugin requires Docker CLI version 999.0.0 or newer, but the Docker CLI version is 27.1.0")
+	assert.Assert(t, IsIncompatible(err))
+	assert.Assert(t, !IsNotFound(err))
+}
+
+func TestPluginRunCommandPermissions(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-legacy", `#!/bin/sh
+echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing"}'`, fs.WithMode(0o777)),
+		// The metadata subcommand is run without the environment variables
+		// that are not passed to plugins that declare no permissions.
+		fs.WithFile("docker-restricted", `#!/bin/sh
+[ -z "$MY_TOKEN" ] || exit 1
+echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing","Permissions":{"Network":true,"DockerAPI":true,"Env":["MY_SETTING"]}}'`, fs.WithMode(0o777)),
+	)
+	defer dir.Remove()
+
+	t.Setenv("DOCKER_HOST", "tcp://remote.example.com:2376")
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("MY_SETTING", "value")
+	t.Setenv("MY_TOKEN", "secret")
+
+	cli := test.NewFakeCli(nil)
+	cli.SetConfigFile(&configfile.ConfigFile{
+		CLIPluginsExtraDirs: []string{dir.Path()},
+	})
+
+	cmd, meta, err := PluginRunCommandWithMetadata(cli, "restricted", &cobra.Command{})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(meta.Permissions, &Permissions{Network: true, DockerAPI: true, Env: []string{"MY_SETTING"}}))
+	assert.Check(t, is.Contains(cmd.Env, "DOCKER_HOST=tcp://remote.example.com:2376"))
+	for _, e := range cmd.Env {
+		assert.Check(t, !strings.HasPrefix(e, "MY_TOKEN="), e)
+	}
+
+	cli.ConfigFile().Features = map[string]string{"plugin-allowlist": "true"}
+
+	_, err = PluginRunCommand(cli, "legacy", &cobra.Command{})
+	assert.Error(t, err, "cannot run plugin legacy: plugin is not allowed in the CLI configuration")
+	assert.Assert(t, IsNotAllowed(err))
+	assert.Assert(t, !IsNotFound(err))
+
+	_, err = PluginRunCommand(cli, "restricted", &cobra.Command{})
+	assert.Check(t, IsNotAllowed(err), "declaring permissions doesn't bypass the allowlist")
+
+	cli.ConfigFile().Plugins = map[string]map[string]string{
+		"legacy":     {"allowed": "true"},
+		"restricted": {"allowed": "true", "allow-network": "true", "allow-env": "MY_SETTING,OTHER_SETTING"},
+	}
+	cmd, meta, err = PluginRunCommandWithMetadata(cli, "restricted", &cobra.Command{})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(meta.Permissions, &Permissions{Network: true, Env: []string{"MY_SETTING"}}))
+	assert.Check(t, is.Contains(cmd.Env, "HTTPS_PROXY=http://proxy.example.com:3128"))
+	assert.Check(t, is.Contains(cmd.Env, "MY_SETTING=value"))
+	for _, e := range cmd.Env {
+		assert.Check(t, !strings.HasPrefix(e, "DOCKER_HOST="), e)
+		assert.Check(t, !strings.HasPrefix(e, "MY_TOKEN="), e)
+	}
+
+	cmd, err = PluginRunCommand(cli, "legacy", &cobra.Command{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Contains(cmd.Env, "MY_TOKEN=secret"))
+}
+
 func TestErrPluginNotFound(t *testing.T) {
 	var err error = errPluginNotFound("test")
 	err.(errPluginNotFound).NotFound()
@@ -15,6 +15,17 @@
 	HookSubcommandName = "docker-cli-plugin-hooks"
 )
 
+// Hook capabilities that plugins can declare in [Metadata.Hooks].
+const (
+	// HookCapabilityNextSteps declares that the plugin supports hooks
+	// that are invoked after a command was executed.
+	HookCapabilityNextSteps = "next-steps"
+
+	// HookCapabilityPreRun declares that the plugin supports hooks that
+	// are invoked before a command is executed.
+	HookCapabilityPreRun = "pre-run"
+)
+
 // Metadata provided by the plugin.
 type Metadata struct {
 	// SchemaVersion describes the version of this struct. Mandatory, must be "0.1.0"
@@ -27,4 +38,55 @@ type Metadata struct {
 	ShortDescription string `json:",omitempty"`
 	// URL is a pointer to the plugin's homepage.
 	URL string `json:",omitempty"`
+
+	// MinimumCLIVersion is the optional oldest version of the Docker CLI
+	// that the plugin supports, such as "27.0.0".
+	MinimumCLIVersion string `json:",omitempty"`
+	// MaximumCLIVersion is the optional newest version of the Docker CLI
+	// that the plugin supports, such as "28.5.0".
+	MaximumCLIVersion string `json:",omitempty"`
+	// MinimumAPIVersion is the optional oldest Docker Engine API version
+	// that the plugin requires, such as "1.45".
+	MinimumAPIVersion string `json:",omitempty"`
+	// OperatingSystems optionally lists the operating systems that the
+	// plugin supports, as GOOS values such as "linux". The plugin supports
+	// all operating systems if empty.
+	OperatingSystems []string `json:",omitempty"`
+	// Hooks optionally lists the hooks that the plugin supports, such as
+	// HookCapabilityNextSteps and HookCapabilityPreRun. If empty, the
+	// plugin is assumed to support the hooks it is configured for.
+	Hooks []string `json:",omitempty"`
+	// Experimental indicates that the plugin requires a Docker daemon with
+	// experimental features enabled.
+	Experimental bool `json:",omitempty"`
+	// Permissions optionally declares the capabilities that the plugin
+	// needs. If set, the plugin is run with only those capabilities, or
+	// with those that are also granted in the CLI configuration if the
+	// plugin allowlist is enabled, see Permissions for details.
+	Permissions *Permissions `json:",omitempty"`
+}
+
+// Permissions declares the capabilities that a plugin needs. The CLI runs
+// plugins that declare their permissions with an environment that only
+// contains the variables needed for those capabilities, and only provides
+// the services over the plugin socket that the plugin has permission for.
+//
+// Permissions are not a sandbox: the plugin runs as the user running the
+// CLI, and has access to the same files.
+type Permissions struct {
+	// Credentials lists the registries, optionally including a repository
+	// path, such as "registry.example.com/team-a", for which the plugin may
+	// request credentials from the CLI. Use "docker.io" for Docker Hub, or
+	// "*" for any registry. The user is still asked to consent.
+	Credentials []string `json:",omitempty"`
+	// DockerAPI indicates that the plugin connects to the Docker daemon.
+	// Without it, the configuration of the Docker endpoint is withheld
+	// from the plugin.
+	DockerAPI bool `json:",omitempty"`
+	// Network indicates that the plugin makes network connections other
+	// than to the Docker daemon. Without it, the proxy configuration is
+	// withheld from the plugin.
+	Network bool `json:",omitempty"`
+	// Env lists additional environment variables to pass to the plugin.
+	Env []string `json:",omitempty"`
 }
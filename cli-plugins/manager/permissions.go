@@ -0,0 +1,255 @@
+package manager
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/docker/registry"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pluginAllowlistFeature is the feature in the configuration that, if
+	// enabled, refuses to run plugins that are not allow-listed, and only
+	// gives plugins the permissions that are granted in the configuration.
+	pluginAllowlistFeature = "plugin-allowlist"
+
+	// allowedKey is the key in the configuration of a plugin that holds
+	// whether the plugin is allow-listed.
+	allowedKey = "allowed"
+
+	// Keys in the configuration of a plugin that hold the permissions
+	// granted to the plugin if the plugin allowlist is enabled. The
+	// credentials and env keys hold a comma-separated list.
+	allowCredentialsKey = "allow-credentials"
+	allowDockerAPIKey   = "allow-docker-api"
+	allowNetworkKey     = "allow-network"
+	allowEnvKey         = "allow-env"
+)
+
+var (
+	// baseEnv are the environment variables that are passed to all plugins
+	// that declare their permissions.
+	baseEnv = []string{
+		"PATH", "PWD", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "COLORTERM", "NO_COLOR",
+		"LANG", "LANGUAGE", "TZ", "TMPDIR", "DOCKER_CONFIG",
+
+		// Variables that are needed on Windows.
+		"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP",
+		"USERNAME", "USERPROFILE", "HOMEDRIVE", "HOMEPATH", "APPDATA", "LOCALAPPDATA",
+		"PROGRAMDATA", "PROGRAMFILES",
+	}
+
+	// baseEnvPrefixes are the prefixes of the environment variables that are
+	// passed to all plugins that declare their permissions.
+	baseEnvPrefixes = []string{"LC_", "XDG_", "DOCKER_CLI_", "OTEL_"}
+
+	// dockerAPIEnv are the environment variables that are passed to plugins
+	// with the DockerAPI permission.
+	dockerAPIEnv = []string{
+		"DOCKER_HOST", "DOCKER_CONTEXT", "DOCKER_TLS", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH",
+		"DOCKER_API_VERSION", "DOCKER_DEFAULT_PLATFORM", "SSH_AUTH_SOCK",
+	}
+
+	// networkEnv are the environment variables that are passed to plugins
+	// with the Network permission.
+	networkEnv = []string{
+		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+		"http_proxy", "https_proxy", "no_proxy", "all_proxy",
+		"SSL_CERT_FILE", "SSL_CERT_DIR",
+	}
+)
+
+// pluginNotAllowedError is the cause of the error that is set as Plugin.Err
+// if the plugin is refused by the plugin allowlist.
+type pluginNotAllowedError string
+
+func (e pluginNotAllowedError) Error() string {
+	return string(e)
+}
+
+// IsNotAllowed is true if the given error is due to the plugin not being
+// allowed to run by the CLI configuration.
+func IsNotAllowed(err error) bool {
+	var e pluginNotAllowedError
+	return errors.As(err, &e)
+}
+
+// checkAllowed returns an error for which IsNotAllowed returns true if the
+// plugin allowlist is enabled in the configuration, and the plugin is not
+// allow-listed. Declaring permissions doesn't exempt a plugin from the
+// allowlist.
+func checkAllowed(cfg *configfile.ConfigFile, p Plugin) error {
+	if !allowlistEnabled(cfg) {
+		return nil
+	}
+	if allowed, _ := strconv.ParseBool(cfg.Plugins[p.Name][allowedKey]); allowed {
+		return nil
+	}
+	return &pluginError{cause: pluginNotAllowedError("plugin is not allowed in the CLI configuration")}
+}
+
+// applyAllowlist sets p.Err if the plugin is refused by the plugin
+// allowlist. Otherwise, if the allowlist is enabled, the permissions that
+// the plugin declares are restricted to those granted in the configuration.
+// Plugins that don't declare their permissions are run unrestricted once
+// they are allow-listed.
+func applyAllowlist(cfg *configfile.ConfigFile, p *Plugin) {
+	if p.Err != nil {
+		return
+	}
+	if err := checkAllowed(cfg, *p); err != nil {
+		p.Err = err
+		return
+	}
+	if allowlistEnabled(cfg) {
+		p.Metadata.Permissions = p.Metadata.Permissions.intersect(grantedPermissions(cfg.Plugins[p.Name]))
+	}
+}
+
+func allowlistEnabled(cfg *configfile.ConfigFile) bool {
+	enabled, _ := strconv.ParseBool(cfg.Features[pluginAllowlistFeature])
+	return enabled
+}
+
+// grantedPermissions returns the permissions granted to a plugin in its
+// configuration.
+func grantedPermissions(pluginCfg map[string]string) *Permissions {
+	granted := &Permissions{
+		Credentials: splitList(pluginCfg[allowCredentialsKey]),
+		Env:         splitList(pluginCfg[allowEnvKey]),
+	}
+	granted.DockerAPI, _ = strconv.ParseBool(pluginCfg[allowDockerAPIKey])
+	granted.Network, _ = strconv.ParseBool(pluginCfg[allowNetworkKey])
+	return granted
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// intersect returns the permissions that are both in perms and granted. If
+// the plugin doesn't declare its permissions, nil is returned.
+func (perms *Permissions) intersect(granted *Permissions) *Permissions {
+	if perms == nil {
+		return nil
+	}
+	result := &Permissions{
+		DockerAPI: perms.DockerAPI && granted.DockerAPI,
+		Network:   perms.Network && granted.Network,
+	}
+	for _, c := range perms.Credentials {
+		declared := &Permissions{Credentials: []string{c}}
+		for _, g := range granted.Credentials {
+			// Use the narrowest of both, so that a plugin that declares
+			// "*" only gets the registries that are granted.
+			if declared.allowsCredentials(g) {
+				result.Credentials = append(result.Credentials, g)
+			} else if (&Permissions{Credentials: []string{g}}).allowsCredentials(c) {
+				result.Credentials = append(result.Credentials, c)
+			}
+		}
+	}
+	for _, e := range perms.Env {
+		for _, g := range granted.Env {
+			if e == g {
+				result.Env = append(result.Env, e)
+			}
+		}
+	}
+	return result
+}
+
+// filterEnv returns the variables in env that are passed to a plugin with
+// the given permissions. If the plugin doesn't declare its permissions, env
+// is returned as-is.
+func (perms *Permissions) filterEnv(env []string) []string {
+	if perms == nil {
+		return env
+	}
+
+	allowed := make([]string, 0, len(baseEnv)+len(dockerAPIEnv)+len(networkEnv)+len(perms.Env))
+	allowed = append(allowed, baseEnv...)
+	allowed = append(allowed, perms.Env...)
+	if perms.DockerAPI {
+		allowed = append(allowed, dockerAPIEnv...)
+	}
+	if perms.Network {
+		allowed = append(allowed, networkEnv...)
+	}
+
+	filtered := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if envAllowed(name, allowed) {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
+
+func envAllowed(name string, allowed []string) bool {
+	if name == "" {
+		return false
+	}
+	// Environment variables are case-insensitive on Windows.
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, prefix := range baseEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	for _, a := range allowed {
+		if name == a || (runtime.GOOS == "windows" && name == strings.ToUpper(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsCredentials returns whether a plugin with the given permissions may
+// request the credentials stored under key. A plugin that is allowed to
+// request credentials for a repository path, such as "registry.example.com/team-a",
+// is not given the credentials for the whole registry. Plugins that don't
+// declare their permissions may request any credentials.
+func (perms *Permissions) allowsCredentials(key string) bool {
+	if perms == nil {
+		return true
+	}
+	key = normalizeCredentialsKey(key)
+	for _, c := range perms.Credentials {
+		if c == "*" {
+			return true
+		}
+		c = normalizeCredentialsKey(c)
+		if key == c || strings.HasPrefix(key, c+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCredentialsKey converts a registry address to the form that is
+// used to match permissions, using "docker.io" for Docker Hub.
+func normalizeCredentialsKey(addr string) string {
+	key := credentials.ConvertToCredentialsKey(addr)
+	hostname, repoPath, _ := strings.Cut(key, "/")
+	if hostname == registry.IndexHostname {
+		hostname = registry.DefaultNamespace
+	}
+	if repoPath == "" {
+		return hostname
+	}
+	return hostname + "/" + repoPath
+}
@@ -8,6 +8,7 @@
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -15,6 +16,10 @@
 
 var pluginNameRe = regexp.MustCompile("^[a-z][a-z0-9]*$")
 
+// hookWaitDelay is how long to wait for the output of a plugin's hook
+// subcommand to be closed after the plugin was terminated.
+const hookWaitDelay = 500 * time.Millisecond
+
 // Plugin represents a potential plugin with all it's metadata.
 type Plugin struct {
 	Metadata
@@ -101,6 +106,10 @@ func newPlugin(c Candidate, cmds []*cobra.Command) (Plugin, error) {
 		p.Err = NewPluginError("plugin metadata does not define a vendor")
 		return p, nil
 	}
+	if err := checkCompatibility(p.Metadata); err != nil {
+		p.Err = err
+		return p, nil
+	}
 	return p, nil
 }
 
@@ -113,8 +122,11 @@ func (p *Plugin) RunHook(ctx context.Context, hookData HookPluginData) ([]byte,
 	}
 
 	pCmd := exec.CommandContext(ctx, p.Path, p.Name, HookSubcommandName, string(hDataBytes)) // #nosec G204 -- ignore "Subprocess launched with a potential tainted input or cmd arguments"
-	pCmd.Env = os.Environ()
+	pCmd.Env = p.Metadata.Permissions.filterEnv(os.Environ())
 	pCmd.Env = append(pCmd.Env, ReexecEnvvar+"="+os.Args[0])
+	// Don't wait for subprocesses of the plugin that keep its output open
+	// once the plugin is terminated, for example when a hook times out.
+	pCmd.WaitDelay = hookWaitDelay
 	hookCmdOutput, err := pCmd.Output()
 	if err != nil {
 		return nil, wrapAsPluginError(err, "failed to execute plugin hook subcommand")
@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli-plugins/socket"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/docker/registry"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// credentialsKey is the key in the configuration of a plugin that holds
+// whether the plugin may access registry credentials without asking the
+// user for consent. It's either "allow" or "deny".
+const credentialsKey = "credentials"
+
+// RPCMethods returns the methods the CLI provides to the plugin with the
+// given name over the plugin socket. See [socket.NewRPCHandler]. If the
+// plugin declares its permissions, the methods it has no permission for are
+// withheld.
+func RPCMethods(dockerCli command.Cli, pluginName string, perms *Permissions) map[string]socket.RPCMethod {
+	s := &rpcServices{
+		dockerCli:  dockerCli,
+		pluginName: pluginName,
+		perms:      perms,
+		consent:    make(map[string]bool),
+	}
+	methods := map[string]socket.RPCMethod{
+		socket.MethodPrompt:       s.prompt,
+		socket.MethodTraceContext: s.traceContext,
+	}
+	if perms == nil || perms.DockerAPI {
+		methods[socket.MethodCurrentContext] = s.currentContext
+	}
+	if perms == nil || len(perms.Credentials) > 0 {
+		methods[socket.MethodGetCredentials] = s.getCredentials
+	}
+	return methods
+}
+
+type rpcServices struct {
+	dockerCli  command.Cli
+	pluginName string
+	perms      *Permissions
+
+	// mu serializes the use of the terminal, and protects consent.
+	mu sync.Mutex
+	// consent holds the user's answers to requests for credentials during
+	// this invocation, keyed by credentials key.
+	consent map[string]bool
+}
+
+func (s *rpcServices) currentContext(context.Context, json.RawMessage) (interface{}, error) {
+	ep := s.dockerCli.DockerEndpoint()
+	return socket.CurrentContextResult{
+		Name:          command.EffectiveContext(s.dockerCli),
+		Host:          ep.Host,
+		TLS:           ep.TLSData != nil,
+		SkipTLSVerify: ep.SkipTLSVerify,
+	}, nil
+}
+
+func (s *rpcServices) getCredentials(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p socket.CredentialsParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, socket.NewRPCError(socket.ErrCodeInvalidParams, "invalid parameters: %v", err)
+	}
+
+	key := s.credentialsKey(p.ServerAddress)
+	if !s.perms.allowsCredentials(key) {
+		return nil, socket.NewRPCError(socket.ErrCodeDenied, "plugin does not have permission to access the credentials for %s", key)
+	}
+	ok, err := s.credentialsConsent(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, socket.NewRPCError(socket.ErrCodeDenied, "access to the credentials for %s was denied", key)
+	}
+
+	ac, err := command.GetAuthConfig(ctx, s.dockerCli.ConfigFile(), key)
+	if err != nil {
+		return nil, err
+	}
+	return socket.CredentialsResult{
+		ServerAddress: ac.ServerAddress,
+		Username:      ac.Username,
+		Password:      ac.Password,
+		IdentityToken: ac.IdentityToken,
+		RegistryToken: ac.RegistryToken,
+	}, nil
+}
+
+// credentialsKey returns the key under which the credentials for the given
+// registry address are stored, taking credentials that are scoped to a
+// repository path into account.
+func (s *rpcServices) credentialsKey(serverAddress string) string {
+	addr := credentials.ConvertToCredentialsKey(serverAddress)
+	hostname, _, _ := strings.Cut(addr, "/")
+	isDockerHub := hostname == "" || hostname == registry.DefaultNamespace || hostname == registry.IndexHostname
+	if isDockerHub {
+		addr = registry.DefaultNamespace + strings.TrimPrefix(addr, hostname)
+		hostname = registry.DefaultNamespace
+	}
+	if key := s.dockerCli.ConfigFile().ResolveCredentialsKey(addr); key != hostname {
+		return key
+	}
+	if isDockerHub {
+		return registry.IndexServer
+	}
+	return hostname
+}
+
+// credentialsConsent returns whether the plugin may access the credentials
+// stored under key. Unless the plugin is configured to always allow or deny
+// access, the user is asked on the terminal, once per invocation. Access is
+// denied if there is no terminal.
+func (s *rpcServices) credentialsConsent(ctx context.Context, key string) (bool, error) {
+	if pluginCfg, ok := s.dockerCli.ConfigFile().Plugins[s.pluginName]; ok {
+		switch pluginCfg[credentialsKey] {
+		case "allow":
+			return true, nil
+		case "deny":
+			return false, nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ok, answered := s.consent[key]; answered {
+		return ok, nil
+	}
+	if !s.dockerCli.In().IsTerminal() {
+		return false, nil
+	}
+	msg := fmt.Sprintf("Plugin %q is requesting access to your credentials for %s. Allow?", s.pluginName, key)
+	ok, err := command.PromptForConfirmation(ctx, s.dockerCli.In(), s.dockerCli.Out(), msg)
+	if err != nil {
+		return false, err
+	}
+	s.consent[key] = ok
+	return ok, nil
+}
+
+func (s *rpcServices) prompt(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p socket.PromptParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, socket.NewRPCError(socket.ErrCodeInvalidParams, "invalid parameters: %v", err)
+	}
+	if p.Confirm && p.Secret {
+		return nil, socket.NewRPCError(socket.ErrCodeInvalidParams, "invalid parameters: a confirmation prompt can not be secret")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dockerCli.In().IsTerminal() {
+		return nil, socket.NewRPCError(socket.ErrCodeUnavailable, "cannot prompt: the docker CLI is not attached to a terminal")
+	}
+	if p.Confirm {
+		ok, err := command.PromptForConfirmation(ctx, s.dockerCli.In(), s.dockerCli.Out(), p.Message)
+		if err != nil {
+			return nil, err
+		}
+		return socket.PromptResult{Confirmed: ok}, nil
+	}
+	if p.Secret {
+		restoreInput, err := command.DisableInputEcho(s.dockerCli.In())
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = restoreInput()
+			_, _ = fmt.Fprintln(s.dockerCli.Out())
+		}()
+	}
+	value, err := command.PromptForInput(ctx, s.dockerCli.In(), s.dockerCli.Out(), p.Message)
+	if err != nil {
+		return nil, err
+	}
+	return socket.PromptResult{Value: value}, nil
+}
+
+func (*rpcServices) traceContext(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return socket.TraceContextResult{
+		TraceParent: carrier.Get("traceparent"),
+		TraceState:  carrier.Get("tracestate"),
+	}, nil
+}
@@ -0,0 +1,176 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/cli/cli-plugins/socket"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	dcontext "github.com/docker/cli/cli/context"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestRPCClient(t *testing.T, cli *test.FakeCli, perms *Permissions) *socket.RPCClient {
+	t.Helper()
+	token := socket.NewRPCToken()
+	srv, err := socket.NewPluginServer(socket.NewRPCHandler(context.Background(), token, RPCMethods(cli, "myplugin", perms)))
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	t.Setenv(socket.EnvKey, srv.Addr().String())
+	t.Setenv(socket.TokenEnvKey, token)
+
+	c, err := socket.DialRPC(context.Background())
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestRPCCurrentContext(t *testing.T) {
+	cli := test.NewFakeCli(nil)
+	cli.SetCurrentContext("remote")
+	cli.SetDockerEndpoint(docker.Endpoint{
+		EndpointMeta: docker.EndpointMeta{Host: "tcp://remote.example.com:2376"},
+	})
+	c := newTestRPCClient(t, cli, nil)
+
+	res, err := c.CurrentContext(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(res, socket.CurrentContextResult{
+		Name: "remote",
+		Host: "tcp://remote.example.com:2376",
+	}))
+
+	// the TLS materials of the endpoint are not shared with the plugin
+	cli.SetDockerEndpoint(docker.Endpoint{
+		EndpointMeta: docker.EndpointMeta{Host: "tcp://remote.example.com:2376"},
+		TLSData:      &dcontext.TLSData{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")},
+	})
+	res, err = c.CurrentContext(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(res, socket.CurrentContextResult{
+		Name: "remote",
+		Host: "tcp://remote.example.com:2376",
+		TLS:  true,
+	}))
+}
+
+func TestRPCGetCredentials(t *testing.T) {
+	newCli := func(pluginCfg map[string]string) *test.FakeCli {
+		cli := test.NewFakeCli(nil)
+		cli.SetConfigFile(&configfile.ConfigFile{
+			AuthConfigs: map[string]types.AuthConfig{
+				"https://index.docker.io/v1/":               {Username: "hub-user", Password: "hub-pass"},
+				"registry.example.com":                      {Username: "user", Password: "pass"},
+				"registry.example.com/team-a":               {Username: "team-a-user", Password: "team-a-pass"},
+				"registry.example.com/_oauth/refresh-token": {Username: "user", Password: "refresh-token"},
+			},
+			Plugins: map[string]map[string]string{
+				"myplugin": pluginCfg,
+			},
+		})
+		return cli
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		c := newTestRPCClient(t, newCli(map[string]string{"credentials": "allow"}), nil)
+		ctx := context.Background()
+
+		res, err := c.GetCredentials(ctx, "registry.example.com")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Username, "user"))
+		assert.Check(t, is.Equal(res.Password, "pass"))
+
+		res, err = c.GetCredentials(ctx, "registry.example.com/team-a/app")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Username, "team-a-user"))
+
+		res, err = c.GetCredentials(ctx, "https://registry.example.com/v2/")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Username, "user"))
+
+		for _, addr := range []string{"", "docker.io", "index.docker.io"} {
+			res, err = c.GetCredentials(ctx, addr)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(res.Username, "hub-user"), "address: %q", addr)
+		}
+
+		res, err = c.GetCredentials(ctx, "other.example.com")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Username, ""))
+
+		// OAuth tokens are not credentials scoped to a repository path
+		res, err = c.GetCredentials(ctx, "registry.example.com/_oauth/refresh-token")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Password, "pass"))
+	})
+
+	t.Run("denied in config", func(t *testing.T) {
+		c := newTestRPCClient(t, newCli(map[string]string{"credentials": "deny"}), nil)
+		_, err := c.GetCredentials(context.Background(), "registry.example.com")
+		assert.Check(t, socket.IsRPCError(err, socket.ErrCodeDenied))
+		assert.Check(t, is.Error(err, "access to the credentials for registry.example.com was denied"))
+	})
+
+	t.Run("denied without terminal", func(t *testing.T) {
+		c := newTestRPCClient(t, newCli(nil), nil)
+		_, err := c.GetCredentials(context.Background(), "registry.example.com")
+		assert.Check(t, socket.IsRPCError(err, socket.ErrCodeDenied))
+	})
+}
+
+func TestRPCPermissions(t *testing.T) {
+	cli := test.NewFakeCli(nil)
+	cli.SetConfigFile(&configfile.ConfigFile{
+		AuthConfigs: map[string]types.AuthConfig{
+			"registry.example.com":        {Username: "user", Password: "pass"},
+			"registry.example.com/team-a": {Username: "team-a-user", Password: "team-a-pass"},
+		},
+		Plugins: map[string]map[string]string{
+			"myplugin": {"credentials": "allow"},
+		},
+	})
+
+	t.Run("withheld", func(t *testing.T) {
+		c := newTestRPCClient(t, cli, &Permissions{})
+		assert.Check(t, !c.Supports(socket.MethodGetCredentials))
+		assert.Check(t, !c.Supports(socket.MethodCurrentContext))
+		assert.Check(t, c.Supports(socket.MethodPrompt))
+
+		_, err := c.GetCredentials(context.Background(), "registry.example.com")
+		assert.Check(t, socket.IsRPCError(err, socket.ErrCodeUnknownMethod))
+	})
+
+	t.Run("credentials for a repository path", func(t *testing.T) {
+		c := newTestRPCClient(t, cli, &Permissions{Credentials: []string{"registry.example.com/team-a"}})
+
+		res, err := c.GetCredentials(context.Background(), "registry.example.com/team-a/app")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Username, "team-a-user"))
+
+		_, err = c.GetCredentials(context.Background(), "registry.example.com/team-b/app")
+		assert.Check(t, socket.IsRPCError(err, socket.ErrCodeDenied))
+		assert.Check(t, is.Error(err, "plugin does not have permission to access the credentials for registry.example.com"))
+	})
+}
+
+func TestRPCPromptWithoutTerminal(t *testing.T) {
+	c := newTestRPCClient(t, test.NewFakeCli(nil), nil)
+
+	_, err := c.Prompt(context.Background(), socket.PromptParams{Message: "Name: "})
+	assert.Check(t, socket.IsRPCError(err, socket.ErrCodeUnavailable))
+
+	_, err = c.Prompt(context.Background(), socket.PromptParams{Message: "Continue?", Confirm: true, Secret: true})
+	assert.Check(t, socket.IsRPCError(err, socket.ErrCodeInvalidParams))
+}
+
+func TestRPCTraceContext(t *testing.T) {
+	c := newTestRPCClient(t, test.NewFakeCli(nil), nil)
+
+	res, err := c.TraceContext(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(res, socket.TraceContextResult{}))
+}
@@ -0,0 +1,109 @@
+package socket
+
+import "context"
+
+// Methods provided by the CLI over the plugin socket.
+const (
+	// MethodCurrentContext returns the current context and its Docker
+	// endpoint as a [CurrentContextResult]. It takes no parameters.
+	MethodCurrentContext = "context.current"
+	// MethodGetCredentials returns the credentials for a registry from the
+	// configured credentials store, if the user consents to sharing them with
+	// the plugin. It takes [CredentialsParams], and returns a [CredentialsResult].
+	MethodGetCredentials = "credentials.get"
+	// MethodPrompt prompts the user on the CLI's terminal. It takes
+	// [PromptParams], and returns a [PromptResult].
+	MethodPrompt = "tty.prompt"
+	// MethodTraceContext returns the trace context of the CLI's span for the
+	// plugin invocation as a [TraceContextResult], so that the plugin can use
+	// it as the parent for its own spans. It takes no parameters.
+	MethodTraceContext = "telemetry.trace-context"
+)
+
+// CurrentContextResult is the result of [MethodCurrentContext].
+type CurrentContextResult struct {
+	// Name is the name of the current context.
+	Name string
+	// Host is the address of the Docker endpoint of the context.
+	Host string
+	// TLS is set if the endpoint is configured with TLS materials. The TLS
+	// materials are not shared with plugins.
+	TLS bool `json:",omitempty"`
+	// SkipTLSVerify is set if the TLS certificate of the endpoint should
+	// not be verified.
+	SkipTLSVerify bool `json:",omitempty"`
+}
+
+// CredentialsParams are the parameters of [MethodGetCredentials].
+type CredentialsParams struct {
+	// ServerAddress is the address of the registry, optionally including
+	// a repository path, such as "registry.example.com/team-a". An empty
+	// address is used for Docker Hub.
+	ServerAddress string
+}
+
+// CredentialsResult is the result of [MethodGetCredentials]. All fields are
+// empty if no credentials are stored for the registry.
+type CredentialsResult struct {
+	ServerAddress string `json:",omitempty"`
+	Username      string `json:",omitempty"`
+	Password      string `json:",omitempty"`
+	IdentityToken string `json:",omitempty"`
+	RegistryToken string `json:",omitempty"`
+}
+
+// PromptParams are the parameters of [MethodPrompt].
+type PromptParams struct {
+	// Message is printed before reading the user's input.
+	Message string
+	// Confirm prompts for a yes/no confirmation instead of free-form input,
+	// and sets [PromptResult.Confirmed].
+	Confirm bool `json:",omitempty"`
+	// Secret disables echoing the user's input, for example for passwords.
+	Secret bool `json:",omitempty"`
+}
+
+// PromptResult is the result of [MethodPrompt].
+type PromptResult struct {
+	Value     string `json:",omitempty"`
+	Confirmed bool   `json:",omitempty"`
+}
+
+// TraceContextResult is the result of [MethodTraceContext], containing the
+// W3C trace context headers. Both are empty if the CLI is not tracing.
+type TraceContextResult struct {
+	TraceParent string `json:",omitempty"`
+	TraceState  string `json:",omitempty"`
+}
+
+// CurrentContext returns the current context of the CLI and its Docker endpoint.
+func (c *RPCClient) CurrentContext(ctx context.Context) (CurrentContextResult, error) {
+	var res CurrentContextResult
+	err := c.Call(ctx, MethodCurrentContext, nil, &res)
+	return res, err
+}
+
+// GetCredentials returns the credentials for the given registry. It returns
+// an [RPCError] with [ErrCodeDenied] if the user did not consent to sharing
+// them with the plugin.
+func (c *RPCClient) GetCredentials(ctx context.Context, serverAddress string) (CredentialsResult, error) {
+	var res CredentialsResult
+	err := c.Call(ctx, MethodGetCredentials, CredentialsParams{ServerAddress: serverAddress}, &res)
+	return res, err
+}
+
+// Prompt prompts the user on the CLI's terminal. It returns an [RPCError]
+// with [ErrCodeUnavailable] if the CLI is not attached to a terminal.
+func (c *RPCClient) Prompt(ctx context.Context, params PromptParams) (PromptResult, error) {
+	var res PromptResult
+	err := c.Call(ctx, MethodPrompt, params, &res)
+	return res, err
+}
+
+// TraceContext returns the trace context of the CLI's span for the plugin
+// invocation.
+func (c *RPCClient) TraceContext(ctx context.Context) (TraceContextResult, error) {
+	var res TraceContextResult
+	err := c.Call(ctx, MethodTraceContext, nil, &res)
+	return res, err
+}
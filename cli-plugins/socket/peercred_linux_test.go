@@ -0,0 +1,25 @@
+package socket
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestCheckPeerCredentials(t *testing.T) {
+	srv, err := NewPluginServer(nil)
+	assert.NilError(t, err)
+	defer srv.Close()
+
+	conn, err := net.Dial("unix", srv.Addr().String())
+	assert.NilError(t, err)
+	defer conn.Close()
+	assert.Check(t, checkPeerCredentials(conn))
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	assert.Check(t, is.Error(checkPeerCredentials(c1), "not a unix socket connection"))
+}
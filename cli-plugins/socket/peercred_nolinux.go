@@ -0,0 +1,9 @@
+//go:build !linux
+
+package socket
+
+import "net"
+
+func checkPeerCredentials(net.Conn) error {
+	return nil
+}
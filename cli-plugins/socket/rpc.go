@@ -0,0 +1,355 @@
+// FIXME(thaJeztah): remove once we are a module; the go:build directive prevents go from downgrading language version to go1.16:
+//go:build go1.22
+
+package socket
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProtocolVersion is the most recent version of the request/response protocol
+// spoken over the plugin socket.
+//
+// Connections to the plugin socket are used for lifecycle signalling by
+// default (see [ConnectAndWait]); a plugin that wants to call methods provided
+// by the CLI opens a separate connection, and starts it by sending a [Hello]
+// message with the highest protocol version it supports, and the token that
+// was passed to it in [TokenEnvKey]. The CLI replies with a [Hello] message
+// containing the version that is used for the rest of the connection, which
+// is the lowest of both versions, and the list of methods it provides. After
+// that, the plugin sends [Request] messages, to which the CLI replies with a
+// [Response] message, in order. All messages are encoded as JSON.
+const ProtocolVersion = 1
+
+// TokenEnvKey is the well-known environment variable used to pass the plugin
+// being executed the token it must send in its [Hello] message to call the
+// methods provided by the CLI. Connections without the token are closed.
+const TokenEnvKey = "DOCKER_CLI_PLUGIN_SOCKET_TOKEN"
+
+// rpcHandshakeTimeout is the maximum time to wait for the CLI to reply to the
+// handshake. CLI versions that don't support requests don't reply at all.
+var rpcHandshakeTimeout = 2 * time.Second
+
+// ErrNoSocket is returned by [DialRPC] if the plugin was not started by a CLI
+// providing a plugin socket.
+var ErrNoSocket = errors.New("plugin socket not available")
+
+// ErrRPCNotSupported is returned by [DialRPC] if the CLI that started the
+// plugin does not support requests on the plugin socket.
+var ErrRPCNotSupported = errors.New("the docker CLI does not support requests from plugins; upgrade the docker CLI to use this plugin")
+
+// Hello is the first message sent on a connection by both the plugin and
+// the CLI to negotiate the protocol version.
+type Hello struct {
+	// Version is the protocol version. In the message sent by the plugin,
+	// it is the highest version the plugin supports. In the reply sent by
+	// the CLI, it is the version to use.
+	Version int
+	// Token is the token that was passed to the plugin in [TokenEnvKey]. It
+	// is only set in the message sent by the plugin.
+	Token string `json:",omitempty"`
+	// Methods is the list of methods provided by the CLI. It is only set
+	// in the reply sent by the CLI.
+	Methods []string `json:",omitempty"`
+}
+
+// Request is a message sent by the plugin to call a method provided by the CLI.
+type Request struct {
+	ID     uint64
+	Method string
+	Params json.RawMessage `json:",omitempty"`
+}
+
+// Response is the reply of the CLI to a [Request] with the same ID. Either
+// Result or Error is set.
+type Response struct {
+	ID     uint64
+	Result json.RawMessage `json:",omitempty"`
+	Error  *RPCError       `json:",omitempty"`
+}
+
+// Error codes used in [RPCError].
+const (
+	// ErrCodeUnknownMethod is used if the requested method is not provided
+	// by the CLI.
+	ErrCodeUnknownMethod = "unknown-method"
+	// ErrCodeInvalidParams is used if the request parameters are invalid.
+	ErrCodeInvalidParams = "invalid-params"
+	// ErrCodeDenied is used if the request was denied, for example because
+	// the user did not consent to it.
+	ErrCodeDenied = "denied"
+	// ErrCodeUnavailable is used if the request can not be handled in the
+	// current environment, for example because there is no terminal to
+	// prompt on.
+	ErrCodeUnavailable = "unavailable"
+	// ErrCodeInternal is used for any other error.
+	ErrCodeInternal = "internal"
+)
+
+// RPCError is the error returned by the CLI for a failed request.
+type RPCError struct {
+	Code    string
+	Message string
+}
+
+// NewRPCError returns a new [RPCError] with the given code and message.
+func NewRPCError(code string, format string, args ...any) *RPCError {
+	return &RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// IsRPCError returns true if err is an [RPCError] with the given code.
+func IsRPCError(err error, code string) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr) && rpcErr.Code == code
+}
+
+// RPCMethod handles requests for a method. The params are the raw parameters
+// of the request, and the returned value is encoded as the result. Errors
+// that are not an [RPCError] are returned to the plugin as [ErrCodeInternal].
+type RPCMethod func(ctx context.Context, params json.RawMessage) (any, error)
+
+// NewRPCToken returns a new random token for [NewRPCHandler]. A new token
+// must be used for each plugin invocation, and passed to the plugin in
+// [TokenEnvKey].
+func NewRPCToken() string {
+	return randomID()
+}
+
+// NewRPCHandler returns a connection handler for [NewPluginServer] which
+// handles requests for the given methods. Connections that are only used for
+// lifecycle signalling are left untouched. Connections that don't send the
+// token in their [Hello] message, or (on Linux) that are opened by a process
+// of another user, are closed.
+func NewRPCHandler(ctx context.Context, token string, methods map[string]RPCMethod) func(net.Conn) {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(conn net.Conn) {
+		if err := checkPeerCredentials(conn); err != nil {
+			logrus.Debugf("Closing plugin connection: %v", err)
+			_ = conn.Close()
+			return
+		}
+
+		dec := json.NewDecoder(conn)
+		enc := json.NewEncoder(conn)
+
+		// Connections used for lifecycle signalling never send anything,
+		// so this returns once the connection is closed.
+		var hello Hello
+		if err := dec.Decode(&hello); err != nil {
+			return
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(hello.Token), []byte(token)) != 1 {
+			logrus.Debug("Closing plugin connection with an invalid token")
+			_ = conn.Close()
+			return
+		}
+		if hello.Version < 1 {
+			logrus.Debugf("Closing plugin connection with unsupported protocol version %d", hello.Version)
+			_ = conn.Close()
+			return
+		}
+		if err := enc.Encode(Hello{Version: min(hello.Version, ProtocolVersion), Methods: names}); err != nil {
+			return
+		}
+
+		for {
+			var req Request
+			if err := dec.Decode(&req); err != nil {
+				return
+			}
+			if err := enc.Encode(handleRequest(ctx, methods, req)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func handleRequest(ctx context.Context, methods map[string]RPCMethod, req Request) Response {
+	resp := Response{ID: req.ID}
+	m, ok := methods[req.Method]
+	if !ok {
+		resp.Error = NewRPCError(ErrCodeUnknownMethod, "unknown method: %s", req.Method)
+		return resp
+	}
+	result, err := m(ctx, req.Params)
+	if err == nil {
+		resp.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: ErrCodeInternal, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return resp
+}
+
+// RPCClient calls methods provided by the CLI over the plugin socket.
+// Requests are sent one at a time; an RPCClient is safe for concurrent use.
+type RPCClient struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	enc     *json.Encoder
+	dec     *json.Decoder
+	lastID  uint64
+	err     error
+	version int
+	methods []string
+}
+
+// DialRPC connects to the socket passed via well-known env var, and
+// negotiates the protocol version with the CLI, using the token that is
+// passed in [TokenEnvKey]. It returns [ErrNoSocket] if the env var is not
+// set, and [ErrRPCNotSupported] if the CLI does not support requests on the
+// plugin socket.
+func DialRPC(ctx context.Context) (*RPCClient, error) {
+	socketAddr := os.Getenv(EnvKey)
+	if socketAddr == "" {
+		return nil, ErrNoSocket
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", socketAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &RPCClient{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}
+
+	deadline := time.Now().Add(rpcHandshakeTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	var hello Hello
+	err = c.enc.Encode(Hello{Version: ProtocolVersion, Token: os.Getenv(TokenEnvKey)})
+	if err == nil {
+		err = c.dec.Decode(&hello)
+	}
+	if err != nil {
+		_ = conn.Close()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the docker CLI closed the plugin socket connection: invalid token")
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil {
+			return nil, ErrRPCNotSupported
+		}
+		return nil, err
+	}
+	if hello.Version < 1 || hello.Version > ProtocolVersion {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unsupported plugin protocol version: %d", hello.Version)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c.version = hello.Version
+	c.methods = hello.Methods
+	return c, nil
+}
+
+// Version returns the negotiated protocol version.
+func (c *RPCClient) Version() int {
+	return c.version
+}
+
+// Supports returns true if the CLI provides the given method.
+func (c *RPCClient) Supports(method string) bool {
+	for _, m := range c.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Call calls method with the given params, and decodes the result into result,
+// which may be nil to discard it. Errors returned by the CLI are an [RPCError].
+//
+// If ctx is cancelled before the CLI replied, the connection is closed, and
+// subsequent calls fail.
+func (c *RPCClient) Call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	req := Request{Method: method}
+	if params != nil {
+		p, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = p
+	}
+	c.lastID++
+	req.ID = c.lastID
+
+	stop := context.AfterFunc(ctx, func() {
+		// Interrupt any pending read or write.
+		_ = c.conn.SetDeadline(time.Now())
+	})
+	var resp Response
+	err := c.enc.Encode(req)
+	if err == nil {
+		err = c.dec.Decode(&resp)
+	}
+	if err == nil && resp.ID != req.ID {
+		err = fmt.Errorf("unexpected response ID %d for request %d", resp.ID, req.ID)
+	}
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err != nil {
+		c.err = fmt.Errorf("plugin socket connection closed: %w", err)
+		_ = c.conn.Close()
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Close closes the connection to the CLI.
+func (c *RPCClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = net.ErrClosed
+	}
+	return c.conn.Close()
+}
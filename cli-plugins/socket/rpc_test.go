@@ -0,0 +1,172 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestRPCServer(t *testing.T, methods map[string]RPCMethod) {
+	t.Helper()
+	token := NewRPCToken()
+	srv, err := NewPluginServer(NewRPCHandler(context.Background(), token, methods))
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	t.Setenv(EnvKey, srv.Addr().String())
+	t.Setenv(TokenEnvKey, token)
+}
+
+func TestRPC(t *testing.T) {
+	type echoParams struct {
+		Message string
+	}
+	newTestRPCServer(t, map[string]RPCMethod{
+		"echo": func(_ context.Context, params json.RawMessage) (any, error) {
+			var p echoParams
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, NewRPCError(ErrCodeInvalidParams, "invalid parameters: %v", err)
+			}
+			return p, nil
+		},
+		"deny": func(context.Context, json.RawMessage) (any, error) {
+			return nil, NewRPCError(ErrCodeDenied, "not allowed")
+		},
+		"fail": func(context.Context, json.RawMessage) (any, error) {
+			return nil, errors.New("something went wrong")
+		},
+	})
+
+	ctx := context.Background()
+	c, err := DialRPC(ctx)
+	assert.NilError(t, err)
+	defer c.Close()
+
+	assert.Check(t, is.Equal(c.Version(), ProtocolVersion))
+	assert.Check(t, c.Supports("echo"))
+	assert.Check(t, !c.Supports("unknown"))
+
+	t.Run("result", func(t *testing.T) {
+		var res echoParams
+		err := c.Call(ctx, "echo", echoParams{Message: "hello"}, &res)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Message, "hello"))
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		err := c.Call(ctx, "unknown", nil, nil)
+		assert.Check(t, IsRPCError(err, ErrCodeUnknownMethod))
+		assert.Check(t, is.Error(err, "unknown method: unknown"))
+	})
+
+	t.Run("rpc error", func(t *testing.T) {
+		err := c.Call(ctx, "deny", nil, nil)
+		assert.Check(t, IsRPCError(err, ErrCodeDenied))
+		assert.Check(t, is.Error(err, "not allowed"))
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		err := c.Call(ctx, "fail", nil, nil)
+		assert.Check(t, IsRPCError(err, ErrCodeInternal))
+		assert.Check(t, is.Error(err, "something went wrong"))
+	})
+
+	t.Run("still usable after errors", func(t *testing.T) {
+		var res echoParams
+		err := c.Call(ctx, "echo", echoParams{Message: "again"}, &res)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(res.Message, "again"))
+	})
+}
+
+func TestRPCContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	newTestRPCServer(t, map[string]RPCMethod{
+		"block": func(context.Context, json.RawMessage) (any, error) {
+			<-release
+			return nil, nil
+		},
+	})
+
+	c, err := DialRPC(context.Background())
+	assert.NilError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = c.Call(ctx, "block", nil, nil)
+	assert.Check(t, is.ErrorIs(err, context.DeadlineExceeded))
+
+	err = c.Call(context.Background(), "block", nil, nil)
+	assert.Check(t, is.ErrorContains(err, "plugin socket connection closed"))
+}
+
+func TestRPCLifecycleConnection(t *testing.T) {
+	srv, err := NewPluginServer(NewRPCHandler(context.Background(), NewRPCToken(), nil))
+	assert.NilError(t, err)
+
+	addr, err := net.ResolveUnixAddr("unix", srv.Addr().String())
+	assert.NilError(t, err)
+	conn, err := net.DialUnix("unix", nil, addr)
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected no data on lifecycle connection, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.NilError(t, srv.Close())
+	select {
+	case err := <-done:
+		assert.Check(t, is.ErrorIs(err, io.EOF))
+	case <-time.After(time.Second):
+		t.Fatal("expected EOF after closing the server")
+	}
+}
+
+func TestDialRPC(t *testing.T) {
+	t.Run("no socket", func(t *testing.T) {
+		t.Setenv(EnvKey, "")
+		_, err := DialRPC(context.Background())
+		assert.Check(t, is.ErrorIs(err, ErrNoSocket))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		newTestRPCServer(t, nil)
+		for _, token := range []string{"", "invalid"} {
+			t.Setenv(TokenEnvKey, token)
+			_, err := DialRPC(context.Background())
+			assert.Check(t, is.Error(err, "the docker CLI closed the plugin socket connection: invalid token"))
+		}
+	})
+
+	t.Run("unsupported CLI", func(t *testing.T) {
+		defer func(orig time.Duration) { rpcHandshakeTimeout = orig }(rpcHandshakeTimeout)
+		rpcHandshakeTimeout = 10 * time.Millisecond
+
+		// CLI versions that don't support requests accept connections,
+		// but never reply.
+		srv, err := NewPluginServer(nil)
+		assert.NilError(t, err)
+		defer srv.Close()
+		t.Setenv(EnvKey, srv.Addr().String())
+
+		_, err = DialRPC(context.Background())
+		assert.Check(t, is.ErrorIs(err, ErrRPCNotSupported))
+	})
+}
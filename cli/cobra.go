@@ -20,6 +20,10 @@
 	"github.com/spf13/pflag"
 )
 
+// UserAliasAnnotation is the annotation of the command stubs of user-defined
+// aliases, which holds the command the alias expands to.
+const UserAliasAnnotation = "com.docker.cli.user-alias"
+
 // setupCommonRootCommand contains the setup common to
 // SetupRootCommand and SetupPluginRootCommand.
 func setupCommonRootCommand(rootCmd *cobra.Command) (*cliflags.ClientOptions, *cobra.Command) {
@@ -33,12 +37,14 @@ func setupCommonRootCommand(rootCmd *cobra.Command) (*cliflags.ClientOptions, *c
 	cobra.AddTemplateFunc("hasManagementSubCommands", hasManagementSubCommands)
 	cobra.AddTemplateFunc("hasSwarmSubCommands", hasSwarmSubCommands)
 	cobra.AddTemplateFunc("hasInvalidPlugins", hasInvalidPlugins)
+	cobra.AddTemplateFunc("hasUserAliases", hasUserAliases)
 	cobra.AddTemplateFunc("topCommands", topCommands)
 	cobra.AddTemplateFunc("commandAliases", commandAliases)
 	cobra.AddTemplateFunc("operationSubCommands", operationSubCommands)
 	cobra.AddTemplateFunc("managementSubCommands", managementSubCommands)
 	cobra.AddTemplateFunc("orchestratorSubCommands", orchestratorSubCommands)
 	cobra.AddTemplateFunc("invalidPlugins", invalidPlugins)
+	cobra.AddTemplateFunc("userAliases", userAliases)
 	cobra.AddTemplateFunc("wrappedFlagUsages", wrappedFlagUsages)
 	cobra.AddTemplateFunc("vendorAndVersion", vendorAndVersion)
 	cobra.AddTemplateFunc("invalidPluginReason", invalidPluginReason)
@@ -275,6 +281,10 @@ func hasInvalidPlugins(cmd *cobra.Command) bool {
 	return len(invalidPlugins(cmd)) > 0
 }
 
+func hasUserAliases(cmd *cobra.Command) bool {
+	return len(userAliases(cmd)) > 0
+}
+
 func hasTopCommands(cmd *cobra.Command) bool {
 	return len(topCommands(cmd)) > 0
 }
@@ -321,7 +331,7 @@ func topCommands(cmd *cobra.Command) []*cobra.Command {
 func operationSubCommands(cmd *cobra.Command) []*cobra.Command {
 	cmds := []*cobra.Command{}
 	for _, sub := range cmd.Commands() {
-		if isPlugin(sub) {
+		if isPlugin(sub) || isUserAlias(sub) {
 			continue
 		}
 		if _, ok := sub.Annotations["category-top"]; ok {
@@ -416,6 +426,21 @@ func invalidPlugins(cmd *cobra.Command) []*cobra.Command {
 	return cmds
 }
 
+func isUserAlias(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[UserAliasAnnotation]
+	return ok
+}
+
+func userAliases(cmd *cobra.Command) []*cobra.Command {
+	cmds := []*cobra.Command{}
+	for _, sub := range cmd.Commands() {
+		if isUserAlias(sub) {
+			cmds = append(cmds, sub)
+		}
+	}
+	return cmds
+}
+
 func invalidPluginReason(cmd *cobra.Command) string {
 	return cmd.Annotations[pluginmanager.CommandAnnotationPluginInvalid]
 }
@@ -490,6 +515,16 @@ func invalidPluginReason(cmd *cobra.Command) string {
 {{- end}}
 {{- end}}
 
+{{- if hasUserAliases . }}
+
+User Aliases:
+
+{{- range userAliases . }}
+  {{rpad .Name .NamePadding }} {{.Short}}
+{{- end}}
+
+{{- end}}
+
 {{- if hasInvalidPlugins . }}
 
 Invalid Plugins:
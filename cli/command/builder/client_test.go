@@ -10,6 +10,7 @@
 type fakeClient struct {
 	client.Client
 	builderPruneFunc func(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error)
+	diskUsageFunc    func(ctx context.Context, opts types.DiskUsageOptions) (types.DiskUsage, error)
 }
 
 func (c *fakeClient) BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
@@ -18,3 +19,10 @@ func (c *fakeClient) BuildCachePrune(ctx context.Context, opts types.BuildCacheP
 	}
 	return nil, nil
 }
+
+func (c *fakeClient) DiskUsage(ctx context.Context, opts types.DiskUsageOptions) (types.DiskUsage, error) {
+	if c.diskUsageFunc != nil {
+		return c.diskUsageFunc(ctx, opts)
+	}
+	return types.DiskUsage{}, nil
+}
@@ -9,6 +9,7 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/errdefs"
@@ -21,6 +22,7 @@ type pruneOptions struct {
 	all         bool
 	filter      opts.FilterOpt
 	keepStorage opts.MemBytes
+	dryRun      prune.DryRunOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for images
@@ -32,6 +34,17 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove build cache",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.dryRun.Validate(); err != nil {
+				return err
+			}
+			if options.dryRun.Enabled {
+				return runPruneDryRun(cmd.Context(), dockerCli, options)
+			}
+			confirmed, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, options.force)
+			if err != nil {
+				return err
+			}
+			options.force = options.force || confirmed
 			spaceReclaimed, output, err := runPrune(cmd.Context(), dockerCli, options)
 			if err != nil {
 				return err
@@ -51,6 +64,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVarP(&options.all, "all", "a", false, "Remove all unused build cache, not just dangling ones")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "until=24h")`)
 	flags.Var(&options.keepStorage, "keep-storage", "Amount of disk space to keep for cache")
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -100,6 +114,22 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return report.SpaceReclaimed, output, nil
 }
 
+// runPruneDryRun lists the build cache that would be removed, without
+// removing it.
+func runPruneDryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	if options.keepStorage.Value() != 0 {
+		return errors.New("--keep-storage is not supported with --dry-run")
+	}
+	pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
+	candidates, err := prune.Plan(ctx, dockerCli.Client(), prune.Policy{
+		BuildCache: &prune.Rule{Filters: pruneFilters, All: options.all},
+	})
+	if err != nil {
+		return err
+	}
+	return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+}
+
 // CachePrune executes a prune command for build cache
 func CachePrune(ctx context.Context, dockerCli command.Cli, all bool, filter opts.FilterOpt) (uint64, string, error) {
 	return runPrune(ctx, dockerCli, pruneOptions{force: true, all: all, filter: filter})
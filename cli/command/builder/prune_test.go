@@ -5,9 +5,12 @@
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/docker/cli/internal/test"
 	"github.com/docker/docker/api/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestBuilderPromptTermination(t *testing.T) {
@@ -24,3 +27,32 @@ func TestBuilderPromptTermination(t *testing.T) {
 	cmd.SetErr(io.Discard)
 	test.TerminatePrompt(ctx, t, cmd, cli)
 }
+
+func TestBuilderPruneDryRun(t *testing.T) {
+	now := time.Now()
+	cli := test.NewFakeCli(&fakeClient{
+		diskUsageFunc: func(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				BuildCache: []*types.BuildCache{
+					{ID: "recent1234567890", Type: "regular", Description: "[2/2] RUN make", Size: 1024, CreatedAt: now},
+					{ID: "old1234567890123", Type: "regular", Description: "[1/2] FROM alpine", Size: 2048, CreatedAt: now.Add(-96 * time.Hour)},
+					{ID: "local12345678901", Type: "source.local", Description: "local source", Size: 4096, CreatedAt: now.Add(-96 * time.Hour)},
+				},
+			}, nil
+		},
+		builderPruneFunc: func(context.Context, types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
+			return nil, errors.New("fakeClient builderPruneFunc should not be called")
+		},
+	})
+	cmd := NewPruneCommand(cli)
+	cmd.SetArgs([]string{"--dry-run", "--filter", "until=72h", "--filter", "type=regular"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expected := `TYPE          ID             NAME                SIZE
+build-cache   old123456789   [1/2] FROM alpine   2.048kB
+Total reclaimable space: 2.048kB
+`
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+}
@@ -29,9 +29,10 @@
 	dopts "github.com/docker/cli/opts"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/registry"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/registry"
 	"github.com/docker/go-connections/tlsconfig"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -81,6 +82,7 @@ type DockerCli struct {
 	contentTrust       bool
 	contextStore       store.Store
 	currentContext     string
+	effectiveContext   string
 	init               sync.Once
 	initErr            error
 	dockerEndpoint     docker.Endpoint
@@ -239,8 +241,8 @@ func (*DockerCli) ManifestStore() manifeststore.Store {
 // RegistryClient returns a client for communicating with a Docker distribution
 // registry
 func (cli *DockerCli) RegistryClient(allowInsecure bool) registryclient.RegistryClient {
-	resolver := func(ctx context.Context, index *registry.IndexInfo) registry.AuthConfig {
-		return ResolveAuthConfig(cli.ConfigFile(), index)
+	resolver := func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig {
+		return ResolveRepositoryAuthConfig(ctx, cli.ConfigFile(), repoInfo)
 	}
 	return registryclient.NewRegistryClient(resolver, UserAgent(), allowInsecure)
 }
@@ -279,11 +281,12 @@ func (cli *DockerCli) Initialize(opts *cliflags.ClientOptions, ops ...CLIOption)
 	cli.configFile = config.LoadDefaultConfigFile(cli.err)
 	cli.currentContext = resolveContextName(cli.options, cli.configFile)
 	cli.contextStore = &ContextStoreWithDefault{
-		Store: store.New(config.ContextStoreDir(), cli.contextStoreConfig),
+		Store: store.NewLayered(config.ContextStoreDir(), config.SystemContextStoreDirs(), cli.contextStoreConfig),
 		Resolver: func() (*DefaultContext, error) {
 			return ResolveDefaultContext(cli.options, cli.contextStoreConfig)
 		},
 	}
+	cli.applyContextConfig()
 
 	// TODO(krissetto): pass ctx to the funcs instead of using this
 	if cli.enableGlobalMeter {
@@ -304,7 +307,7 @@ func NewAPIClientFromFlags(opts *cliflags.ClientOptions, configFile *configfile.
 
 	storeConfig := DefaultContextStoreConfig()
 	contextStore := &ContextStoreWithDefault{
-		Store: store.New(config.ContextStoreDir(), storeConfig),
+		Store: store.NewLayered(config.ContextStoreDir(), config.SystemContextStoreDirs(), storeConfig),
 		Resolver: func() (*DefaultContext, error) {
 			return ResolveDefaultContext(opts, storeConfig)
 		},
@@ -393,7 +396,12 @@ func (cli *DockerCli) initializeFromClient() {
 		}
 		return
 	}
+	cli.initializeFromPing(ping)
+}
 
+// initializeFromPing initializes the server info and API version from the
+// daemon's response to a ping.
+func (cli *DockerCli) initializeFromPing(ping types.Ping) {
 	cli.serverInfo = ServerInfo{
 		HasExperimental: ping.Experimental,
 		OSType:          ping.OSType,
@@ -495,13 +503,19 @@ func (cli *DockerCli) initialize() error {
 			cli.initErr = errors.Wrap(cli.initErr, "unable to resolve docker endpoint")
 			return
 		}
+		if cli.baseCtx == nil {
+			cli.baseCtx = context.Background()
+		}
 		if cli.client == nil {
+			var ping *types.Ping
+			cli.dockerEndpoint, cli.effectiveContext, ping = cli.failover(cli.dockerEndpoint)
 			if cli.client, cli.initErr = newAPIClientFromEndpoint(cli.dockerEndpoint, cli.configFile); cli.initErr != nil {
 				return
 			}
-		}
-		if cli.baseCtx == nil {
-			cli.baseCtx = context.Background()
+			if ping != nil {
+				cli.initializeFromPing(*ping)
+				return
+			}
 		}
 		cli.initializeFromClient()
 	})
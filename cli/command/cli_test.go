@@ -16,14 +16,17 @@
 	"testing"
 	"time"
 
+	"github.com/distribution/reference"
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/configfile"
+	configtypes "github.com/docker/cli/cli/config/types"
 	"github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/fs"
 )
 
@@ -395,3 +398,44 @@ func TestHooksEnabled(t *testing.T) {
 		assert.Check(t, !cli.HooksEnabled())
 	})
 }
+
+func TestRegistryClientUsesRepositoryCredentials(t *testing.T) {
+	users := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v2/" {
+			users <- user
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	cli, err := NewDockerCli()
+	assert.NilError(t, err)
+	cli.configFile = configfile.New("filename")
+	cli.configFile.AuthConfigs = map[string]configtypes.AuthConfig{
+		host:             {Username: "registry-user", Password: "pass"},
+		host + "/team-a": {Username: "team-a-user", Password: "pass"},
+	}
+
+	for image, expected := range map[string]string{
+		host + "/team-a/app:latest": "team-a-user",
+		host + "/team-b/app:latest": "registry-user",
+	} {
+		ref, err := reference.ParseNormalizedNamed(image)
+		assert.NilError(t, err)
+		_, _ = cli.RegistryClient(true).GetManifest(context.Background(), ref)
+		select {
+		case user := <-users:
+			assert.Check(t, is.Equal(user, expected), image)
+		default:
+			t.Errorf("%s: no authenticated request was made", image)
+		}
+	}
+}
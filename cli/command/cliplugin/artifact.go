@@ -0,0 +1,144 @@
+package cliplugin
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli-plugins/manager"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// mediaTypePluginBinary is the media type of the layer that holds the
+// executable of a CLI plugin. A CLI plugin is published as an image index,
+// with a manifest for each platform that has a single layer of this type.
+const mediaTypePluginBinary = "application/vnd.docker.cli-plugin.binary.v1"
+
+// pluginArtifact is the executable of a CLI plugin for the current platform,
+// as published in a registry.
+type pluginArtifact struct {
+	ref     reference.Named
+	version string
+	binary  distribution.Descriptor
+}
+
+// pluginNameFromReference returns the name of the plugin that is published
+// at the given reference, which is the last component of the repository path
+// without the "docker-" prefix. For example, the plugin published as
+// "example.com/tools/docker-policy:1.0" is named "policy".
+func pluginNameFromReference(ref reference.Named) (string, error) {
+	name := strings.TrimPrefix(path.Base(reference.Path(ref)), manager.NamePrefix)
+	if !manager.IsValidName(name) {
+		return "", errors.Errorf("invalid plugin name %q in reference %s: plugin names must consist of lower case letters and digits, and start with a letter", name, reference.FamiliarString(ref))
+	}
+	return name, nil
+}
+
+// resolvePlugin looks up the executable of the plugin for the current platform
+// in the image index at the given reference.
+func resolvePlugin(ctx context.Context, registryClient registryclient.RegistryClient, ref reference.Named) (pluginArtifact, error) {
+	manifests, err := registryClient.GetManifestList(ctx, ref)
+	if err != nil {
+		return pluginArtifact{}, errors.Wrapf(err, "failed to get the plugin index for %s", reference.FamiliarString(ref))
+	}
+
+	platform := platforms.DefaultSpec()
+	matcher := platforms.Only(platform)
+	var best *manifesttypes.ImageManifest
+	for i, m := range manifests {
+		if m.Descriptor.Platform == nil || !matcher.Match(*m.Descriptor.Platform) {
+			continue
+		}
+		if best == nil || matcher.Less(*m.Descriptor.Platform, *best.Descriptor.Platform) {
+			best = &manifests[i]
+		}
+	}
+	if best == nil {
+		return pluginArtifact{}, errors.Errorf("%s does not provide the plugin for %s", reference.FamiliarString(ref), platforms.Format(platform))
+	}
+	if best.OCIManifest == nil {
+		return pluginArtifact{}, errors.Errorf("%s is not a CLI plugin: the manifest for %s is not an OCI image manifest", reference.FamiliarString(ref), platforms.Format(platform))
+	}
+
+	artifact := pluginArtifact{ref: ref}
+	for _, layer := range best.OCIManifest.Layers {
+		if layer.MediaType == mediaTypePluginBinary {
+			if artifact.binary.Digest != "" {
+				return pluginArtifact{}, errors.Errorf("%s is not a CLI plugin: the manifest for %s has more than one %s layer", reference.FamiliarString(ref), platforms.Format(platform), mediaTypePluginBinary)
+			}
+			artifact.binary = layer
+		}
+	}
+	if artifact.binary.Digest == "" {
+		return pluginArtifact{}, errors.Errorf("%s is not a CLI plugin: the manifest for %s has no %s layer", reference.FamiliarString(ref), platforms.Format(platform), mediaTypePluginBinary)
+	}
+	if err := artifact.binary.Digest.Validate(); err != nil {
+		return pluginArtifact{}, errors.Wrapf(err, "invalid digest for the plugin executable in %s", reference.FamiliarString(ref))
+	}
+
+	artifact.version = best.OCIManifest.Annotations[ocispec.AnnotationVersion]
+	if tagged, ok := ref.(reference.Tagged); ok && artifact.version == "" {
+		artifact.version = tagged.Tag()
+	}
+	return artifact, nil
+}
+
+// download fetches the executable of the plugin, verifies it against its
+// digest and size, validates it as a CLI plugin, and replaces the file at
+// target with it. The file at target is left untouched if the executable
+// cannot be verified, or is not a valid CLI plugin.
+func (a pluginArtifact) download(ctx context.Context, registryClient registryclient.RegistryClient, target string, rootCmd *cobra.Command) error {
+	rc, err := registryClient.GetBlob(ctx, a.ref, a.binary.Digest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download the plugin executable from %s", reference.FamiliarString(a.ref))
+	}
+	defer rc.Close()
+
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// The executable is downloaded to a temporary directory, so that it is
+	// never picked up as a plugin candidate before it is validated, but has
+	// the same name as the installed plugin.
+	tmpDir, err := os.MkdirTemp(dir, ".install-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmp, err := os.OpenFile(filepath.Join(tmpDir, filepath.Base(target)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+
+	verifier := a.binary.Digest.Verifier()
+	n, err := io.Copy(io.MultiWriter(tmp, verifier), io.LimitReader(rc, a.binary.Size+1))
+	if err != nil {
+		return errors.Wrapf(err, "failed to download the plugin executable from %s", reference.FamiliarString(a.ref))
+	}
+	if n != a.binary.Size || !verifier.Verified() {
+		return errors.Errorf("failed to verify the plugin executable from %s: content does not match digest %s", reference.FamiliarString(a.ref), a.binary.Digest)
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if _, err := manager.ValidatePlugin(tmp.Name(), rootCmd); err != nil {
+		return errors.Wrapf(err, "%s is not a valid CLI plugin", reference.FamiliarString(a.ref))
+	}
+	return os.Rename(tmp.Name(), target)
+}
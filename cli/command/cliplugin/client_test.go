@@ -0,0 +1,117 @@
+package cliplugin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/ocischema"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// pluginBinary returns the executable of a CLI plugin with the given version,
+// as a shell script that prints the plugin's metadata.
+func pluginBinary(t *testing.T, version string) []byte {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	return []byte(`#!/bin/sh
+if [ "$1" = "docker-cli-plugin-metadata" ]; then
+	echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing","Version":"` + version + `"}'
+fi
+`)
+}
+
+type fakeRegistryClient struct {
+	getManifestListFunc func(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error)
+	getBlobFunc         func(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error)
+}
+
+func (*fakeRegistryClient) GetManifest(context.Context, reference.Named) (manifesttypes.ImageManifest, error) {
+	return manifesttypes.ImageManifest{}, nil
+}
+
+func (c *fakeRegistryClient) GetManifestList(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error) {
+	if c.getManifestListFunc != nil {
+		return c.getManifestListFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (*fakeRegistryClient) MountBlob(context.Context, reference.Canonical, reference.Named) error {
+	return nil
+}
+
+func (*fakeRegistryClient) PutManifest(context.Context, reference.Named, distribution.Manifest) (digest.Digest, error) {
+	return "", nil
+}
+
+func (c *fakeRegistryClient) GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+	if c.getBlobFunc != nil {
+		return c.getBlobFunc(ctx, ref, dgst)
+	}
+	return nil, nil
+}
+
+var _ client.RegistryClient = &fakeRegistryClient{}
+
+// fakePluginRegistry returns a registry client that serves a plugin with
+// the given version and executable, for the current platform and for
+// another platform.
+func fakePluginRegistry(t *testing.T, version string, binary []byte) *fakeRegistryClient {
+	t.Helper()
+	binaryDesc := distribution.Descriptor{
+		MediaType: mediaTypePluginBinary,
+		Digest:    digest.FromBytes(binary),
+		Size:      int64(len(binary)),
+	}
+	newManifest := func(ref reference.Named, platform ocispec.Platform) manifesttypes.ImageManifest {
+		mfst, err := ocischema.FromStruct(ocischema.Manifest{
+			Versioned: manifest.Versioned{SchemaVersion: 2, MediaType: ocispec.MediaTypeImageManifest},
+			Config:    distribution.Descriptor{MediaType: ocispec.MediaTypeEmptyJSON, Digest: ocispec.DescriptorEmptyJSON.Digest, Size: 2},
+			Layers:    []distribution.Descriptor{binaryDesc},
+			Annotations: map[string]string{
+				ocispec.AnnotationVersion: version,
+			},
+		})
+		assert.NilError(t, err)
+		return manifesttypes.NewOCIImageManifest(ref, ocispec.Descriptor{Platform: &platform}, mfst)
+	}
+	return &fakeRegistryClient{
+		getManifestListFunc: func(_ context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error) {
+			return []manifesttypes.ImageManifest{
+				newManifest(ref, ocispec.Platform{OS: "plan9", Architecture: "amd64"}),
+				newManifest(ref, platforms.DefaultSpec()),
+			}, nil
+		},
+		getBlobFunc: func(_ context.Context, _ reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+			assert.Check(t, dgst == binaryDesc.Digest)
+			return io.NopCloser(bytes.NewReader(binary)), nil
+		},
+	}
+}
+
+// setupConfigDir sets the config directory, into which plugins are
+// installed, to a temporary directory.
+func setupConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	oldDir := config.Dir()
+	config.SetDir(dir)
+	t.Cleanup(func() {
+		config.SetDir(oldDir)
+	})
+	return dir
+}
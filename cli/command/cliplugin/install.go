@@ -0,0 +1,99 @@
+package cliplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type installOptions struct {
+	remote  string
+	force   bool
+	rootCmd *cobra.Command
+}
+
+func newInstallCommand(dockerCli command.Cli) *cobra.Command {
+	var opts installOptions
+
+	cmd := &cobra.Command{
+		Use:   "install [OPTIONS] REFERENCE",
+		Short: "Install a CLI plugin from a registry",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.remote = args[0]
+			opts.rootCmd = cmd.Root()
+			return runInstall(cmd.Context(), dockerCli, opts)
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.force, "force", false, "Replace a plugin that was not installed from a registry")
+	return cmd
+}
+
+func runInstall(ctx context.Context, dockerCli command.Cli, opts installOptions) error {
+	ref, err := reference.ParseNormalizedNamed(opts.remote)
+	if err != nil {
+		return err
+	}
+	ref = reference.TagNameOnly(ref)
+
+	name, err := pluginNameFromReference(ref)
+	if err != nil {
+		return err
+	}
+	installed, err := loadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+	if _, ok := installed.Plugins[name]; !ok && !opts.force {
+		target, err := manager.UserPluginPath(name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(target); err == nil {
+			return errors.Errorf("plugin %s was not installed from a registry: use --force to replace %s", name, target)
+		}
+	}
+
+	artifact, err := resolvePlugin(ctx, dockerCli.RegistryClient(false), ref)
+	if err != nil {
+		return err
+	}
+	p, err := installPlugin(ctx, dockerCli, opts.rootCmd, installed, name, artifact)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(dockerCli.Out(), "Installed plugin %s %s (%s)\n", name, p.Version, p.Digest)
+	return nil
+}
+
+// installPlugin downloads the executable of the plugin into the user's plugin
+// directory, and records it as installed from the artifact's reference. The
+// plugin must not conflict with a command of rootCmd.
+func installPlugin(ctx context.Context, dockerCli command.Cli, rootCmd *cobra.Command, installed *installedPlugins, name string, artifact pluginArtifact) (installedPlugin, error) {
+	target, err := manager.UserPluginPath(name)
+	if err != nil {
+		return installedPlugin{}, err
+	}
+	if err := artifact.download(ctx, dockerCli.RegistryClient(false), target, rootCmd); err != nil {
+		return installedPlugin{}, err
+	}
+	p := installedPlugin{
+		Reference:   reference.FamiliarString(artifact.ref),
+		Version:     artifact.version,
+		Digest:      artifact.binary.Digest,
+		InstalledAt: time.Now().UTC().Truncate(time.Second),
+	}
+	installed.Plugins[name] = p
+	return p, installed.save()
+}
@@ -0,0 +1,210 @@
+package cliplugin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli-plugins/manager"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestInstall(t *testing.T) {
+	dir := setupConfigDir(t)
+	binary := pluginBinary(t, "1.2.0")
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(fakePluginRegistry(t, "1.2.0", binary))
+
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"registry.example.com/tools/docker-policy:1.2.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "Installed plugin policy 1.2.0 ("+digest.FromBytes(binary).String()+")\n"))
+
+	target, err := manager.UserPluginPath("policy")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(filepath.Dir(target), filepath.Join(dir, "cli-plugins")))
+	content, err := os.ReadFile(target)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(content, binary))
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(target)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(fi.Mode().Perm(), os.FileMode(0o755)))
+	}
+
+	installed, err := loadInstalledPlugins()
+	assert.NilError(t, err)
+	p, ok := installed.Plugins["policy"]
+	assert.Assert(t, ok)
+	assert.Check(t, is.Equal(p.Reference, "registry.example.com/tools/docker-policy:1.2.0"))
+	assert.Check(t, is.Equal(p.Version, "1.2.0"))
+	assert.Check(t, is.Equal(p.Digest, digest.FromBytes(binary)))
+	assert.Check(t, !p.InstalledAt.IsZero())
+}
+
+func TestInstallVerifiesDigest(t *testing.T) {
+	setupConfigDir(t)
+	registryClient := fakePluginRegistry(t, "1.2.0", pluginBinary(t, "1.2.0"))
+	registryClient.getBlobFunc = func(context.Context, reference.Named, digest.Digest) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("#!/bin/sh\necho tampered\n"))), nil
+	}
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registryClient)
+
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"registry.example.com/tools/docker-policy:1.2.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.ErrorContains(cmd.Execute(), "failed to verify the plugin executable from registry.example.com/tools/docker-policy:1.2.0"))
+
+	target, err := manager.UserPluginPath("policy")
+	assert.NilError(t, err)
+	entries, err := os.ReadDir(filepath.Dir(target))
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(entries, 0))
+
+	installed, err := loadInstalledPlugins()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(installed.Plugins, 0))
+}
+
+func TestInstallExistingPlugin(t *testing.T) {
+	setupConfigDir(t)
+	binary := pluginBinary(t, "1.2.0")
+	target, err := manager.UserPluginPath("policy")
+	assert.NilError(t, err)
+	assert.NilError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	assert.NilError(t, os.WriteFile(target, []byte("#!/bin/sh\necho manual\n"), 0o755))
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(fakePluginRegistry(t, "1.2.0", binary))
+
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"registry.example.com/tools/docker-policy:1.2.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), "plugin policy was not installed from a registry: use --force to replace "+target))
+
+	cmd = newInstallCommand(cli)
+	cmd.SetArgs([]string{"--force", "registry.example.com/tools/docker-policy:1.2.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	content, err := os.ReadFile(target)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(content, binary))
+}
+
+func TestInstallInvalidPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	testCases := []struct {
+		doc           string
+		binary        []byte
+		builtin       string
+		expectedError string
+	}{
+		{
+			doc:           "invalid metadata",
+			binary:        []byte("#!/bin/sh\necho policy\n"),
+			expectedError: "registry.example.com/tools/docker-policy:1.2.0 is not a valid CLI plugin: invalid metadata",
+		},
+		{
+			doc:           "invalid schema version",
+			binary:        []byte("#!/bin/sh\necho '{\"SchemaVersion\":\"0.2.0\",\"Vendor\":\"e2e-testing\"}'\n"),
+			expectedError: `registry.example.com/tools/docker-policy:1.2.0 is not a valid CLI plugin: plugin SchemaVersion "0.2.0" is not valid, must be 0.1.0`,
+		},
+		{
+			doc:           "duplicates builtin command",
+			binary:        pluginBinary(t, "1.2.0"),
+			builtin:       "policy",
+			expectedError: `registry.example.com/tools/docker-policy:1.2.0 is not a valid CLI plugin: plugin "policy" duplicates builtin command`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			setupConfigDir(t)
+			cli := test.NewFakeCli(nil)
+			cli.SetRegistryClient(fakePluginRegistry(t, "1.2.0", tc.binary))
+
+			cmd := newInstallCommand(cli)
+			rootCmd := &cobra.Command{Use: "docker"}
+			rootCmd.AddCommand(cmd)
+			if tc.builtin != "" {
+				rootCmd.AddCommand(&cobra.Command{Use: tc.builtin})
+			}
+			rootCmd.SetArgs([]string{"install", "registry.example.com/tools/docker-policy:1.2.0"})
+			rootCmd.SetOut(io.Discard)
+			rootCmd.SetErr(io.Discard)
+			assert.Check(t, is.ErrorContains(rootCmd.Execute(), tc.expectedError))
+
+			target, err := manager.UserPluginPath("policy")
+			assert.NilError(t, err)
+			entries, err := os.ReadDir(filepath.Dir(target))
+			assert.NilError(t, err)
+			assert.Check(t, is.Len(entries, 0))
+
+			installed, err := loadInstalledPlugins()
+			assert.NilError(t, err)
+			assert.Check(t, is.Len(installed.Plugins, 0))
+		})
+	}
+}
+
+func TestInstallErrors(t *testing.T) {
+	testCases := []struct {
+		doc           string
+		args          []string
+		manifestList  func(context.Context, reference.Named) ([]manifesttypes.ImageManifest, error)
+		expectedError string
+	}{
+		{
+			doc:           "invalid plugin name",
+			args:          []string{"registry.example.com/tools/Policy-Plugin"},
+			expectedError: "invalid reference format",
+		},
+		{
+			doc:           "invalid plugin name in repository",
+			args:          []string{"registry.example.com/tools/docker-policy-plugin"},
+			expectedError: `invalid plugin name "policy-plugin" in reference registry.example.com/tools/docker-policy-plugin:latest`,
+		},
+		{
+			doc:  "no manifest for platform",
+			args: []string{"registry.example.com/tools/docker-policy"},
+			manifestList: func(context.Context, reference.Named) ([]manifesttypes.ImageManifest, error) {
+				return nil, nil
+			},
+			expectedError: "registry.example.com/tools/docker-policy:latest does not provide the plugin for " + runtime.GOOS,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			setupConfigDir(t)
+			registryClient := fakePluginRegistry(t, "1.2.0", []byte("#!/bin/sh\n"))
+			if tc.manifestList != nil {
+				registryClient.getManifestListFunc = tc.manifestList
+			}
+			cli := test.NewFakeCli(nil)
+			cli.SetRegistryClient(registryClient)
+
+			cmd := newInstallCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			assert.Check(t, is.ErrorContains(cmd.Execute(), tc.expectedError))
+		})
+	}
+}
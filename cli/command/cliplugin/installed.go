@@ -0,0 +1,80 @@
+package cliplugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/docker/pkg/atomicwriter"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// installedFileName is the name of the file in the "cli-plugins" directory
+// inside the CLIs config directory that records which plugins were installed
+// from a registry.
+const installedFileName = "installed.json"
+
+// installedPlugin records a CLI plugin that was installed from a registry.
+type installedPlugin struct {
+	// Reference is the reference the plugin was installed from, and is
+	// updated from.
+	Reference string `json:"reference"`
+	// Version is the version of the plugin, as annotated in its manifest,
+	// or the tag it was installed from.
+	Version string `json:"version,omitempty"`
+	// Digest is the digest of the plugin's executable.
+	Digest digest.Digest `json:"digest"`
+	// InstalledAt is when the plugin was installed or last updated.
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+// installedPlugins is the content of the installedFileName file.
+type installedPlugins struct {
+	Plugins map[string]installedPlugin `json:"plugins"`
+}
+
+func installedFilePath() (string, error) {
+	return config.Path("cli-plugins", installedFileName)
+}
+
+// loadInstalledPlugins reads the plugins that were installed from a registry.
+func loadInstalledPlugins() (*installedPlugins, error) {
+	installed := &installedPlugins{Plugins: make(map[string]installedPlugin)}
+	fileName, err := installedFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return installed, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, installed); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	if installed.Plugins == nil {
+		installed.Plugins = make(map[string]installedPlugin)
+	}
+	return installed, nil
+}
+
+// save writes the plugins that were installed from a registry.
+func (i *installedPlugins) save() error {
+	fileName, err := installedFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(i, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+		return err
+	}
+	return atomicwriter.WriteFile(fileName, data, 0o644)
+}
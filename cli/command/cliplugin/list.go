@@ -0,0 +1,156 @@
+package cliplugin
+
+import (
+	"sort"
+	"time"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultListTableFormat = "table {{.Name}}\t{{.Version}}\t{{.Reference}}"
+	defaultListQuietFormat = "{{.Name}}"
+
+	versionHeader   = "VERSION"
+	referenceHeader = "REFERENCE"
+	digestHeader    = "DIGEST"
+	installedHeader = "INSTALLED AT"
+)
+
+type listOptions struct {
+	quiet  bool
+	format string
+}
+
+func newListCommand(dockerCli command.Cli) *cobra.Command {
+	var opts listOptions
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Short:   "List CLI plugins that were installed from a registry",
+		Aliases: []string{"list"},
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(dockerCli, opts)
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only display plugin names")
+	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+	return cmd
+}
+
+// listEntry is a plugin that was installed from a registry, with its name.
+type listEntry struct {
+	name string
+	installedPlugin
+}
+
+func runList(dockerCli command.Cli, opts listOptions) error {
+	installed, err := loadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+	entries := make([]listEntry, 0, len(installed.Plugins))
+	for name, p := range installed.Plugins {
+		entries = append(entries, listEntry{name: name, installedPlugin: p})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	format := opts.format
+	if len(format) == 0 {
+		format = formatter.TableFormatKey
+	}
+	return listFormatWrite(formatter.Context{
+		Output: dockerCli.Out(),
+		Format: newListFormat(format, opts.quiet),
+	}, entries)
+}
+
+func newListFormat(source string, quiet bool) formatter.Format {
+	switch source {
+	case formatter.TableFormatKey:
+		if quiet {
+			return defaultListQuietFormat
+		}
+		return defaultListTableFormat
+	case formatter.RawFormatKey:
+		if quiet {
+			return `name: {{.Name}}`
+		}
+		return `name: {{.Name}}\nversion: {{.Version}}\nreference: {{.Reference}}\ndigest: {{.Digest}}\n`
+	}
+	return formatter.Format(source)
+}
+
+func listFormatWrite(ctx formatter.Context, entries []listEntry) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, e := range entries {
+			if err := format(&listContext{e: e}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	listCtx := listContext{}
+	listCtx.Header = formatter.SubHeaderContext{
+		"Name":        formatter.NameHeader,
+		"Version":     versionHeader,
+		"Reference":   referenceHeader,
+		"Digest":      digestHeader,
+		"InstalledAt": installedHeader,
+	}
+	return ctx.Write(&listCtx, render)
+}
+
+type listContext struct {
+	formatter.HeaderContext
+	e listEntry
+}
+
+func (c *listContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *listContext) Name() string {
+	return c.e.name
+}
+
+func (c *listContext) Version() string {
+	return c.e.Version
+}
+
+func (c *listContext) Reference() string {
+	return c.e.Reference
+}
+
+func (c *listContext) Digest() string {
+	return c.e.Digest.String()
+}
+
+func (c *listContext) InstalledAt() string {
+	return c.e.installedPlugin.InstalledAt.Format(time.RFC3339)
+}
+
+// completeInstalledPlugins completes the names of the plugins that were
+// installed from a registry.
+func completeInstalledPlugins(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	installed, err := loadInstalledPlugins()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(installed.Plugins))
+	for name := range installed.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
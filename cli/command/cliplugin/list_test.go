@@ -0,0 +1,64 @@
+package cliplugin
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestList(t *testing.T) {
+	dir := setupConfigDir(t)
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "cli-plugins"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "cli-plugins", installedFileName), []byte(`{
+	"plugins": {
+		"policy": {
+			"reference": "registry.example.com/tools/docker-policy:1",
+			"version": "1.1.0",
+			"digest": "sha256:7e4ef1f2fa0a3a4c0f4de2f84bbd4b7a4da0a5ad48ec8d4a1f0d6fc2c3c21c9e",
+			"installedAt": "2026-10-01T12:00:00Z"
+		},
+		"audit": {
+			"reference": "registry.example.com/tools/docker-audit@sha256:0b8a6ba4e2c4d0e1ba6e4d4e6e0ad4d2c63b3e0f6a1d5b8f3e9c4d2a1b0c9d8e",
+			"digest": "sha256:3f0a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8c0d2e4f6a8b0c2d4e6f8a",
+			"installedAt": "2026-09-01T12:00:00Z"
+		}
+	}
+}`), 0o644))
+
+	testCases := []struct {
+		doc    string
+		args   []string
+		golden string
+	}{
+		{
+			doc:    "default",
+			golden: "cli-plugin-list.golden",
+		},
+		{
+			doc:    "quiet",
+			args:   []string{"--quiet"},
+			golden: "cli-plugin-list-quiet.golden",
+		},
+		{
+			doc:    "format",
+			args:   []string{"--format", "{{.Name}} {{.Digest}} {{.InstalledAt}}"},
+			golden: "cli-plugin-list-format.golden",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			cli := test.NewFakeCli(nil)
+			cmd := newListCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			assert.NilError(t, cmd.Execute())
+			golden.Assert(t, cli.OutBuffer().String(), tc.golden)
+		})
+	}
+}
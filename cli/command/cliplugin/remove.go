@@ -0,0 +1,63 @@
+package cliplugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+type removeOptions struct {
+	plugins []string
+}
+
+func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
+	var opts removeOptions
+
+	cmd := &cobra.Command{
+		Use:     "rm PLUGIN [PLUGIN...]",
+		Short:   "Remove one or more CLI plugins that were installed from a registry",
+		Aliases: []string{"remove"},
+		Args:    cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.plugins = args
+			return runRemove(dockerCli, opts)
+		},
+		ValidArgsFunction: completeInstalledPlugins,
+	}
+	return cmd
+}
+
+func runRemove(dockerCli command.Cli, opts removeOptions) error {
+	installed, err := loadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range opts.plugins {
+		if _, ok := installed.Plugins[name]; !ok {
+			errs = append(errs, fmt.Errorf("plugin %s was not installed from a registry", name))
+			continue
+		}
+		target, err := manager.UserPluginPath(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+			continue
+		}
+		delete(installed.Plugins, name)
+		_, _ = fmt.Fprintln(dockerCli.Out(), name)
+	}
+	if err := installed.save(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
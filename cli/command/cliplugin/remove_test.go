@@ -0,0 +1,40 @@
+package cliplugin
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestRemove(t *testing.T) {
+	setupConfigDir(t)
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(fakePluginRegistry(t, "1.2.0", pluginBinary(t, "1.2.0")))
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"registry.example.com/tools/docker-policy:1.2.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	cli.OutBuffer().Reset()
+	cmd = newRemoveCommand(cli)
+	cmd.SetArgs([]string{"policy", "other"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), "plugin other was not installed from a registry"))
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "policy\n"))
+
+	target, err := manager.UserPluginPath("policy")
+	assert.NilError(t, err)
+	_, err = os.Stat(target)
+	assert.Check(t, os.IsNotExist(err))
+
+	installed, err := loadInstalledPlugins()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(installed.Plugins, 0))
+}
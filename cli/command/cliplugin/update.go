@@ -0,0 +1,81 @@
+package cliplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+type updateOptions struct {
+	plugins []string
+	rootCmd *cobra.Command
+}
+
+func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
+	var opts updateOptions
+
+	cmd := &cobra.Command{
+		Use:   "update [PLUGIN...]",
+		Short: "Update CLI plugins that were installed from a registry",
+		Long:  "Update CLI plugins that were installed from a registry. All installed plugins are updated if no plugin is specified.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.plugins = args
+			opts.rootCmd = cmd.Root()
+			return runUpdate(cmd.Context(), dockerCli, opts)
+		},
+		ValidArgsFunction: completeInstalledPlugins,
+	}
+	return cmd
+}
+
+func runUpdate(ctx context.Context, dockerCli command.Cli, opts updateOptions) error {
+	installed, err := loadInstalledPlugins()
+	if err != nil {
+		return err
+	}
+	names := opts.plugins
+	if len(names) == 0 {
+		for name := range installed.Plugins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var errs []error
+	for _, name := range names {
+		if err := updatePlugin(ctx, dockerCli, opts.rootCmd, installed, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func updatePlugin(ctx context.Context, dockerCli command.Cli, rootCmd *cobra.Command, installed *installedPlugins, name string) error {
+	current, ok := installed.Plugins[name]
+	if !ok {
+		return fmt.Errorf("plugin %s was not installed from a registry", name)
+	}
+	ref, err := reference.ParseNormalizedNamed(current.Reference)
+	if err != nil {
+		return err
+	}
+	artifact, err := resolvePlugin(ctx, dockerCli.RegistryClient(false), ref)
+	if err != nil {
+		return err
+	}
+	if artifact.binary.Digest == current.Digest {
+		_, _ = fmt.Fprintf(dockerCli.Out(), "Plugin %s is up to date (%s)\n", name, current.Version)
+		return nil
+	}
+	p, err := installPlugin(ctx, dockerCli, rootCmd, installed, name, artifact)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(dockerCli.Out(), "Updated plugin %s from %s to %s (%s)\n", name, current.Version, p.Version, p.Digest)
+	return nil
+}
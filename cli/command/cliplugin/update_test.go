@@ -0,0 +1,65 @@
+package cliplugin
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestUpdate(t *testing.T) {
+	setupConfigDir(t)
+	oldBinary := pluginBinary(t, "1.0.0")
+	newBinary := pluginBinary(t, "1.1.0")
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(fakePluginRegistry(t, "1.0.0", oldBinary))
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"registry.example.com/tools/docker-policy:1"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	cli = test.NewFakeCli(nil)
+	cli.SetRegistryClient(fakePluginRegistry(t, "1.1.0", newBinary))
+	cmd = newUpdateCommand(cli)
+	cmd.SetArgs([]string{})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "Updated plugin policy from 1.0.0 to 1.1.0 ("+digest.FromBytes(newBinary).String()+")\n"))
+
+	target, err := manager.UserPluginPath("policy")
+	assert.NilError(t, err)
+	content, err := os.ReadFile(target)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(content, newBinary))
+
+	installed, err := loadInstalledPlugins()
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(installed.Plugins["policy"].Reference, "registry.example.com/tools/docker-policy:1"))
+	assert.Check(t, is.Equal(installed.Plugins["policy"].Version, "1.1.0"))
+
+	cli.OutBuffer().Reset()
+	cmd = newUpdateCommand(cli)
+	cmd.SetArgs([]string{"policy"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "Plugin policy is up to date (1.1.0)\n"))
+}
+
+func TestUpdateNotInstalled(t *testing.T) {
+	setupConfigDir(t)
+	cli := test.NewFakeCli(nil)
+	cmd := newUpdateCommand(cli)
+	cmd.SetArgs([]string{"policy"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), "plugin policy was not installed from a registry"))
+}
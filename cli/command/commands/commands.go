@@ -6,6 +6,7 @@
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/builder"
 	"github.com/docker/cli/cli/command/checkpoint"
+	"github.com/docker/cli/cli/command/cliplugin"
 	"github.com/docker/cli/cli/command/config"
 	"github.com/docker/cli/cli/command/container"
 	"github.com/docker/cli/cli/command/context"
@@ -45,6 +46,7 @@ func AddCommands(cmd *cobra.Command, dockerCli command.Cli) {
 		// management commands
 		builder.NewBuilderCommand(dockerCli),
 		checkpoint.NewCheckpointCommand(dockerCli),
+		cliplugin.NewCLIPluginCommand(dockerCli),
 		container.NewContainerCommand(dockerCli),
 		context.NewContextCommand(dockerCli),
 		image.NewImageCommand(dockerCli),
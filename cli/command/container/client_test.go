@@ -44,6 +44,7 @@ type fakeClient struct {
 	containerRenameFunc     func(ctx context.Context, oldName, newName string) error
 	containerCommitFunc     func(ctx context.Context, container string, options container.CommitOptions) (container.CommitResponse, error)
 	containerPauseFunc      func(ctx context.Context, container string) error
+	diskUsageFunc           func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
 	Version                 string
 }
 
@@ -174,6 +175,13 @@ func (f *fakeClient) ContainerKill(ctx context.Context, containerID, signal stri
 	return nil
 }
 
+func (f *fakeClient) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	if f.diskUsageFunc != nil {
+		return f.diskUsageFunc(ctx, options)
+	}
+	return types.DiskUsage{}, nil
+}
+
 func (f *fakeClient) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (container.PruneReport, error) {
 	if f.containerPruneFunc != nil {
 		return f.containerPruneFunc(ctx, pruneFilters)
@@ -47,7 +47,8 @@ type StatsEntry struct {
 type Stats struct {
 	mutex sync.RWMutex
 	StatsEntry
-	err error
+	err      error
+	recorder *statsRecorder
 }
 
 // GetError returns the container statistics error.
@@ -92,6 +93,9 @@ func (cs *Stats) SetStatistics(s StatsEntry) {
 	defer cs.mutex.Unlock()
 	s.Container = cs.Container
 	cs.StatsEntry = s
+	if cs.recorder != nil {
+		cs.recorder.record(s)
+	}
 }
 
 // GetStatistics returns container statistics with other meta data such as the container name
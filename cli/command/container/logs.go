@@ -2,11 +2,14 @@
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/internal/logfilter"
+	"github.com/docker/cli/opts"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/spf13/cobra"
@@ -19,21 +22,26 @@ type logsOptions struct {
 	timestamps bool
 	details    bool
 	tail       string
+	filter     opts.FilterOpt
+	logFilter  logfilter.Options
 
-	container string
+	containers []string
 }
 
 // NewLogsCommand creates a new cobra.Command for `docker logs`
 func NewLogsCommand(dockerCli command.Cli) *cobra.Command {
-	var opts logsOptions
+	options := logsOptions{filter: opts.NewFilterOpt()}
 
 	cmd := &cobra.Command{
-		Use:   "logs [OPTIONS] CONTAINER",
-		Short: "Fetch the logs of a container",
-		Args:  cli.ExactArgs(1),
+		Use:   "logs [OPTIONS] CONTAINER [CONTAINER...]",
+		Short: "Fetch the logs of one or more containers",
+		Args:  cli.RequiresMinArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts.container = args[0]
-			return runLogs(cmd.Context(), dockerCli, &opts)
+			if len(args) == 0 && options.filter.Value().Len() == 0 {
+				return errors.New("requires at least 1 container, or a --filter")
+			}
+			options.containers = args
+			return runLogs(cmd.Context(), dockerCli, &options)
 		},
 		Annotations: map[string]string{
 			"aliases": "docker container logs, docker logs",
@@ -42,18 +50,40 @@ func NewLogsCommand(dockerCli command.Cli) *cobra.Command {
 	}
 
 	flags := cmd.Flags()
-	flags.BoolVarP(&opts.follow, "follow", "f", false, "Follow log output")
-	flags.StringVar(&opts.since, "since", "", `Show logs since timestamp (e.g. "2013-01-02T13:23:37Z") or relative (e.g. "42m" for 42 minutes)`)
-	flags.StringVar(&opts.until, "until", "", `Show logs before a timestamp (e.g. "2013-01-02T13:23:37Z") or relative (e.g. "42m" for 42 minutes)`)
+	flags.BoolVarP(&options.follow, "follow", "f", false, "Follow log output")
+	flags.StringVar(&options.since, "since", "", `Show logs since timestamp (e.g. "2013-01-02T13:23:37Z") or relative (e.g. "42m" for 42 minutes)`)
+	flags.StringVar(&options.until, "until", "", `Show logs before a timestamp (e.g. "2013-01-02T13:23:37Z") or relative (e.g. "42m" for 42 minutes)`)
 	flags.SetAnnotation("until", "version", []string{"1.35"})
-	flags.BoolVarP(&opts.timestamps, "timestamps", "t", false, "Show timestamps")
-	flags.BoolVar(&opts.details, "details", false, "Show extra details provided to logs")
-	flags.StringVarP(&opts.tail, "tail", "n", "all", "Number of lines to show from the end of the logs")
+	flags.BoolVarP(&options.timestamps, "timestamps", "t", false, "Show timestamps")
+	flags.BoolVar(&options.details, "details", false, "Show extra details provided to logs")
+	flags.StringVarP(&options.tail, "tail", "n", "all", "Number of lines to show from the end of the logs")
+	flags.Var(&options.filter, "filter", `Show the logs of all containers matching a filter (e.g. "label=com.example.app", "name=web", "network=backend")`)
+	options.logFilter.InstallFlags(flags)
 	return cmd
 }
 
 func runLogs(ctx context.Context, dockerCli command.Cli, opts *logsOptions) error {
-	c, err := dockerCli.Client().ContainerInspect(ctx, opts.container)
+	if len(opts.containers) != 1 || opts.filter.Value().Len() > 0 {
+		// Merge the logs of multiple containers, and prefix each line
+		// with the name of the container. Lines are filtered before
+		// they are prefixed.
+		var f *logfilter.Filter
+		if opts.logFilter.Enabled() {
+			var err error
+			if f, err = logfilter.New(opts.logFilter); err != nil {
+				return err
+			}
+		}
+		return runMergedLogs(ctx, dockerCli, opts, f)
+	}
+
+	stdout, stderr, flush, err := logWriters(dockerCli, opts.logFilter)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	c, err := dockerCli.Client().ContainerInspect(ctx, opts.containers[0])
 	if err != nil {
 		return err
 	}
@@ -74,9 +104,27 @@ func runLogs(ctx context.Context, dockerCli command.Cli, opts *logsOptions) erro
 	defer responseBody.Close()
 
 	if c.Config.Tty {
-		_, err = io.Copy(dockerCli.Out(), responseBody)
+		_, err = io.Copy(stdout, responseBody)
 	} else {
-		_, err = stdcopy.StdCopy(dockerCli.Out(), dockerCli.Err(), responseBody)
+		_, err = stdcopy.StdCopy(stdout, stderr, responseBody)
 	}
 	return err
 }
+
+// logWriters returns the writers to print logs to. If filtering options are
+// set, logs are filtered and formatted before being printed; the returned
+// flush function must be called to print any remaining incomplete line.
+func logWriters(dockerCli command.Cli, options logfilter.Options) (stdout, stderr io.Writer, flush func(), _ error) {
+	if !options.Enabled() {
+		return dockerCli.Out(), dockerCli.Err(), func() {}, nil
+	}
+	f, err := logfilter.New(options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	outW, errW := f.Writer(dockerCli.Out()), f.Writer(dockerCli.Err())
+	return outW, errW, func() {
+		_ = outW.Flush()
+		_ = errW.Flush()
+	}, nil
+}
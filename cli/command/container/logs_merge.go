@@ -0,0 +1,443 @@
+package container
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/logfilter"
+	"github.com/docker/cli/internal/tui"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/morikuni/aec"
+	"github.com/sirupsen/logrus"
+)
+
+// prefixColors are the colors used to prefix log lines with the name of
+// the container they originate from.
+var prefixColors = []aec.ANSI{
+	aec.CyanF,
+	aec.YellowF,
+	aec.GreenF,
+	aec.MagentaF,
+	aec.BlueF,
+	aec.LightCyanF,
+	aec.LightYellowF,
+	aec.LightGreenF,
+	aec.LightMagentaF,
+	aec.LightBlueF,
+}
+
+// mergeReadAhead is the number of lines of each container that are read
+// ahead while merging the logs of containers that are not followed.
+const mergeReadAhead = 64
+
+// logTarget is a container to collect the logs from.
+type logTarget struct {
+	id   string
+	name string
+	tty  bool
+}
+
+// logLine is a single line of logs of a container.
+type logLine struct {
+	target    *logTarget
+	timestamp time.Time
+	rawTime   string
+	message   []byte
+	stderr    bool
+}
+
+// lineWriter splits the logs written to it into lines.
+type lineWriter struct {
+	target *logTarget
+	stderr bool
+	emit   func(logLine)
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		w.line(w.buf[:idx+1])
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits the remaining (unterminated) line, if any.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.line(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) line(b []byte) {
+	l := logLine{target: w.target, stderr: w.stderr, message: append([]byte(nil), b...)}
+
+	// Logs are always requested with timestamps, so that lines of different
+	// containers can be ordered.
+	if ts, msg, ok := bytes.Cut(l.message, []byte(" ")); ok {
+		if t, err := time.Parse(time.RFC3339Nano, string(ts)); err == nil {
+			l.timestamp, l.rawTime, l.message = t, string(ts), msg
+		}
+	}
+	w.emit(l)
+}
+
+// logsMerger collects the logs of multiple containers, and prints them
+// with a prefix for each container.
+type logsMerger struct {
+	dockerCLI command.Cli
+	opts      *logsOptions
+	filter    *logfilter.Filter
+	out       tui.Output
+	errOut    tui.Output
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	active  map[string]bool
+	colors  map[string]aec.ANSI
+	padding int
+	errs    []error
+}
+
+// runMergedLogs fetches the logs of all containers passed, or matching the
+// filter, and merges them into a single stream ordered by timestamp. When
+// following the logs of containers matching a filter, containers that are
+// started afterward are added as they start. If f is not nil, lines are
+// filtered and formatted by f before they are prefixed.
+func runMergedLogs(ctx context.Context, dockerCLI command.Cli, options *logsOptions, f *logfilter.Filter) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	targets, err := resolveLogTargets(ctx, dockerCLI, options)
+	if err != nil {
+		return err
+	}
+	useFilter := options.filter.Value().Len() > 0
+	if len(targets) == 0 && !(useFilter && options.follow) {
+		return errors.New("no containers match the given filter")
+	}
+
+	m := &logsMerger{
+		dockerCLI: dockerCLI,
+		opts:      options,
+		filter:    f,
+		out:       tui.NewOutput(dockerCLI.Out()),
+		errOut:    tui.NewOutput(dockerCLI.Err()),
+		active:    make(map[string]bool),
+		colors:    make(map[string]aec.ANSI),
+	}
+	for _, t := range targets {
+		if len(t.name) > m.padding {
+			m.padding = len(t.name)
+		}
+	}
+
+	var eventErrs <-chan error
+	if useFilter && options.follow {
+		// Subscribe to events before collecting the logs of the existing
+		// containers, so that we don't miss containers started in between.
+		eventErrs = m.watchStarts(ctx)
+	}
+
+	if !options.follow {
+		m.merge(ctx, targets)
+		return errors.Join(m.errs...)
+	}
+
+	for _, t := range targets {
+		m.follow(ctx, t, options.since)
+	}
+
+	if eventErrs != nil {
+		select {
+		case <-ctx.Done():
+		case err := <-eventErrs:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				m.addError(err)
+			}
+		}
+		cancel()
+	}
+	m.wg.Wait()
+	return errors.Join(m.errs...)
+}
+
+// resolveLogTargets returns the containers passed by name, and the containers
+// matching the filter (if any).
+func resolveLogTargets(ctx context.Context, dockerCLI command.Cli, options *logsOptions) ([]*logTarget, error) {
+	apiClient := dockerCLI.Client()
+
+	ids := append([]string(nil), options.containers...)
+	if f := options.filter.Value(); f.Len() > 0 {
+		cs, err := apiClient.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cs {
+			ids = append(ids, c.ID)
+		}
+	}
+
+	var (
+		targets []*logTarget
+		seen    = make(map[string]bool)
+	)
+	for _, id := range ids {
+		c, err := apiClient.ContainerInspect(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		targets = append(targets, &logTarget{
+			id:   c.ID,
+			name: strings.TrimPrefix(c.Name, "/"),
+			tty:  c.Config != nil && c.Config.Tty,
+		})
+	}
+	return targets, nil
+}
+
+// watchStarts adds containers matching the filter as they are started. The
+// returned channel receives an error if the events stream is interrupted.
+func (m *logsMerger) watchStarts(ctx context.Context) <-chan error {
+	apiClient := m.dockerCLI.Client()
+
+	f := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("event", string(events.ActionStart)),
+	)
+	for _, v := range m.opts.filter.Value().Get("label") {
+		f.Add("label", v)
+	}
+	messages, errs := apiClient.Events(ctx, events.ListOptions{Filters: f})
+
+	result := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				result <- err
+				return
+			case e := <-messages:
+				m.mu.Lock()
+				active := m.active[e.Actor.ID]
+				m.mu.Unlock()
+				if active {
+					continue
+				}
+
+				// Not all filters are supported by events (such as "network"),
+				// so verify that the container matches the filter.
+				f := m.opts.filter.Value().Clone()
+				f.Add("id", e.Actor.ID)
+				cs, err := apiClient.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
+				if err != nil || len(cs) == 0 {
+					continue
+				}
+				c, err := apiClient.ContainerInspect(ctx, e.Actor.ID)
+				if err != nil {
+					logrus.Debugf("failed to inspect started container %s: %v", e.Actor.ID, err)
+					continue
+				}
+				// Only collect logs from the moment the container was started,
+				// to prevent printing logs of a previous run again.
+				since := fmt.Sprintf("%d.%09d", e.TimeNano/int64(time.Second), e.TimeNano%int64(time.Second))
+				m.follow(ctx, &logTarget{
+					id:   c.ID,
+					name: strings.TrimPrefix(c.Name, "/"),
+					tty:  c.Config != nil && c.Config.Tty,
+				}, since)
+			}
+		}
+	}()
+	return result
+}
+
+// merge collects the logs of the given containers, and prints them ordered
+// by timestamp. The logs of each container are ordered already, so they are
+// merged as they are read, keeping only the next few lines of each container
+// in memory.
+func (m *logsMerger) merge(ctx context.Context, targets []*logTarget) {
+	streams := make([]chan logLine, len(targets))
+	for i, t := range targets {
+		m.register(t)
+		streams[i] = make(chan logLine, mergeReadAhead)
+		go m.read(ctx, t, streams[i])
+	}
+
+	heads := make(logHeads, 0, len(streams))
+	for i, s := range streams {
+		if l, ok := <-s; ok {
+			heads = append(heads, logHead{line: l, stream: i})
+		}
+	}
+	heap.Init(&heads)
+	for heads.Len() > 0 {
+		m.print(heads[0].line)
+		if l, ok := <-streams[heads[0].stream]; ok {
+			heads[0].line = l
+			heap.Fix(&heads, 0)
+		} else {
+			heap.Pop(&heads)
+		}
+	}
+}
+
+// read collects the logs of the given container into lines, and closes it
+// once all logs are read.
+func (m *logsMerger) read(ctx context.Context, t *logTarget, lines chan<- logLine) {
+	defer close(lines)
+	emit := func(l logLine) { lines <- l }
+	if err := m.collect(ctx, t, m.opts.since, emit); err != nil && !errors.Is(err, context.Canceled) {
+		m.addError(fmt.Errorf("failed to get logs of %s: %w", t.name, err))
+	}
+}
+
+// register assigns a color to the given container, and widens the prefix to
+// fit its name.
+func (m *logsMerger) register(t *logTarget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(t.name) > m.padding {
+		m.padding = len(t.name)
+	}
+	if _, ok := m.colors[t.id]; !ok {
+		m.colors[t.id] = prefixColors[len(m.colors)%len(prefixColors)]
+	}
+}
+
+// follow starts collecting the logs of the given container in the background.
+func (m *logsMerger) follow(ctx context.Context, t *logTarget, since string) {
+	m.mu.Lock()
+	if m.active[t.id] {
+		m.mu.Unlock()
+		return
+	}
+	m.active[t.id] = true
+	m.wg.Add(1)
+	m.mu.Unlock()
+	m.register(t)
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.active, t.id)
+			m.mu.Unlock()
+			m.wg.Done()
+		}()
+		if err := m.collect(ctx, t, since, m.print); err != nil && !errors.Is(err, context.Canceled) {
+			m.addError(fmt.Errorf("failed to get logs of %s: %w", t.name, err))
+		}
+	}()
+}
+
+func (m *logsMerger) collect(ctx context.Context, t *logTarget, since string, emit func(logLine)) error {
+	body, err := m.dockerCLI.Client().ContainerLogs(ctx, t.id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      since,
+		Until:      m.opts.until,
+		Timestamps: true,
+		Follow:     m.opts.follow,
+		Tail:       m.opts.tail,
+		Details:    m.opts.details,
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	stdout := &lineWriter{target: t, emit: emit}
+	stderr := &lineWriter{target: t, emit: emit, stderr: true}
+	defer stdout.Flush()
+	defer stderr.Flush()
+
+	if t.tty {
+		_, err = io.Copy(stdout, body)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, body)
+	}
+	return err
+}
+
+func (m *logsMerger) addError(err error) {
+	m.mu.Lock()
+	m.errs = append(m.errs, err)
+	m.mu.Unlock()
+}
+
+// print prints a log line, prefixed with the name of its container.
+func (m *logsMerger) print(l logLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := m.out
+	if l.stderr {
+		out = m.errOut
+	}
+	message := l.message
+	if m.filter != nil {
+		var ok bool
+		if message, ok = m.filter.Apply(message, out); !ok {
+			return
+		}
+	}
+	prefix := m.out.Color(m.colors[l.target.id]).Apply(fmt.Sprintf("%-*s |", m.padding, l.target.name))
+	if m.opts.timestamps && l.rawTime != "" {
+		prefix = l.rawTime + " " + prefix
+	}
+	_, _ = fmt.Fprintf(out, "%s %s", prefix, message)
+}
+
+// logHead is the next line of one of the logs that are merged.
+type logHead struct {
+	line   logLine
+	stream int
+}
+
+// logHeads is a min-heap of the next lines of the logs that are merged,
+// ordered by timestamp. Lines with the same timestamp are ordered by the
+// order in which the containers were passed.
+type logHeads []logHead
+
+func (h logHeads) Len() int { return len(h) }
+
+func (h logHeads) Less(i, j int) bool {
+	if !h[i].line.timestamp.Equal(h[j].line.timestamp) {
+		return h[i].line.timestamp.Before(h[j].line.timestamp)
+	}
+	return h[i].stream < h[j].stream
+}
+
+func (h logHeads) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *logHeads) Push(x interface{}) { *h = append(*h, x.(logHead)) }
+
+func (h *logHeads) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
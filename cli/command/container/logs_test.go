@@ -1,13 +1,20 @@
 package container
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/cli/cli/internal/logfilter"
 	"github.com/docker/cli/internal/test"
+	"github.com/docker/cli/opts"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 )
@@ -37,7 +44,7 @@ func TestRunLogs(t *testing.T) {
 		{
 			doc:         "successful logs",
 			expectedOut: "foo",
-			options:     &logsOptions{},
+			options:     &logsOptions{containers: []string{"container-id"}},
 			client:      &fakeClient{logFunc: logFn("foo"), inspectFunc: inspectFn},
 		},
 	}
@@ -57,3 +64,133 @@ func TestRunLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestRunMergedLogs(t *testing.T) {
+	inspectFn := func(containerID string) (container.InspectResponse, error) {
+		return container.InspectResponse{
+			Config:            &container.Config{Tty: containerID == "id-db"},
+			ContainerJSONBase: &container.ContainerJSONBase{ID: containerID, Name: "/" + strings.TrimPrefix(containerID, "id-")},
+		}, nil
+	}
+	logFunc := func(containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+		assert.Check(t, options.Timestamps, "timestamps must be requested to merge logs")
+		switch containerID {
+		case "id-web":
+			var buf bytes.Buffer
+			_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte("2025-01-01T00:00:01.000000000Z web first\n"))
+			_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte("2025-01-01T00:00:03.000000000Z web error\n"))
+			return io.NopCloser(&buf), nil
+		case "id-db":
+			return io.NopCloser(strings.NewReader("2025-01-01T00:00:02.000000000Z db second\n")), nil
+		}
+		return nil, errors.New("unexpected container")
+	}
+
+	testcases := []struct {
+		doc         string
+		options     *logsOptions
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			doc:         "multiple containers",
+			options:     &logsOptions{containers: []string{"id-web", "id-db"}, filter: opts.NewFilterOpt()},
+			expectedOut: "web | web first\ndb  | db second\n",
+			expectedErr: "web | web error\n",
+		},
+		{
+			doc:         "multiple containers with timestamps",
+			options:     &logsOptions{containers: []string{"id-web", "id-db"}, filter: opts.NewFilterOpt(), timestamps: true},
+			expectedOut: "2025-01-01T00:00:01.000000000Z web | web first\n2025-01-01T00:00:02.000000000Z db  | db second\n",
+			expectedErr: "2025-01-01T00:00:03.000000000Z web | web error\n",
+		},
+		{
+			doc:         "grep matches the message",
+			options:     &logsOptions{containers: []string{"id-web", "id-db"}, filter: opts.NewFilterOpt(), logFilter: logfilter.Options{Grep: "^web error"}},
+			expectedErr: "web | web error\n",
+		},
+		{
+			doc:         "grep with timestamps",
+			options:     &logsOptions{containers: []string{"id-web", "id-db"}, filter: opts.NewFilterOpt(), timestamps: true, logFilter: logfilter.Options{Grep: "^(db|web) "}},
+			expectedOut: "2025-01-01T00:00:01.000000000Z web | web first\n2025-01-01T00:00:02.000000000Z db  | db second\n",
+			expectedErr: "2025-01-01T00:00:03.000000000Z web | web error\n",
+		},
+		{
+			doc:         "grep does not match the prefix",
+			options:     &logsOptions{containers: []string{"id-web", "id-db"}, filter: opts.NewFilterOpt(), logFilter: logfilter.Options{Grep: `\|`}},
+			expectedOut: "",
+		},
+		{
+			doc: "filter",
+			options: func() *logsOptions {
+				o := &logsOptions{filter: opts.NewFilterOpt()}
+				assert.NilError(t, o.filter.Set("label=app"))
+				return o
+			}(),
+			expectedOut: "db | db second\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.doc, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{
+				inspectFunc: inspectFn,
+				logFunc:     logFunc,
+				containerListFunc: func(options container.ListOptions) ([]container.Summary, error) {
+					assert.Check(t, is.DeepEqual(options.Filters.Get("label"), []string{"app"}))
+					return []container.Summary{{ID: "id-db"}, {ID: "id-db"}}, nil
+				},
+			})
+			err := runLogs(context.TODO(), cli, tc.options)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(tc.expectedOut, cli.OutBuffer().String()))
+			assert.Check(t, is.Equal(tc.expectedErr, cli.ErrBuffer().String()))
+		})
+	}
+}
+
+// TestRunMergedLogsInterleaved verifies that logs that are longer than the
+// lines that are read ahead are merged in order.
+func TestRunMergedLogsInterleaved(t *testing.T) {
+	const lines = 3 * mergeReadAhead
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cli := test.NewFakeCli(&fakeClient{
+		inspectFunc: func(containerID string) (container.InspectResponse, error) {
+			return container.InspectResponse{
+				Config:            &container.Config{Tty: true},
+				ContainerJSONBase: &container.ContainerJSONBase{ID: containerID, Name: "/" + containerID},
+			}, nil
+		},
+		logFunc: func(containerID string, _ container.LogsOptions) (io.ReadCloser, error) {
+			offset := 0
+			if containerID == "b" {
+				offset = 1
+			}
+			var buf bytes.Buffer
+			for i := offset; i < 2*lines; i += 2 {
+				fmt.Fprintf(&buf, "%s %d\n", start.Add(time.Duration(i)*time.Second).Format(time.RFC3339Nano), i)
+			}
+			return io.NopCloser(&buf), nil
+		},
+	})
+	err := runLogs(context.TODO(), cli, &logsOptions{containers: []string{"a", "b"}, filter: opts.NewFilterOpt()})
+	assert.NilError(t, err)
+
+	var expected strings.Builder
+	for i := 0; i < 2*lines; i++ {
+		fmt.Fprintf(&expected, "%s | %d\n", []string{"a", "b"}[i%2], i)
+	}
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected.String()))
+}
+
+func TestRunMergedLogsNoMatch(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		containerListFunc: func(container.ListOptions) ([]container.Summary, error) {
+			return nil, nil
+		},
+	})
+	options := &logsOptions{filter: opts.NewFilterOpt()}
+	assert.NilError(t, options.filter.Set("name=nothing"))
+	err := runLogs(context.TODO(), cli, options)
+	assert.Check(t, is.Error(err, "no containers match the given filter"))
+}
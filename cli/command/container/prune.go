@@ -7,6 +7,7 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/errdefs"
 	units "github.com/docker/go-units"
@@ -17,6 +18,7 @@
 type pruneOptions struct {
 	force  bool
 	filter opts.FilterOpt
+	dryRun prune.DryRunOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for containers
@@ -28,6 +30,17 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove all stopped containers",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.dryRun.Validate(); err != nil {
+				return err
+			}
+			if options.dryRun.Enabled {
+				return runPruneDryRun(cmd.Context(), dockerCli, options)
+			}
+			confirmed, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, options.force)
+			if err != nil {
+				return err
+			}
+			options.force = options.force || confirmed
 			spaceReclaimed, output, err := runPrune(cmd.Context(), dockerCli, options)
 			if err != nil {
 				return err
@@ -45,6 +58,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVarP(&options.force, "force", "f", false, "Do not prompt for confirmation")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "until=<timestamp>")`)
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -81,6 +95,19 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return spaceReclaimed, output, nil
 }
 
+// runPruneDryRun lists the containers that would be removed, without
+// removing them.
+func runPruneDryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
+	candidates, err := prune.Plan(ctx, dockerCli.Client(), prune.Policy{
+		Containers: &prune.Rule{Filters: pruneFilters},
+	})
+	if err != nil {
+		return err
+	}
+	return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+}
+
 // RunPrune calls the Container Prune API
 // This returns the amount of space reclaimed and a detailed output string
 func RunPrune(ctx context.Context, dockerCli command.Cli, _ bool, filter opts.FilterOpt) (uint64, string, error) {
@@ -4,11 +4,20 @@
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/streams"
 	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestContainerPrunePromptTermination(t *testing.T) {
@@ -26,3 +35,57 @@ func TestContainerPrunePromptTermination(t *testing.T) {
 	cmd.SetErr(io.Discard)
 	test.TerminatePrompt(ctx, t, cmd, cli)
 }
+
+func TestContainerPruneProtectedContext(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		containerPruneFunc: func(ctx context.Context, pruneFilters filters.Args) (container.PruneReport, error) {
+			return container.PruneReport{}, errors.New("fakeClient containerPruneFunc should not be called")
+		},
+	})
+	contextStore := store.New(t.TempDir(), command.DefaultContextStoreConfig())
+	assert.NilError(t, contextStore.CreateOrUpdate(store.Metadata{
+		Name:     "production",
+		Metadata: command.DockerContext{Protected: true},
+	}))
+	cli.SetContextStore(contextStore)
+	cli.SetCurrentContext("production")
+	cli.SetDockerEndpoint(docker.Endpoint{EndpointMeta: docker.EndpointMeta{Host: "tcp://production.example.com:2376"}})
+	cli.SetIn(streams.NewIn(io.NopCloser(strings.NewReader("y\n"))))
+
+	cmd := NewPruneCommand(cli)
+	cmd.SetArgs([]string{})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	assert.Check(t, errdefs.IsCancelled(err))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), `The current context "production" is protected`))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "tcp://production.example.com:2376"))
+}
+
+func TestContainerPruneDryRun(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		diskUsageFunc: func(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				Containers: []*container.Summary{
+					{ID: "1111111111111111", Names: []string{"/web"}, State: "running", Created: 1},
+					{ID: "2222222222222222", Names: []string{"/old"}, State: "exited", Created: 1, SizeRw: 2048},
+					{ID: "3333333333333333", Names: []string{"/keep"}, State: "exited", Created: 1, Labels: map[string]string{"keep": "true"}},
+				},
+			}, nil
+		},
+		containerPruneFunc: func(context.Context, filters.Args) (container.PruneReport, error) {
+			return container.PruneReport{}, errors.New("fakeClient containerPruneFunc should not be called")
+		},
+	})
+	cmd := NewPruneCommand(cli)
+	cmd.SetArgs([]string{"--dry-run", "--filter", "label!=keep=true", "--filter", "until=24h"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expected := `TYPE        ID             NAME      SIZE
+container   222222222222   old       2.048kB
+Total reclaimable space: 2.048kB
+`
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+}
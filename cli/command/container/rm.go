@@ -33,6 +33,9 @@ func NewRmCommand(dockerCli command.Cli) *cobra.Command {
 		Args:    cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runRm(cmd.Context(), dockerCli, &opts)
 		},
 		Annotations: map[string]string{
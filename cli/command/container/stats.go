@@ -61,6 +61,16 @@ type StatsOptions struct {
 	// above), but may require daemon-side validation as the list of accepted
 	// filters can differ between daemon- and API versions.
 	Filters *filters.Args
+
+	// Record is the path of a file to persist every collected sample to.
+	// Samples are written as newline-delimited JSON, or as CSV if the file
+	// has a ".csv" extension.
+	Record string
+
+	// Replay is the path of a file produced through the Record option. If
+	// set, stats are rendered from the recorded samples instead of being
+	// collected from the daemon, followed by a summary per container.
+	Replay string
 }
 
 // NewStatsCommand creates a new [cobra.Command] for "docker stats".
@@ -86,6 +96,11 @@ func NewStatsCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.BoolVar(&options.NoStream, "no-stream", false, "Disable streaming stats and only pull the first result")
 	flags.BoolVar(&options.NoTrunc, "no-trunc", false, "Do not truncate output")
 	flags.StringVar(&options.Format, "format", "", flagsHelper.FormatHelp)
+	flags.StringVar(&options.Record, "record", "", `Record all samples to a file (newline-delimited JSON, or CSV if the file has a ".csv" extension)`)
+	flags.StringVar(&options.Replay, "replay", "", "Replay samples from a file produced by --record, and summarize them per container")
+	_ = cmd.MarkFlagFilename("record")
+	_ = cmd.MarkFlagFilename("replay")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
 	return cmd
 }
 
@@ -104,7 +119,14 @@ func NewStatsCommand(dockerCLI command.Cli) *cobra.Command {
 // This shows real-time information on CPU usage, memory usage, and network I/O.
 //
 //nolint:gocyclo
-func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions) error {
+func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions) (retErr error) {
+	if options.Replay != "" {
+		if len(options.Containers) > 0 || options.All || options.Record != "" {
+			return errors.New("replaying stats cannot be combined with a list of containers, --all, or --record")
+		}
+		return replayStats(ctx, dockerCLI, options)
+	}
+
 	apiClient := dockerCLI.Client()
 
 	// waitFirst is a WaitGroup to wait first stat data's reach for each container
@@ -113,6 +135,19 @@ func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions)
 	closeChan := make(chan error)
 	cStats := stats{}
 
+	if options.Record != "" {
+		recorder, err := newStatsRecorder(options.Record)
+		if err != nil {
+			return err
+		}
+		cStats.recorder = recorder
+		defer func() {
+			if err := recorder.Close(); err != nil && retErr == nil {
+				retErr = fmt.Errorf("failed to record stats: %w", err)
+			}
+		}()
+	}
+
 	showAll := len(options.Containers) == 0
 	if showAll {
 		// If no names were specified, start a long-running goroutine which
@@ -259,11 +294,13 @@ func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions)
 			format = formatter.TableFormatKey
 		}
 	}
-	if daemonOSType == "" {
+	osType := getDaemonOSType()
+	if osType == "" {
 		// Get the daemonOSType if not set already. The daemonOSType variable
 		// should already be set when collecting stats as part of "collect()",
 		// so we unlikely hit this code in practice.
-		daemonOSType = dockerCLI.ServerInfo().OSType
+		osType = dockerCLI.ServerInfo().OSType
+		setDaemonOSType(osType)
 	}
 
 	// Buffer to store formatted stats text.
@@ -272,7 +309,7 @@ func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions)
 
 	statsCtx := formatter.Context{
 		Output: &statsTextBuffer,
-		Format: NewStatsFormat(format, daemonOSType),
+		Format: NewStatsFormat(format, osType),
 	}
 
 	var err error
@@ -286,29 +323,10 @@ func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions)
 		}
 		cStats.mu.RUnlock()
 
-		if !options.NoStream {
-			// Start by moving the cursor to the top-left
-			_, _ = fmt.Fprint(&statsTextBuffer, "\033[H")
-		}
-
-		if err = statsFormatWrite(statsCtx, ccStats, daemonOSType, !options.NoTrunc); err != nil {
+		if err = writeStatsFrame(dockerCLI.Out(), statsCtx, &statsTextBuffer, ccStats, osType, !options.NoTrunc, !options.NoStream); err != nil {
 			break
 		}
 
-		if !options.NoStream {
-			for _, line := range strings.Split(statsTextBuffer.String(), "\n") {
-				// In case the new text is shorter than the one we are writing over,
-				// we'll append the "erase line" escape sequence to clear the remaining text.
-				_, _ = fmt.Fprintln(&statsTextBuffer, line, "\033[K")
-			}
-
-			// We might have fewer containers than before, so let's clear the remaining text
-			_, _ = fmt.Fprint(&statsTextBuffer, "\033[J")
-		}
-
-		_, _ = fmt.Fprint(dockerCLI.Out(), statsTextBuffer.String())
-		statsTextBuffer.Reset()
-
 		if len(cStats.cs) == 0 && !showAll {
 			break
 		}
@@ -334,6 +352,35 @@ func RunStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions)
 	return err
 }
 
+// writeStatsFrame renders the given stats through buf, and prints the result
+// in a single write to avoid screen flickering. When streaming, the frame is
+// drawn over the previous one.
+func writeStatsFrame(out io.Writer, statsCtx formatter.Context, buf *bytes.Buffer, ccStats []StatsEntry, osType string, trunc, stream bool) error {
+	if stream {
+		// Start by moving the cursor to the top-left
+		_, _ = fmt.Fprint(buf, "\033[H")
+	}
+
+	if err := statsFormatWrite(statsCtx, ccStats, osType, trunc); err != nil {
+		return err
+	}
+
+	if stream {
+		for _, line := range strings.Split(buf.String(), "\n") {
+			// In case the new text is shorter than the one we are writing over,
+			// we'll append the "erase line" escape sequence to clear the remaining text.
+			_, _ = fmt.Fprintln(buf, line, "\033[K")
+		}
+
+		// We might have fewer containers than before, so let's clear the remaining text
+		_, _ = fmt.Fprint(buf, "\033[J")
+	}
+
+	_, _ = fmt.Fprint(out, buf.String())
+	buf.Reset()
+	return nil
+}
+
 // newEventHandler initializes and returns an eventHandler
 func newEventHandler() *eventHandler {
 	return &eventHandler{handlers: make(map[events.Action]func(events.Message))}
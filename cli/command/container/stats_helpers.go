@@ -16,17 +16,37 @@
 type stats struct {
 	mu sync.RWMutex
 	cs []*Stats
+
+	// recorder, if set, persists every sample collected for the containers.
+	recorder *statsRecorder
 }
 
 // daemonOSType is set once we have at least one stat for a container
 // from the daemon. It is used to ensure we print the right header based
-// on the daemon platform.
-var daemonOSType string
+// on the daemon platform. It is set by the goroutines collecting stats,
+// and must be accessed through getDaemonOSType and setDaemonOSType.
+var (
+	daemonOSType   string
+	daemonOSTypeMu sync.RWMutex
+)
+
+func getDaemonOSType() string {
+	daemonOSTypeMu.RLock()
+	defer daemonOSTypeMu.RUnlock()
+	return daemonOSType
+}
+
+func setDaemonOSType(osType string) {
+	daemonOSTypeMu.Lock()
+	defer daemonOSTypeMu.Unlock()
+	daemonOSType = osType
+}
 
 func (s *stats) add(cs *Stats) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if _, exists := s.isKnownContainer(cs.Container); !exists {
+		cs.recorder = s.recorder
 		s.cs = append(s.cs, cs)
 		return true
 	}
@@ -95,9 +115,9 @@ func collect(ctx context.Context, s *Stats, cli client.ContainerAPIClient, strea
 				continue
 			}
 
-			daemonOSType = response.OSType
+			setDaemonOSType(response.OSType)
 
-			if daemonOSType != "windows" {
+			if response.OSType != "windows" {
 				previousCPU = v.PreCPUStats.CPUUsage.TotalUsage
 				previousSystem = v.PreCPUStats.SystemUsage
 				cpuPercent = calculateCPUPercentUnix(previousCPU, previousSystem, v)
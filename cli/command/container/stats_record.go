@@ -0,0 +1,428 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/command/formatter/tabwriter"
+	"github.com/docker/docker/pkg/stringid"
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+// StatsRecord is a single stats sample, as persisted by "docker stats --record".
+type StatsRecord struct {
+	Time   time.Time
+	OSType string
+	StatsEntry
+}
+
+// csvStatsHeader is the header of recordings in CSV format. The order of
+// columns must match [StatsRecord.csvValues].
+var csvStatsHeader = []string{
+	"Time", "OSType", "Container", "Name", "ID",
+	"CPUPercentage", "Memory", "MemoryLimit", "MemoryPercentage",
+	"NetworkRx", "NetworkTx", "BlockRead", "BlockWrite", "PidsCurrent",
+}
+
+func (r StatsRecord) csvValues() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		r.Time.Format(time.RFC3339Nano), r.OSType, r.Container, r.Name, r.ID,
+		f(r.CPUPercentage), f(r.Memory), f(r.MemoryLimit), f(r.MemoryPercentage),
+		f(r.NetworkRx), f(r.NetworkTx), f(r.BlockRead), f(r.BlockWrite),
+		strconv.FormatUint(r.PidsCurrent, 10),
+	}
+}
+
+func statsRecordFromCSV(values []string) (StatsRecord, error) {
+	if len(values) != len(csvStatsHeader) {
+		return StatsRecord{}, errors.Errorf("expected %d columns, got %d", len(csvStatsHeader), len(values))
+	}
+	var (
+		r   StatsRecord
+		err error
+	)
+	if r.Time, err = time.Parse(time.RFC3339Nano, values[0]); err != nil {
+		return StatsRecord{}, err
+	}
+	r.OSType, r.Container, r.Name, r.ID = values[1], values[2], values[3], values[4]
+	for i, dst := range []*float64{
+		&r.CPUPercentage, &r.Memory, &r.MemoryLimit, &r.MemoryPercentage,
+		&r.NetworkRx, &r.NetworkTx, &r.BlockRead, &r.BlockWrite,
+	} {
+		if *dst, err = strconv.ParseFloat(values[5+i], 64); err != nil {
+			return StatsRecord{}, errors.Wrapf(err, "invalid value for %s", csvStatsHeader[5+i])
+		}
+	}
+	if r.PidsCurrent, err = strconv.ParseUint(values[13], 10, 64); err != nil {
+		return StatsRecord{}, errors.Wrapf(err, "invalid value for %s", csvStatsHeader[13])
+	}
+	return r, nil
+}
+
+// isCSVRecording returns whether the recording at the given path uses
+// the CSV format. Recordings use newline-delimited JSON, unless the
+// file has a ".csv" extension.
+func isCSVRecording(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// statsRecorder persists stats samples to a file.
+type statsRecorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+	csv *csv.Writer
+	err error
+}
+
+func newStatsRecorder(path string) (*statsRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create stats recording")
+	}
+	r := &statsRecorder{f: f}
+	if isCSVRecording(path) {
+		r.csv = csv.NewWriter(f)
+		if err := r.csv.Write(csvStatsHeader); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	} else {
+		r.enc = json.NewEncoder(f)
+	}
+	return r, nil
+}
+
+// record appends a sample to the recording. Errors are retained, and
+// returned by [statsRecorder.Close].
+func (r *statsRecorder) record(s StatsEntry) {
+	rec := StatsRecord{Time: time.Now().UTC(), OSType: getDaemonOSType(), StatsEntry: s}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if r.csv != nil {
+		if r.err = r.csv.Write(rec.csvValues()); r.err == nil {
+			r.csv.Flush()
+			r.err = r.csv.Error()
+		}
+		return
+	}
+	r.err = r.enc.Encode(rec)
+}
+
+// Close closes the recording, and returns the first error that occurred
+// while recording. Samples that are recorded after closing are discarded.
+func (r *statsRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.f.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	err := r.err
+	r.err = os.ErrClosed
+	return err
+}
+
+// readStatsRecording reads all samples from a recording, ordered by time.
+func readStatsRecording(rd io.Reader, isCSV bool) ([]StatsRecord, error) {
+	var records []StatsRecord
+	if isCSV {
+		cr := csv.NewReader(rd)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read recording header")
+		}
+		if strings.Join(header, ",") != strings.Join(csvStatsHeader, ",") {
+			return nil, errors.New("invalid recording: unexpected CSV header")
+		}
+		for line := 2; ; line++ {
+			values, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			rec, err := statsRecordFromCSV(values)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid recording: line %d", line)
+			}
+			records = append(records, rec)
+		}
+	} else {
+		scanner := bufio.NewScanner(rd)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var rec StatsRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				return nil, errors.Wrapf(err, "invalid recording: line %d", line)
+			}
+			records = append(records, rec)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// replayInterval is the interval at which recorded samples are grouped into
+// a single frame during replay. It matches the refresh rate of the live view.
+const replayInterval = 500 * time.Millisecond
+
+// replayStats re-renders the stats table from a recording, followed by
+// a summary of the recorded samples for each container.
+func replayStats(ctx context.Context, dockerCLI command.Cli, options *StatsOptions) error {
+	f, err := os.Open(options.Replay)
+	if err != nil {
+		return errors.Wrap(err, "failed to open stats recording")
+	}
+	defer f.Close()
+
+	records, err := readStatsRecording(f, isCSVRecording(options.Replay))
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("stats recording does not contain any samples")
+	}
+
+	format := options.Format
+	if len(format) == 0 {
+		if len(dockerCLI.ConfigFile().StatsFormat) > 0 {
+			format = dockerCLI.ConfigFile().StatsFormat
+		} else {
+			format = formatter.TableFormatKey
+		}
+	}
+	osType := records[0].OSType
+
+	var statsTextBuffer bytes.Buffer
+	statsCtx := formatter.Context{
+		Output: &statsTextBuffer,
+		Format: NewStatsFormat(format, osType),
+	}
+
+	var (
+		order  []string
+		latest = make(map[string]StatsEntry)
+	)
+	renderFrame := func() error {
+		ccStats := make([]StatsEntry, 0, len(order))
+		for _, c := range order {
+			ccStats = append(ccStats, latest[c])
+		}
+		return writeStatsFrame(dockerCLI.Out(), statsCtx, &statsTextBuffer, ccStats, osType, !options.NoTrunc, !options.NoStream)
+	}
+
+	frameStart := records[0].Time
+	for i, rec := range records {
+		if _, ok := latest[rec.Container]; !ok {
+			order = append(order, rec.Container)
+		}
+		latest[rec.Container] = rec.StatsEntry
+
+		if options.NoStream {
+			continue
+		}
+		if i < len(records)-1 && records[i+1].Time.Sub(frameStart) < replayInterval {
+			continue
+		}
+		if err := renderFrame(); err != nil {
+			return err
+		}
+		if i == len(records)-1 {
+			break
+		}
+
+		// Pace the replay according to the time between recorded samples.
+		next := records[i+1].Time
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next.Sub(frameStart)):
+		}
+		frameStart = next
+	}
+	if options.NoStream {
+		if err := renderFrame(); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintln(dockerCLI.Out())
+	return writeStatsSummary(dockerCLI.Out(), summarizeStats(records, order), osType, !options.NoTrunc)
+}
+
+// statsSummary holds the aggregated statistics of a single metric.
+type statsSummary struct {
+	Min, Max, Avg, P95 float64
+}
+
+// containerStatsSummary holds the aggregated statistics of a container.
+type containerStatsSummary struct {
+	Container string
+	Name      string
+	Samples   int
+	CPU       statsSummary
+	Memory    statsSummary
+	MemPerc   statsSummary
+	NetRx     statsSummary
+	NetTx     statsSummary
+	BlkRead   statsSummary
+	BlkWrite  statsSummary
+	Pids      statsSummary
+}
+
+func summarizeStats(records []StatsRecord, order []string) []containerStatsSummary {
+	type samples struct {
+		name                                        string
+		cpu, mem, memPerc, netRx, netTx, blkR, blkW []float64
+		ps                                          []float64
+	}
+	byContainer := make(map[string]*samples)
+	for _, rec := range records {
+		if rec.IsInvalid {
+			continue
+		}
+		s, ok := byContainer[rec.Container]
+		if !ok {
+			s = &samples{}
+			byContainer[rec.Container] = s
+		}
+		if rec.Name != "" {
+			s.name = strings.TrimPrefix(rec.Name, "/")
+		}
+		s.cpu = append(s.cpu, rec.CPUPercentage)
+		s.mem = append(s.mem, rec.Memory)
+		s.memPerc = append(s.memPerc, rec.MemoryPercentage)
+		s.netRx = append(s.netRx, rec.NetworkRx)
+		s.netTx = append(s.netTx, rec.NetworkTx)
+		s.blkR = append(s.blkR, rec.BlockRead)
+		s.blkW = append(s.blkW, rec.BlockWrite)
+		s.ps = append(s.ps, float64(rec.PidsCurrent))
+	}
+
+	summaries := make([]containerStatsSummary, 0, len(byContainer))
+	for _, c := range order {
+		s, ok := byContainer[c]
+		if !ok {
+			continue
+		}
+		summaries = append(summaries, containerStatsSummary{
+			Container: c,
+			Name:      s.name,
+			Samples:   len(s.cpu),
+			CPU:       summarize(s.cpu),
+			Memory:    summarize(s.mem),
+			MemPerc:   summarize(s.memPerc),
+			NetRx:     summarize(s.netRx),
+			NetTx:     summarize(s.netTx),
+			BlkRead:   summarize(s.blkR),
+			BlkWrite:  summarize(s.blkW),
+			Pids:      summarize(s.ps),
+		})
+	}
+	return summaries
+}
+
+// summarize calculates the minimum, maximum, average, and 95th percentile
+// (nearest-rank) of the given values.
+func summarize(values []float64) statsSummary {
+	if len(values) == 0 {
+		return statsSummary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return statsSummary{
+		Min: sorted[0],
+		Max: sorted[len(sorted)-1],
+		Avg: sum / float64(len(sorted)),
+		P95: sorted[rank],
+	}
+}
+
+func writeStatsSummary(out io.Writer, summaries []containerStatsSummary, osType string, trunc bool) error {
+	type metric struct {
+		name    string
+		summary func(containerStatsSummary) statsSummary
+		format  func(float64) string
+	}
+	percent := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) + "%" }
+	count := func(v float64) string { return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64) }
+	// Network and block I/O are formatted the same way as in the stats table.
+	size := func(v float64) string { return units.HumanSizeWithPrecision(v, 3) }
+
+	metrics := []metric{
+		{name: cpuPercHeader, summary: func(s containerStatsSummary) statsSummary { return s.CPU }, format: percent},
+		{name: "MEM USAGE", summary: func(s containerStatsSummary) statsSummary { return s.Memory }, format: units.BytesSize},
+	}
+	if osType != winOSType {
+		metrics = append(metrics,
+			metric{name: memPercHeader, summary: func(s containerStatsSummary) statsSummary { return s.MemPerc }, format: percent},
+		)
+	}
+	metrics = append(metrics,
+		metric{name: "NET RX", summary: func(s containerStatsSummary) statsSummary { return s.NetRx }, format: size},
+		metric{name: "NET TX", summary: func(s containerStatsSummary) statsSummary { return s.NetTx }, format: size},
+		metric{name: "BLOCK READ", summary: func(s containerStatsSummary) statsSummary { return s.BlkRead }, format: size},
+		metric{name: "BLOCK WRITE", summary: func(s containerStatsSummary) statsSummary { return s.BlkWrite }, format: size},
+	)
+	if osType != winOSType {
+		metrics = append(metrics,
+			metric{name: pidsHeader, summary: func(s containerStatsSummary) statsSummary { return s.Pids }, format: count},
+		)
+	}
+
+	w := tabwriter.NewWriter(out, 10, 1, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CONTAINER\tNAME\tSAMPLES\tMETRIC\tMIN\tMAX\tAVG\tP95")
+
+	for _, s := range summaries {
+		id := s.Container
+		if trunc {
+			id = stringid.TruncateID(id)
+		}
+		name := s.Name
+		if name == "" {
+			name = noValue
+		}
+		for _, m := range metrics {
+			v := m.summary(s)
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", id, name, s.Samples, m.name,
+				m.format(v.Min), m.format(v.Max), m.format(v.Avg), m.format(v.P95))
+		}
+	}
+	return w.Flush()
+}
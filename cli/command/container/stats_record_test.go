@@ -0,0 +1,89 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/golden"
+)
+
+func TestStatsRecordRoundTrip(t *testing.T) {
+	for _, name := range []string{"stats.json", "stats.csv"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			recorder, err := newStatsRecorder(path)
+			assert.NilError(t, err)
+
+			cStats := stats{recorder: recorder}
+			s := NewStats("abcdef123456")
+			assert.Check(t, cStats.add(s))
+			s.SetStatistics(StatsEntry{Name: "/web", ID: "abcdef123456", CPUPercentage: 12.5, Memory: 1024, PidsCurrent: 3})
+			s.SetStatistics(StatsEntry{Name: "/web", ID: "abcdef123456", CPUPercentage: 50, Memory: 2048, PidsCurrent: 4})
+			assert.NilError(t, recorder.Close())
+
+			// Samples collected after closing the recording are discarded.
+			s.SetStatistics(StatsEntry{Name: "/web", ID: "abcdef123456"})
+
+			f, err := os.Open(path)
+			assert.NilError(t, err)
+			defer f.Close()
+
+			records, err := readStatsRecording(f, isCSVRecording(path))
+			assert.NilError(t, err)
+			assert.Assert(t, is.Len(records, 2))
+			assert.Check(t, is.Equal(records[0].Container, "abcdef123456"))
+			assert.Check(t, is.Equal(records[0].Name, "/web"))
+			assert.Check(t, is.Equal(records[0].CPUPercentage, 12.5))
+			assert.Check(t, is.Equal(records[1].Memory, 2048.0))
+			assert.Check(t, is.Equal(records[1].PidsCurrent, uint64(4)))
+			assert.Check(t, !records[0].Time.IsZero())
+		})
+	}
+}
+
+func TestReadStatsRecordingInvalid(t *testing.T) {
+	_, err := readStatsRecording(strings.NewReader("{}\nnot-json\n"), false)
+	assert.Check(t, is.ErrorContains(err, "invalid recording: line 2"))
+
+	_, err = readStatsRecording(strings.NewReader("a,b,c\n"), true)
+	assert.Check(t, is.ErrorContains(err, "unexpected CSV header"))
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 20)
+	for i := 20; i > 0; i-- {
+		values = append(values, float64(i))
+	}
+	assert.Check(t, is.DeepEqual(summarize(values), statsSummary{Min: 1, Max: 20, Avg: 10.5, P95: 19}))
+	assert.Check(t, is.DeepEqual(summarize([]float64{7}), statsSummary{Min: 7, Max: 7, Avg: 7, P95: 7}))
+	assert.Check(t, is.DeepEqual(summarize(nil), statsSummary{}))
+}
+
+func TestRunStatsReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	const recording = `{"Time":"2025-01-01T00:00:00Z","OSType":"linux","Container":"abcdef1234567890","Name":"/web","ID":"abcdef1234567890","CPUPercentage":10,"Memory":1048576,"MemoryLimit":2097152,"MemoryPercentage":50,"PidsCurrent":2}
+{"Time":"2025-01-01T00:00:01Z","OSType":"linux","Container":"abcdef1234567890","Name":"/web","ID":"abcdef1234567890","CPUPercentage":30,"Memory":1048576,"MemoryLimit":2097152,"MemoryPercentage":50,"PidsCurrent":4}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(recording), 0o644))
+
+	cli := test.NewFakeCli(&fakeClient{})
+	err := RunStats(context.Background(), cli, &StatsOptions{Replay: path, NoStream: true})
+	assert.NilError(t, err)
+
+	golden.Assert(t, cli.OutBuffer().String(), "stats-replay.golden")
+}
+
+func TestRunStatsReplayErrors(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{})
+	err := RunStats(context.Background(), cli, &StatsOptions{Replay: "stats.json", Containers: []string{"web"}})
+	assert.Check(t, is.ErrorContains(err, "replaying stats cannot be combined"))
+
+	err = RunStats(context.Background(), cli, &StatsOptions{Replay: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Check(t, is.ErrorContains(err, "failed to open stats recording"))
+}
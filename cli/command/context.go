@@ -6,13 +6,28 @@
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/docker/cli/cli/context/store"
 )
 
 // DockerContext is a typed representation of what we put in Context metadata
 type DockerContext struct {
-	Description      string
+	Description string
+
+	// Fallback are the names of the contexts to use, in order, when the
+	// Docker endpoint of this context is unreachable.
+	Fallback []string
+
+	// Config is an overlay of the CLI configuration file, which is merged
+	// over the configuration file when the context is used.
+	Config map[string]any
+
+	// Protected indicates that destructive commands require confirmation
+	// when using the context.
+	Protected bool
+
 	AdditionalFields map[string]any
 }
 
@@ -22,6 +37,15 @@ func (dc DockerContext) MarshalJSON() ([]byte, error) {
 	if dc.Description != "" {
 		s["Description"] = dc.Description
 	}
+	if len(dc.Fallback) > 0 {
+		s["Fallback"] = dc.Fallback
+	}
+	if len(dc.Config) > 0 {
+		s["Config"] = dc.Config
+	}
+	if dc.Protected {
+		s["Protected"] = true
+	}
 	if dc.AdditionalFields != nil {
 		for k, v := range dc.AdditionalFields {
 			s[k] = v
@@ -40,6 +64,30 @@ func (dc *DockerContext) UnmarshalJSON(payload []byte) error {
 		switch k {
 		case "Description":
 			dc.Description = v.(string)
+		case "Fallback":
+			items, ok := v.([]any)
+			if !ok {
+				return errors.New("context fallback must be a list of context names")
+			}
+			for _, item := range items {
+				name, ok := item.(string)
+				if !ok {
+					return errors.New("context fallback must be a list of context names")
+				}
+				dc.Fallback = append(dc.Fallback, name)
+			}
+		case "Config":
+			cfg, ok := v.(map[string]any)
+			if !ok {
+				return errors.New("context config must be an object")
+			}
+			dc.Config = cfg
+		case "Protected":
+			protected, ok := v.(bool)
+			if !ok {
+				return errors.New("context protected must be a boolean")
+			}
+			dc.Protected = protected
 		default:
 			if dc.AdditionalFields == nil {
 				dc.AdditionalFields = make(map[string]any)
@@ -66,3 +114,35 @@ func GetDockerContext(storeMetadata store.Metadata) (DockerContext, error) {
 	}
 	return res, nil
 }
+
+// systemContextConfigFeature is the feature in the configuration that, if
+// enabled, applies the configuration overlays of contexts that are provided
+// by a system context store (see [store.LayeredStore]).
+const systemContextConfigFeature = "system-context-config"
+
+// applyContextConfig merges the configuration overlay of the current context
+// (if any) over the configuration file. The overlays of system contexts are
+// ignored, unless the user opted in to using them. An invalid overlay is
+// ignored with a warning, so that the context can still be fixed or switched.
+func (cli *DockerCli) applyContextConfig() {
+	if cli.CurrentContext() == DefaultContextName {
+		return
+	}
+	if cli.contextStore.GetStorageInfo(cli.CurrentContext()).ReadOnly {
+		if enabled, _ := strconv.ParseBool(cli.configFile.Features[systemContextConfigFeature]); !enabled {
+			return
+		}
+	}
+	meta, err := cli.contextStore.GetMetadata(cli.CurrentContext())
+	if err != nil {
+		// Errors about the context are returned when using it.
+		return
+	}
+	dc, err := GetDockerContext(meta)
+	if err != nil || len(dc.Config) == 0 {
+		return
+	}
+	if err := cli.configFile.ApplyOverlay(dc.Config); err != nil {
+		_, _ = fmt.Fprintf(cli.err, "WARNING: ignoring the configuration overrides of context %q: %v\n", cli.CurrentContext(), err)
+	}
+}
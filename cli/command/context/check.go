@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+)
+
+// checkTimeout is the maximum time to wait for the Docker endpoint of a
+// context to respond when checking contexts.
+const checkTimeout = 5 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnreachable = "unreachable"
+)
+
+// checkContexts checks the Docker endpoints of the given contexts in parallel,
+// and sets the result of the check on each context. Contexts that could not
+// be loaded are not checked.
+func checkContexts(ctx context.Context, s store.Reader, contexts []*formatter.ClientContext) {
+	var wg sync.WaitGroup
+	for _, c := range contexts {
+		if c.Error != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(c *formatter.ClientContext) {
+			defer wg.Done()
+			checkContext(ctx, s, c)
+		}(c)
+	}
+	wg.Wait()
+}
+
+func checkContext(ctx context.Context, s store.Reader, c *formatter.ClientContext) {
+	ep, err := endpoint(s, c.Name)
+	if err != nil {
+		c.Error = err.Error()
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	health := ep.CheckHealth(ctx)
+	if !health.Reachable {
+		c.Status = statusUnreachable
+		c.Error = health.Err.Error()
+		return
+	}
+	c.Status = statusOK
+	c.Latency = formatLatency(health.Latency)
+	c.APIVersion = health.APIVersion
+}
+
+func endpoint(s store.Reader, name string) (docker.Endpoint, error) {
+	meta, err := s.GetMetadata(name)
+	if err != nil {
+		return docker.Endpoint{}, err
+	}
+	epMeta, err := docker.EndpointFromContext(meta)
+	if err != nil {
+		return docker.Endpoint{}, err
+	}
+	return docker.WithTLSData(s, name, epMeta)
+}
+
+// formatLatency formats the latency in milliseconds, or microseconds for
+// local endpoints that respond in less than a millisecond.
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -24,6 +24,8 @@ type CreateOptions struct {
 	Description string
 	Docker      map[string]string
 	From        string
+	Fallback    []string
+	Protected   bool
 
 	// Additional Metadata to store in the context. This option is not
 	// currently exposed to the user.
@@ -60,6 +62,8 @@ func newCreateCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.StringVar(&opts.Description, "description", "", "Description of the context")
 	flags.StringToStringVar(&opts.Docker, "docker", nil, "set the docker endpoint")
 	flags.StringVar(&opts.From, "from", "", "create context from a named context")
+	flags.StringSliceVar(&opts.Fallback, "fallback", nil, "Contexts to use, in order, when this context is unreachable")
+	flags.BoolVar(&opts.Protected, "protected", false, "Require confirmation for destructive commands when using the context")
 	return cmd
 }
 
@@ -70,6 +74,9 @@ func RunCreate(dockerCLI command.Cli, o *CreateOptions) error {
 	if err != nil {
 		return err
 	}
+	if err := validateFallback(s, o.Name, o.Fallback); err != nil {
+		return err
+	}
 	switch {
 	case o.From == "" && o.Docker == nil:
 		err = createFromExistingContext(s, dockerCLI.CurrentContext(), o)
@@ -99,6 +106,8 @@ func createNewContext(contextStore store.ReaderWriter, o *CreateOptions) error {
 		},
 		Metadata: command.DockerContext{
 			Description:      o.Description,
+			Fallback:         o.Fallback,
+			Protected:        o.Protected,
 			AdditionalFields: o.metaData,
 		},
 		Name: o.Name,
@@ -138,6 +147,8 @@ func createFromExistingContext(s store.ReaderWriter, fromContextName string, o *
 	reader := store.Export(fromContextName, &descriptionDecorator{
 		Reader:      s,
 		description: o.Description,
+		fallback:    o.Fallback,
+		protected:   o.Protected,
 	})
 	defer reader.Close()
 	return store.Import(o.Name, s, reader)
@@ -146,6 +157,8 @@ func createFromExistingContext(s store.ReaderWriter, fromContextName string, o *
 type descriptionDecorator struct {
 	store.Reader
 	description string
+	fallback    []string
+	protected   bool
 }
 
 func (d *descriptionDecorator) GetMetadata(name string) (store.Metadata, error) {
@@ -160,6 +173,12 @@ func (d *descriptionDecorator) GetMetadata(name string) (store.Metadata, error)
 	if d.description != "" {
 		typedContext.Description = d.description
 	}
+	if d.fallback != nil {
+		typedContext.Fallback = d.fallback
+	}
+	if d.protected {
+		typedContext.Protected = true
+	}
 	c.Metadata = typedContext
 	return c, nil
 }
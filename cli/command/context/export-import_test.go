@@ -2,6 +2,7 @@
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -91,3 +92,60 @@ func TestExportExistingFile(t *testing.T) {
 	err := RunExport(cli, &ExportOptions{ContextName: "test", Dest: contextFile})
 	assert.Assert(t, os.IsExist(err))
 }
+
+func TestExportImportEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	contextFile := filepath.Join(dir, "exported")
+	passphraseFile := filepath.Join(dir, "passphrase")
+	assert.NilError(t, os.WriteFile(passphraseFile, []byte("secret\n"), 0o600))
+
+	cli := makeFakeCli(t)
+	createTestContext(t, cli, "test", nil)
+	assert.NilError(t, runExport(context.Background(), cli, &ExportOptions{
+		ContextName:    "test",
+		Dest:           contextFile,
+		Encrypt:        true,
+		PassphraseFile: passphraseFile,
+	}))
+
+	err := RunImport(cli, "test2", contextFile)
+	assert.Check(t, is.ErrorContains(err, "cannot prompt for a passphrase"))
+
+	assert.NilError(t, runImport(context.Background(), cli, "test2", contextFile, importOptions{passphraseFile: passphraseFile}))
+	context1, err := cli.ContextStore().GetMetadata("test")
+	assert.NilError(t, err)
+	context2, err := cli.ContextStore().GetMetadata("test2")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(context1.Endpoints, context2.Endpoints))
+	assert.Check(t, is.DeepEqual(context1.Metadata, context2.Metadata))
+}
+
+func TestExportPassphraseFileWithoutEncrypt(t *testing.T) {
+	cli := makeFakeCli(t)
+	err := RunExport(cli, &ExportOptions{ContextName: "test", Dest: "-", PassphraseFile: "passphrase"})
+	assert.Check(t, is.Error(err, "--passphrase-file can only be used with --encrypt"))
+}
+
+func TestImportWithoutConfigOverlay(t *testing.T) {
+	contextFile := filepath.Join(t.TempDir(), "exported")
+	cli := makeFakeCli(t)
+	createTestContext(t, cli, "test", nil)
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{
+		Name:   "test",
+		Config: []string{`credHelpers={"registry.example.com":"evil"}`},
+	}))
+	assert.NilError(t, RunExport(cli, &ExportOptions{
+		ContextName: "test",
+		Dest:        contextFile,
+	}))
+	cli.ErrBuffer().Reset()
+	assert.NilError(t, RunImport(cli, "test2", contextFile))
+
+	c, err := cli.ContextStore().GetMetadata("test2")
+	assert.NilError(t, err)
+	dc, err := command.GetDockerContext(c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(dc.Config, 0))
+	assert.Check(t, is.Equal(dc.Description, "description of test"))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), `Configuration overrides of context "test2" were not imported`))
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -14,27 +15,32 @@
 
 // ExportOptions are the options used for exporting a context
 type ExportOptions struct {
-	ContextName string
-	Dest        string
+	ContextName    string
+	Dest           string
+	Encrypt        bool
+	PassphraseFile string
 }
 
 func newExportCommand(dockerCli command.Cli) *cobra.Command {
-	return &cobra.Command{
+	opts := &ExportOptions{}
+	cmd := &cobra.Command{
 		Use:   "export [OPTIONS] CONTEXT [FILE|-]",
 		Short: "Export a context to a tar archive FILE or a tar stream on STDOUT.",
 		Args:  cli.RequiresRangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := &ExportOptions{
-				ContextName: args[0],
-			}
+			opts.ContextName = args[0]
 			if len(args) == 2 {
 				opts.Dest = args[1]
 			} else {
 				opts.Dest = opts.ContextName + ".dockercontext"
 			}
-			return RunExport(dockerCli, opts)
+			return runExport(cmd.Context(), dockerCli, opts)
 		},
 	}
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.Encrypt, "encrypt", false, "Encrypt the exported context with a passphrase")
+	flags.StringVar(&opts.PassphraseFile, "passphrase-file", "", "Read the passphrase to encrypt the context from a file")
+	return cmd
 }
 
 func writeTo(dockerCli command.Cli, reader io.Reader, dest string) error {
@@ -65,10 +71,26 @@ func writeTo(dockerCli command.Cli, reader io.Reader, dest string) error {
 
 // RunExport exports a Docker context
 func RunExport(dockerCli command.Cli, opts *ExportOptions) error {
+	return runExport(context.Background(), dockerCli, opts)
+}
+
+func runExport(ctx context.Context, dockerCli command.Cli, opts *ExportOptions) error {
 	if err := store.ValidateContextName(opts.ContextName); err != nil && opts.ContextName != command.DefaultContextName {
 		return err
 	}
-	reader := store.Export(opts.ContextName, dockerCli.ContextStore())
+	if opts.PassphraseFile != "" && !opts.Encrypt {
+		return errors.New("--passphrase-file can only be used with --encrypt")
+	}
+	var reader io.ReadCloser
+	if opts.Encrypt {
+		passphrase, err := readPassphrase(ctx, dockerCli, opts.PassphraseFile, true)
+		if err != nil {
+			return err
+		}
+		reader = store.ExportEncrypted(opts.ContextName, dockerCli.ContextStore(), passphrase)
+	} else {
+		reader = store.Export(opts.ContextName, dockerCli.ContextStore())
+	}
 	defer reader.Close()
 	return writeTo(dockerCli, reader, opts.Dest)
 }
@@ -1,6 +1,8 @@
 package context
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,20 +13,31 @@
 	"github.com/spf13/cobra"
 )
 
+type importOptions struct {
+	passphraseFile string
+}
+
 func newImportCommand(dockerCli command.Cli) *cobra.Command {
+	var opts importOptions
 	cmd := &cobra.Command{
-		Use:   "import CONTEXT FILE|-",
+		Use:   "import [OPTIONS] CONTEXT FILE|-",
 		Short: "Import a context from a tar or zip file",
 		Args:  cli.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunImport(dockerCli, args[0], args[1])
+			return runImport(cmd.Context(), dockerCli, args[0], args[1], opts)
 		},
 	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.passphraseFile, "passphrase-file", "", "Read the passphrase to decrypt an encrypted context from a file")
 	return cmd
 }
 
 // RunImport imports a Docker context
 func RunImport(dockerCli command.Cli, name string, source string) error {
+	return runImport(context.Background(), dockerCli, name, source, importOptions{})
+}
+
+func runImport(ctx context.Context, dockerCli command.Cli, name string, source string, opts importOptions) error {
 	if err := checkContextNameForCreation(dockerCli.ContextStore(), name); err != nil {
 		return err
 	}
@@ -41,7 +54,16 @@ func RunImport(dockerCli command.Cli, name string, source string) error {
 		reader = f
 	}
 
-	if err := store.Import(name, dockerCli.ContextStore(), reader); err != nil {
+	passphrase := func() ([]byte, error) {
+		if source == "-" && opts.passphraseFile == "" {
+			return nil, errors.New("context is encrypted: use --passphrase-file to provide the passphrase when importing from STDIN")
+		}
+		return readPassphrase(ctx, dockerCli, opts.passphraseFile, false)
+	}
+	if err := store.ImportWithPassphrase(name, dockerCli.ContextStore(), reader, passphrase); err != nil {
+		return err
+	}
+	if err := removeConfigOverlay(dockerCli, name); err != nil {
 		return err
 	}
 
@@ -49,3 +71,25 @@ func RunImport(dockerCli command.Cli, name string, source string) error {
 	_, _ = fmt.Fprintf(dockerCli.Err(), "Successfully imported context %q\n", name)
 	return nil
 }
+
+// removeConfigOverlay removes the configuration overlay from an imported
+// context, as the context may come from an untrusted source, and the overlay
+// can set properties such as the credential helpers.
+func removeConfigOverlay(dockerCli command.Cli, name string) error {
+	s := dockerCli.ContextStore()
+	c, err := s.GetMetadata(name)
+	if err != nil {
+		return err
+	}
+	dockerContext, err := command.GetDockerContext(c)
+	if err != nil || len(dockerContext.Config) == 0 {
+		return err
+	}
+	dockerContext.Config = nil
+	c.Metadata = dockerContext
+	if err := s.CreateOrUpdate(c); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(dockerCli.Err(), "Configuration overrides of context %q were not imported; use \"docker context update --config\" to set them\n", name)
+	return nil
+}
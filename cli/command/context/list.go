@@ -4,6 +4,8 @@
 package context
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -13,6 +15,7 @@
 	"github.com/docker/cli/cli/command/completion"
 	"github.com/docker/cli/cli/command/formatter"
 	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
 	flagsHelper "github.com/docker/cli/cli/flags"
 	"github.com/docker/docker/client"
 	"github.com/fvbommel/sortorder"
@@ -22,6 +25,7 @@
 type listOptions struct {
 	format string
 	quiet  bool
+	check  bool
 }
 
 func newListCommand(dockerCli command.Cli) *cobra.Command {
@@ -32,7 +36,7 @@ func newListCommand(dockerCli command.Cli) *cobra.Command {
 		Short:   "List contexts",
 		Args:    cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runList(dockerCli, opts)
+			return runList(cmd.Context(), dockerCli, opts)
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
@@ -40,13 +44,17 @@ func newListCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only show context names")
+	flags.BoolVar(&opts.check, "check", false, "Check if the Docker endpoint of each context is reachable")
 	return cmd
 }
 
-func runList(dockerCli command.Cli, opts *listOptions) error {
+func runList(ctx context.Context, dockerCli command.Cli, opts *listOptions) error {
 	if opts.format == "" {
 		opts.format = formatter.TableFormatKey
 	}
+	if opts.check && opts.quiet {
+		return errors.New("--check cannot be combined with --quiet")
+	}
 	contextMap, err := dockerCli.ContextStore().List()
 	if err != nil {
 		return err
@@ -107,6 +115,10 @@ func runList(dockerCli command.Cli, opts *listOptions) error {
 			ContextType: getContextType(nil, opts.format),
 		})
 	}
+	setSources(dockerCli.ContextStore(), contexts)
+	if opts.check {
+		checkContexts(ctx, dockerCli.ContextStore(), contexts)
+	}
 	sort.Slice(contexts, func(i, j int) bool {
 		return sortorder.NaturalLess(contexts[i].Name, contexts[j].Name)
 	})
@@ -149,5 +161,51 @@ func format(dockerCli command.Cli, opts *listOptions, contexts []*formatter.Clie
 		Output: dockerCli.Out(),
 		Format: formatter.NewClientContextFormat(opts.format, opts.quiet),
 	}
+	if opts.format == formatter.TableFormatKey {
+		if opts.check {
+			contextCtx.Format = formatter.ClientContextCheckTableFormat
+		} else if hasSources(contexts) {
+			contextCtx.Format = formatter.ClientContextSourceTableFormat
+		}
+	}
 	return formatter.ClientContextWrite(contextCtx, contexts)
 }
+
+const (
+	sourceUser   = "user"
+	sourceSystem = "system"
+)
+
+// setSources sets where each context is stored if any of the contexts is
+// stored in a read-only store, so that users can tell system contexts from
+// their own.
+func setSources(s store.StorageInfoProvider, contexts []*formatter.ClientContext) {
+	sources := make([]string, len(contexts))
+	var readOnly bool
+	for i, c := range contexts {
+		if c.Name == command.DefaultContextName {
+			continue
+		}
+		if s.GetStorageInfo(c.Name).ReadOnly {
+			sources[i] = sourceSystem
+			readOnly = true
+		} else {
+			sources[i] = sourceUser
+		}
+	}
+	if !readOnly {
+		return
+	}
+	for i, c := range contexts {
+		c.Source = sources[i]
+	}
+}
+
+func hasSources(contexts []*formatter.ClientContext) bool {
+	for _, c := range contexts {
+		if c.Source != "" {
+			return true
+		}
+	}
+	return false
+}
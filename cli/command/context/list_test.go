@@ -1,11 +1,17 @@
 package context
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/context/store"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
 )
 
@@ -34,7 +40,7 @@ func TestList(t *testing.T) {
 	createTestContexts(t, cli, "current", "other", "unset")
 	cli.SetCurrentContext("current")
 	cli.OutBuffer().Reset()
-	assert.NilError(t, runList(cli, &listOptions{}))
+	assert.NilError(t, runList(context.Background(), cli, &listOptions{}))
 	golden.Assert(t, cli.OutBuffer().String(), "list.golden")
 }
 
@@ -48,19 +54,19 @@ func TestListJSON(t *testing.T) {
 
 	t.Run("format={{json .}}", func(t *testing.T) {
 		cli.OutBuffer().Reset()
-		assert.NilError(t, runList(cli, &listOptions{format: formatter.JSONFormat}))
+		assert.NilError(t, runList(context.Background(), cli, &listOptions{format: formatter.JSONFormat}))
 		golden.Assert(t, cli.OutBuffer().String(), "list-json.golden")
 	})
 
 	t.Run("format=json", func(t *testing.T) {
 		cli.OutBuffer().Reset()
-		assert.NilError(t, runList(cli, &listOptions{format: formatter.JSONFormatKey}))
+		assert.NilError(t, runList(context.Background(), cli, &listOptions{format: formatter.JSONFormatKey}))
 		golden.Assert(t, cli.OutBuffer().String(), "list-json.golden")
 	})
 
 	t.Run("format={{ json .Name }}", func(t *testing.T) {
 		cli.OutBuffer().Reset()
-		assert.NilError(t, runList(cli, &listOptions{format: `{{ json .Name }}`}))
+		assert.NilError(t, runList(context.Background(), cli, &listOptions{format: `{{ json .Name }}`}))
 		golden.Assert(t, cli.OutBuffer().String(), "list-json-name.golden")
 	})
 }
@@ -70,7 +76,7 @@ func TestListQuiet(t *testing.T) {
 	createTestContexts(t, cli, "current", "other")
 	cli.SetCurrentContext("current")
 	cli.OutBuffer().Reset()
-	assert.NilError(t, runList(cli, &listOptions{quiet: true}))
+	assert.NilError(t, runList(context.Background(), cli, &listOptions{quiet: true}))
 	golden.Assert(t, cli.OutBuffer().String(), "quiet-list.golden")
 }
 
@@ -78,6 +84,53 @@ func TestListError(t *testing.T) {
 	cli := makeFakeCli(t)
 	cli.SetCurrentContext("nosuchcontext")
 	cli.OutBuffer().Reset()
-	assert.NilError(t, runList(cli, &listOptions{}))
+	assert.NilError(t, runList(context.Background(), cli, &listOptions{}))
 	golden.Assert(t, cli.OutBuffer().String(), "list-with-error.golden")
 }
+
+func TestListCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.45")
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cli := makeFakeCli(t)
+	for name, url := range map[string]string{"reachable": ts.URL, "unreachable": closed.URL} {
+		assert.NilError(t, RunCreate(cli, &CreateOptions{
+			Name:   name,
+			Docker: map[string]string{keyHost: "tcp://" + strings.TrimPrefix(url, "http://")},
+		}))
+	}
+	cli.SetCurrentContext("reachable")
+	cli.OutBuffer().Reset()
+	assert.NilError(t, runList(context.Background(), cli, &listOptions{check: true, format: "{{.Name}} {{.Status}} {{.APIVersion}}"}))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "reachable ok 1.45\n"))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "unreachable unreachable \n"))
+
+	err := runList(context.Background(), cli, &listOptions{check: true, quiet: true})
+	assert.Check(t, is.Error(err, "--check cannot be combined with --quiet"))
+}
+
+func TestListSystemContexts(t *testing.T) {
+	userDir, systemDir := t.TempDir(), t.TempDir()
+	storeConfig := command.DefaultContextStoreConfig()
+	cli := makeFakeCli(t)
+	contextStore := cli.ContextStore().(*command.ContextStoreWithDefault)
+	contextStore.Store = store.New(systemDir, storeConfig)
+	createTestContext(t, cli, "shared", nil)
+	contextStore.Store = store.NewLayered(userDir, []string{systemDir}, storeConfig)
+	createTestContext(t, cli, "current", nil)
+	cli.SetCurrentContext("current")
+
+	cli.OutBuffer().Reset()
+	assert.NilError(t, runList(context.Background(), cli, &listOptions{format: formatter.TableFormatKey}))
+	golden.Assert(t, cli.OutBuffer().String(), "list-system.golden")
+
+	err := RunUpdate(cli, &UpdateOptions{Name: "shared", Description: "updated"})
+	assert.Check(t, is.ErrorContains(err, `context "shared" is read-only`))
+	err = RunRemove(cli, RemoveOptions{}, []string{"shared"})
+	assert.Check(t, is.ErrorContains(err, `context "shared" is read-only`))
+}
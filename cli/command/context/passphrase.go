@@ -0,0 +1,63 @@
+package context
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli/command"
+)
+
+// readPassphrase reads the passphrase to encrypt or decrypt a context from the
+// given file, or prompts the user for it if no file is given. If confirm is
+// set, the user is prompted to enter the passphrase twice.
+func readPassphrase(ctx context.Context, dockerCli command.Cli, file string, confirm bool) ([]byte, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		passphrase := bytes.TrimRight(data, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("passphrase file %s is empty", file)
+		}
+		return passphrase, nil
+	}
+
+	if !dockerCli.In().IsTerminal() {
+		return nil, errors.New("cannot prompt for a passphrase: STDIN is not a terminal, use --passphrase-file")
+	}
+	restoreInput, err := command.DisableInputEcho(dockerCli.In())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := restoreInput(); err != nil {
+			_, _ = fmt.Fprintln(dockerCli.Err(), "Error: failed to restore terminal state to echo input:", err)
+		}
+	}()
+
+	// The exported context may be written to STDOUT, so prompts are
+	// written to STDERR.
+	passphrase, err := command.PromptForInput(ctx, dockerCli.In(), dockerCli.Err(), "Passphrase: ")
+	_, _ = fmt.Fprintln(dockerCli.Err())
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	if confirm {
+		again, err := command.PromptForInput(ctx, dockerCli.In(), dockerCli.Err(), "Confirm passphrase: ")
+		_, _ = fmt.Fprintln(dockerCli.Err())
+		if err != nil {
+			return nil, err
+		}
+		if again != passphrase {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return []byte(passphrase), nil
+}
@@ -3,10 +3,12 @@
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/formatter/tabwriter"
+	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/context/docker"
 	"github.com/docker/cli/cli/context/store"
 	"github.com/pkg/errors"
@@ -18,6 +20,9 @@ type UpdateOptions struct {
 	Name        string
 	Description string
 	Docker      map[string]string
+	Fallback    []string
+	Config      []string
+	Protected   *bool
 }
 
 func longUpdateDescription() string {
@@ -35,12 +40,16 @@ func longUpdateDescription() string {
 
 func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
 	opts := &UpdateOptions{}
+	var protected bool
 	cmd := &cobra.Command{
 		Use:   "update [OPTIONS] CONTEXT",
 		Short: "Update a context",
 		Args:  cli.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Name = args[0]
+			if cmd.Flags().Changed("protected") {
+				opts.Protected = &protected
+			}
 			return RunUpdate(dockerCli, opts)
 		},
 		Long: longUpdateDescription(),
@@ -48,6 +57,9 @@ func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&opts.Description, "description", "", "Description of the context")
 	flags.StringToStringVar(&opts.Docker, "docker", nil, "set the docker endpoint")
+	flags.StringArrayVar(&opts.Config, "config", nil, "Override a property of the configuration file when using the context (key=value)")
+	flags.StringSliceVar(&opts.Fallback, "fallback", nil, `Contexts to use, in order, when this context is unreachable ("" to remove)`)
+	flags.BoolVar(&protected, "protected", false, "Require confirmation for destructive commands when using the context")
 	return cmd
 }
 
@@ -68,6 +80,20 @@ func RunUpdate(dockerCLI command.Cli, o *UpdateOptions) error {
 	if o.Description != "" {
 		dockerContext.Description = o.Description
 	}
+	if o.Fallback != nil {
+		if err := validateFallback(s, o.Name, o.Fallback); err != nil {
+			return err
+		}
+		dockerContext.Fallback = o.Fallback
+	}
+	if len(o.Config) > 0 {
+		if dockerContext.Config, err = updateConfigOverlay(dockerContext.Config, o.Config); err != nil {
+			return err
+		}
+	}
+	if o.Protected != nil {
+		dockerContext.Protected = *o.Protected
+	}
 
 	c.Metadata = dockerContext
 
@@ -102,3 +128,38 @@ func validateEndpoints(c store.Metadata) error {
 	_, err := command.GetDockerContext(c)
 	return err
 }
+
+// updateConfigOverlay sets ("key=value") or removes ("key") properties of the
+// configuration overlay of a context.
+func updateConfigOverlay(overlay map[string]interface{}, values []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(overlay))
+	for k, v := range overlay {
+		result[k] = v
+	}
+	for _, kv := range values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			delete(result, k)
+			continue
+		}
+		val, err := configfile.OverlayValue(k, v)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid --config")
+		}
+		result[k] = val
+	}
+	return result, nil
+}
+
+// validateFallback validates that the fallback contexts of a context exist.
+func validateFallback(s store.Reader, name string, fallback []string) error {
+	for _, f := range fallback {
+		if f == name {
+			return errors.Errorf("context %q cannot be a fallback of itself", name)
+		}
+		if _, err := s.GetMetadata(f); err != nil {
+			return errors.Wrapf(err, "invalid fallback context %q", f)
+		}
+	}
+	return nil
+}
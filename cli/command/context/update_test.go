@@ -65,3 +65,87 @@ func TestUpdateInvalidDockerHost(t *testing.T) {
 	})
 	assert.ErrorContains(t, err, "unable to parse docker host")
 }
+
+func TestUpdateFallback(t *testing.T) {
+	cli := makeFakeCli(t)
+	createTestContexts(t, cli, "test", "backup")
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{
+		Name:     "test",
+		Fallback: []string{"backup", "default"},
+	}))
+	c, err := cli.ContextStore().GetMetadata("test")
+	assert.NilError(t, err)
+	dc, err := command.GetDockerContext(c)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(dc.Fallback, []string{"backup", "default"}))
+	assert.Check(t, is.Equal(dc.Description, "description of test"))
+
+	err = RunUpdate(cli, &UpdateOptions{Name: "test", Fallback: []string{"test"}})
+	assert.Check(t, is.Error(err, `context "test" cannot be a fallback of itself`))
+	err = RunUpdate(cli, &UpdateOptions{Name: "test", Fallback: []string{"missing"}})
+	assert.Check(t, is.ErrorContains(err, `invalid fallback context "missing"`))
+
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{Name: "test", Fallback: []string{}}))
+	c, err = cli.ContextStore().GetMetadata("test")
+	assert.NilError(t, err)
+	dc, err = command.GetDockerContext(c)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(dc.Fallback, 0))
+}
+
+func TestUpdateConfig(t *testing.T) {
+	cli := makeFakeCli(t)
+	createTestContext(t, cli, "test", nil)
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{
+		Name: "test",
+		Config: []string{
+			"psFormat=table {{.ID}}\t{{.Names}}",
+			`credHelpers={"registry.example.com":"vault"}`,
+			"detachKeys=ctrl-x,x",
+		},
+	}))
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{
+		Name:   "test",
+		Config: []string{"detachKeys"},
+	}))
+	c, err := cli.ContextStore().GetMetadata("test")
+	assert.NilError(t, err)
+	dc, err := command.GetDockerContext(c)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(dc.Config, map[string]any{
+		"psFormat":    "table {{.ID}}\t{{.Names}}",
+		"credHelpers": map[string]any{"registry.example.com": "vault"},
+	}))
+
+	err = RunUpdate(cli, &UpdateOptions{Name: "test", Config: []string{"auths={}"}})
+	assert.Check(t, is.Error(err, `invalid --config: configuration property "auths" cannot be overridden`))
+
+	err = RunUpdate(cli, &UpdateOptions{Name: "test", Config: []string{"cliPluginsExtraDirs=[]"}})
+	assert.Check(t, is.Error(err, `invalid --config: configuration property "cliPluginsExtraDirs" cannot be overridden`))
+}
+
+func TestUpdateProtected(t *testing.T) {
+	cli := makeFakeCli(t)
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name:      "test",
+		Docker:    map[string]string{},
+		Protected: true,
+	}))
+
+	isProtected := func() bool {
+		t.Helper()
+		c, err := cli.ContextStore().GetMetadata("test")
+		assert.NilError(t, err)
+		dc, err := command.GetDockerContext(c)
+		assert.NilError(t, err)
+		return dc.Protected
+	}
+	assert.Check(t, isProtected())
+
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{Name: "test", Description: "description"}))
+	assert.Check(t, isProtected())
+
+	unprotected := false
+	assert.NilError(t, RunUpdate(cli, &UpdateOptions{Name: "test", Protected: &unprotected}))
+	assert.Check(t, !isProtected())
+}
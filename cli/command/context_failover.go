@@ -0,0 +1,177 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/atomicwriter"
+)
+
+const (
+	// failoverFile is the file in the context store directory in which the
+	// fallback contexts that are used for unreachable contexts are recorded.
+	failoverFile = "failover.json"
+
+	// failoverTTL is how long the fallback context of an unreachable context
+	// is used, before checking whether the context is reachable again.
+	failoverTTL = time.Minute
+)
+
+// failoverRecord records the fallback context that is used for an
+// unreachable context.
+type failoverRecord struct {
+	Fallback string
+	Until    time.Time
+}
+
+// EffectiveContext returns the name of the context whose Docker endpoint is
+// used by dockerCli. This is the current context, unless the current context
+// is unreachable, and one of its fallback contexts is used instead.
+func EffectiveContext(dockerCli Cli) string {
+	if c, ok := dockerCli.(interface{ EffectiveContext() string }); ok {
+		return c.EffectiveContext()
+	}
+	return dockerCli.CurrentContext()
+}
+
+// EffectiveContext returns the name of the context whose Docker endpoint is
+// used. This is the current context, unless the current context is
+// unreachable, and one of its fallback contexts is used instead.
+func (cli *DockerCli) EffectiveContext() string {
+	_ = cli.initialize()
+	if cli.effectiveContext != "" {
+		return cli.effectiveContext
+	}
+	return cli.CurrentContext()
+}
+
+// failover returns the Docker endpoint to use for the current context, and the
+// name of the context it belongs to. If the current context has fallback
+// contexts, and its endpoint is unreachable, the endpoint of the first
+// reachable fallback context is used instead, and a warning is printed.
+// Fallback contexts of fallback contexts are not used.
+//
+// The fallback context is used without checking the current context again
+// for failoverTTL, so that commands don't wait for an unreachable endpoint
+// each time. If the endpoint was checked, the response to the ping is
+// returned, so that the daemon doesn't have to be pinged again.
+//
+// The endpoint of the current context is returned if it has no fallback
+// contexts, or none of them are reachable.
+func (cli *DockerCli) failover(ep docker.Endpoint) (docker.Endpoint, string, *types.Ping) {
+	name := cli.CurrentContext()
+	fallback := cli.fallbackContexts()
+	if len(fallback) == 0 {
+		return ep, name, nil
+	}
+
+	records := readFailoverRecords()
+	if r, ok := records[name]; ok && time.Now().Before(r.Until) && isFallback(fallback, r.Fallback) {
+		if fallbackEP, err := cli.resolveContextEndpoint(r.Fallback); err == nil {
+			_, _ = fmt.Fprintf(cli.Err(), "Warning: context %q is unreachable\nUsing fallback context %q\n", name, r.Fallback)
+			return fallbackEP, r.Fallback, nil
+		}
+	}
+
+	health := cli.checkEndpoint(ep)
+	if health.Reachable {
+		if _, ok := records[name]; ok {
+			delete(records, name)
+			writeFailoverRecords(records)
+		}
+		return ep, name, &health.Ping
+	}
+	cause := health.Err
+	for _, fb := range fallback {
+		fallbackEP, err := cli.resolveContextEndpoint(fb)
+		if err != nil {
+			_, _ = fmt.Fprintf(cli.Err(), "Warning: unable to resolve fallback context %q: %v\n", fb, err)
+			continue
+		}
+		h := cli.checkEndpoint(fallbackEP)
+		if !h.Reachable {
+			_, _ = fmt.Fprintf(cli.Err(), "Warning: fallback context %q is unreachable: %v\n", fb, h.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(cli.Err(), "Warning: context %q is unreachable: %v\nUsing fallback context %q\n", name, cause, fb)
+		records[name] = failoverRecord{Fallback: fb, Until: time.Now().Add(failoverTTL)}
+		writeFailoverRecords(records)
+		return fallbackEP, fb, &h.Ping
+	}
+	return ep, name, nil
+}
+
+// fallbackContexts returns the fallback contexts of the current context.
+func (cli *DockerCli) fallbackContexts() []string {
+	if cli.contextStore == nil || cli.CurrentContext() == DefaultContextName {
+		return nil
+	}
+	meta, err := cli.contextStore.GetMetadata(cli.CurrentContext())
+	if err != nil {
+		return nil
+	}
+	dc, err := GetDockerContext(meta)
+	if err != nil {
+		return nil
+	}
+	return dc.Fallback
+}
+
+func (cli *DockerCli) resolveContextEndpoint(name string) (docker.Endpoint, error) {
+	if name == DefaultContextName {
+		return resolveDefaultDockerEndpoint(cli.options)
+	}
+	return resolveDockerEndpoint(cli.contextStore, name)
+}
+
+func (cli *DockerCli) checkEndpoint(ep docker.Endpoint) docker.Health {
+	ctx, cancel := context.WithTimeout(cli.baseCtx, cli.getInitTimeout())
+	defer cancel()
+	return ep.CheckHealth(ctx)
+}
+
+func isFallback(fallback []string, name string) bool {
+	for _, fb := range fallback {
+		if fb == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readFailoverRecords reads the fallback contexts that are used for
+// unreachable contexts, by context name. Errors are ignored, as the records
+// are only used to skip checking unreachable contexts.
+func readFailoverRecords() map[string]failoverRecord {
+	records := make(map[string]failoverRecord)
+	data, err := os.ReadFile(filepath.Join(config.ContextStoreDir(), failoverFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &records)
+	}
+	return records
+}
+
+func writeFailoverRecords(records map[string]failoverRecord) {
+	now := time.Now()
+	for name, r := range records {
+		if now.After(r.Until) {
+			delete(records, name)
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	dir := config.ContextStoreDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	_ = atomicwriter.WriteFile(filepath.Join(dir, failoverFile), data, 0o644)
+}
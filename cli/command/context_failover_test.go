@@ -0,0 +1,82 @@
+package command
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/flags"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestFailover(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.45")
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+	reachable := "tcp://" + strings.TrimPrefix(ts.URL, "http://")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	unreachable := "tcp://" + strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	config.SetDir(t.TempDir())
+	s := store.New(config.ContextStoreDir(), DefaultContextStoreConfig())
+	for _, c := range []struct {
+		name     string
+		host     string
+		fallback []string
+	}{
+		{name: "flaky", host: unreachable, fallback: []string{"missing", "also-flaky", "backup"}},
+		{name: "also-flaky", host: unreachable},
+		{name: "backup", host: reachable},
+		{name: "no-fallback", host: unreachable},
+	} {
+		assert.NilError(t, s.CreateOrUpdate(store.Metadata{
+			Name:      c.name,
+			Metadata:  DockerContext{Fallback: c.fallback},
+			Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: c.host}},
+		}))
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		var errBuf bytes.Buffer
+		cli, err := NewDockerCli(WithErrorStream(&errBuf))
+		assert.NilError(t, err)
+		assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "flaky"}))
+		assert.Check(t, is.Equal(cli.DockerEndpoint().Host, reachable))
+		assert.Check(t, is.Equal(cli.CurrentContext(), "flaky"))
+		assert.Check(t, is.Equal(cli.EffectiveContext(), "backup"))
+		assert.Check(t, is.Contains(errBuf.String(), `Warning: unable to resolve fallback context "missing"`))
+		assert.Check(t, is.Contains(errBuf.String(), `Warning: fallback context "also-flaky" is unreachable`))
+		assert.Check(t, is.Contains(errBuf.String(), `Warning: context "flaky" is unreachable`))
+		assert.Check(t, is.Contains(errBuf.String(), `Using fallback context "backup"`))
+	})
+
+	t.Run("cached fallback", func(t *testing.T) {
+		var errBuf bytes.Buffer
+		cli, err := NewDockerCli(WithErrorStream(&errBuf))
+		assert.NilError(t, err)
+		assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "flaky"}))
+		assert.Check(t, is.Equal(cli.DockerEndpoint().Host, reachable))
+		assert.Check(t, is.Equal(cli.EffectiveContext(), "backup"))
+		assert.Check(t, is.Equal(errBuf.String(), "Warning: context \"flaky\" is unreachable\nUsing fallback context \"backup\"\n"))
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		var errBuf bytes.Buffer
+		cli, err := NewDockerCli(WithErrorStream(&errBuf))
+		assert.NilError(t, err)
+		assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "no-fallback"}))
+		assert.Check(t, is.Equal(cli.DockerEndpoint().Host, unreachable))
+		assert.Check(t, is.Equal(cli.EffectiveContext(), "no-fallback"))
+		assert.Check(t, is.Equal(errBuf.String(), ""))
+	})
+}
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+// ConfirmContextEnvVar is the name of the environment variable that must be
+// set to the name of a protected context to run destructive commands without
+// confirmation.
+const ConfirmContextEnvVar = "DOCKER_CONFIRM_CONTEXT"
+
+// ConfirmProtectedContext requests confirmation from the user before running a
+// destructive command when the current context is protected. The user must
+// type the name of the context to confirm. It returns true if the current
+// context is protected and the command was confirmed, in which case callers
+// should not prompt for confirmation again.
+//
+// The prompt is skipped if force is set, and the ConfirmContextEnvVar
+// environment variable is set to the name of the current context. Commands
+// that have no "--force" option pass true for force, so that only the
+// environment variable is required.
+//
+// An errdefs.ErrCancelled error is returned if the user does not confirm.
+func ConfirmProtectedContext(ctx context.Context, dockerCli Cli, force bool) (bool, error) {
+	name := EffectiveContext(dockerCli)
+	if !isProtectedContext(dockerCli, name) {
+		return false, nil
+	}
+	if force && os.Getenv(ConfirmContextEnvVar) == name {
+		return true, nil
+	}
+
+	message := fmt.Sprintf("WARNING! The current context %q is protected.\n", name)
+	if host := dockerCli.DockerEndpoint().Host; host != "" {
+		message += fmt.Sprintf("This command will run against %s.\n", host)
+	}
+	message += fmt.Sprintf("Type the name of the context (%s) to continue: ", name)
+
+	answer, err := PromptForInput(ctx, dockerCli.In(), dockerCli.Out(), message)
+	if err != nil {
+		return false, err
+	}
+	if answer != name {
+		return false, errdefs.Cancelled(errors.New("context name does not match: command has been cancelled"))
+	}
+	return true, nil
+}
+
+func isProtectedContext(dockerCli Cli, name string) bool {
+	if name == "" || name == DefaultContextName || dockerCli.ContextStore() == nil {
+		return false
+	}
+	meta, err := dockerCli.ContextStore().GetMetadata(name)
+	if err != nil {
+		return false
+	}
+	dc, err := GetDockerContext(meta)
+	if err != nil {
+		return false
+	}
+	return dc.Protected
+}
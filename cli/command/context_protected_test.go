@@ -0,0 +1,87 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/flags"
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestConfirmProtectedContext(t *testing.T) {
+	config.SetDir(t.TempDir())
+	s := store.New(config.ContextStoreDir(), DefaultContextStoreConfig())
+	for _, c := range []struct {
+		name      string
+		protected bool
+	}{
+		{name: "prod", protected: true},
+		{name: "dev"},
+	} {
+		assert.NilError(t, s.CreateOrUpdate(store.Metadata{
+			Name:      c.name,
+			Metadata:  DockerContext{Protected: c.protected},
+			Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: "tcp://" + c.name + ".example.com:2376"}},
+		}))
+	}
+
+	newCli := func(t *testing.T, contextName, input string) (*DockerCli, *bytes.Buffer) {
+		t.Helper()
+		var out bytes.Buffer
+		cli, err := NewDockerCli(
+			WithInputStream(io.NopCloser(strings.NewReader(input))),
+			WithOutputStream(&out),
+		)
+		assert.NilError(t, err)
+		assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: contextName}))
+		return cli, &out
+	}
+
+	tests := []struct {
+		doc       string
+		context   string
+		input     string
+		force     bool
+		env       string
+		confirmed bool
+		prompted  bool
+		cancelled bool
+	}{
+		{doc: "not protected", context: "dev"},
+		{doc: "not protected with force", context: "dev", force: true},
+		{doc: "confirmed", context: "prod", input: "prod\n", confirmed: true, prompted: true},
+		{doc: "wrong name", context: "prod", input: "dev\n", prompted: true, cancelled: true},
+		{doc: "yes is not enough", context: "prod", input: "y\n", prompted: true, cancelled: true},
+		{doc: "force without env", context: "prod", input: "\n", force: true, prompted: true, cancelled: true},
+		{doc: "env without force", context: "prod", input: "\n", env: "prod", prompted: true, cancelled: true},
+		{doc: "env for other context", context: "prod", input: "\n", force: true, env: "dev", prompted: true, cancelled: true},
+		{doc: "force and env", context: "prod", force: true, env: "prod", confirmed: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			t.Setenv(ConfirmContextEnvVar, tc.env)
+			cli, out := newCli(t, tc.context, tc.input)
+			confirmed, err := ConfirmProtectedContext(context.Background(), cli, tc.force)
+			if tc.cancelled {
+				assert.Check(t, errdefs.IsCancelled(err))
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Check(t, is.Equal(confirmed, tc.confirmed))
+			if tc.prompted {
+				assert.Check(t, is.Contains(out.String(), `The current context "prod" is protected`))
+				assert.Check(t, is.Contains(out.String(), "tcp://prod.example.com:2376"))
+			} else {
+				assert.Check(t, is.Equal(out.String(), ""))
+			}
+		})
+	}
+}
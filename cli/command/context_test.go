@@ -4,10 +4,18 @@
 package command
 
 import (
+	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/flags"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestDockerContextMetadataKeepAdditionalFields(t *testing.T) {
@@ -27,3 +35,94 @@ func TestDockerContextMetadataKeepAdditionalFields(t *testing.T) {
 	assert.Equal(t, c2.AdditionalFields["foo"], "bar")
 	assert.Equal(t, c2.Description, "test")
 }
+
+func TestDockerContextMetadataFallback(t *testing.T) {
+	c := DockerContext{
+		Description: "test",
+		Fallback:    []string{"one", "two"},
+	}
+	jsonBytes, err := json.Marshal(c)
+	assert.NilError(t, err)
+	const expected = `{"Description":"test","Fallback":["one","two"]}`
+	assert.Equal(t, string(jsonBytes), expected)
+
+	var c2 DockerContext
+	assert.NilError(t, json.Unmarshal(jsonBytes, &c2))
+	assert.DeepEqual(t, c2.Fallback, []string{"one", "two"})
+	assert.Check(t, c2.AdditionalFields == nil)
+
+	var c3 DockerContext
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"Fallback":"one"}`), &c3), "context fallback must be a list of context names")
+}
+
+func TestApplyContextConfig(t *testing.T) {
+	dir := t.TempDir()
+	config.SetDir(dir)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"psFormat": "{{.ID}}"}`), 0o600))
+	s := store.New(config.ContextStoreDir(), DefaultContextStoreConfig())
+	assert.NilError(t, s.CreateOrUpdate(store.Metadata{
+		Name: "prod",
+		Metadata: DockerContext{
+			Config: map[string]any{"psFormat": "{{.Names}}", "detachKeys": "ctrl-x,x"},
+		},
+		Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: "tcp://prod.example.com:2376"}},
+	}))
+
+	cli, err := NewDockerCli()
+	assert.NilError(t, err)
+	assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "prod"}))
+	assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, "{{.Names}}"))
+	assert.Check(t, is.Equal(cli.ConfigFile().DetachKeys, "ctrl-x,x"))
+
+	cli, err = NewDockerCli()
+	assert.NilError(t, err)
+	assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: DefaultContextName}))
+	assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, "{{.ID}}"))
+}
+
+func TestApplyInvalidContextConfig(t *testing.T) {
+	dir := t.TempDir()
+	config.SetDir(dir)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"psFormat": "{{.ID}}"}`), 0o600))
+	s := store.New(config.ContextStoreDir(), DefaultContextStoreConfig())
+	assert.NilError(t, s.CreateOrUpdate(store.Metadata{
+		Name: "prod",
+		Metadata: DockerContext{
+			Config: map[string]any{"psFormat": "{{.Names}}", "auths": map[string]any{}},
+		},
+		Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: "tcp://prod.example.com:2376"}},
+	}))
+
+	var stderr bytes.Buffer
+	cli, err := NewDockerCli(WithErrorStream(&stderr))
+	assert.NilError(t, err)
+	assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "prod"}))
+	assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, "{{.ID}}"))
+	assert.Check(t, is.Contains(stderr.String(), `WARNING: ignoring the configuration overrides of context "prod": configuration property "auths" cannot be overridden`))
+}
+
+func TestApplySystemContextConfig(t *testing.T) {
+	dir, systemDir := t.TempDir(), t.TempDir()
+	config.SetDir(dir)
+	t.Setenv(config.EnvOverrideSystemContextDirs, systemDir)
+	s := store.New(systemDir, DefaultContextStoreConfig())
+	assert.NilError(t, s.CreateOrUpdate(store.Metadata{
+		Name: "prod",
+		Metadata: DockerContext{
+			Config: map[string]any{"psFormat": "{{.Names}}"},
+		},
+		Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: "tcp://prod.example.com:2376"}},
+	}))
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"psFormat": "{{.ID}}"}`), 0o600))
+	cli, err := NewDockerCli()
+	assert.NilError(t, err)
+	assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "prod"}))
+	assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, "{{.ID}}"))
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"psFormat": "{{.ID}}", "features": {"system-context-config": "true"}}`), 0o600))
+	cli, err = NewDockerCli()
+	assert.NilError(t, err)
+	assert.NilError(t, cli.Initialize(&flags.ClientOptions{Context: "prod"}))
+	assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, "{{.Names}}"))
+}
@@ -6,7 +6,19 @@
 	// ClientContextTableFormat is the default client context format.
 	ClientContextTableFormat = "table {{.Name}}{{if .Current}} *{{end}}\t{{.Description}}\t{{.DockerEndpoint}}\t{{.Error}}"
 
+	// ClientContextCheckTableFormat is the default client context format
+	// when checking the endpoints of contexts.
+	ClientContextCheckTableFormat = "table {{.Name}}{{if .Current}} *{{end}}\t{{.DockerEndpoint}}\t{{.Status}}\t{{.Latency}}\t{{.APIVersion}}\t{{.Error}}"
+
+	// ClientContextSourceTableFormat is the default client context format
+	// when there are read-only contexts that are shared by all users of the
+	// system.
+	ClientContextSourceTableFormat = "table {{.Name}}{{if .Current}} *{{end}}\t{{.Description}}\t{{.DockerEndpoint}}\t{{.Source}}\t{{.Error}}"
+
 	dockerEndpointHeader = "DOCKER ENDPOINT"
+	latencyHeader        = "LATENCY"
+	apiVersionHeader     = "API VERSION"
+	sourceHeader         = "SOURCE"
 	quietContextFormat   = "{{.Name}}"
 
 	maxErrLength = 45
@@ -31,6 +43,18 @@ type ClientContext struct {
 	Current        bool
 	Error          string
 
+	// Status, Latency, and APIVersion are the result of checking the
+	// Docker endpoint of the context, and only set when checking contexts.
+	Status     string `json:",omitempty"`
+	Latency    string `json:",omitempty"`
+	APIVersion string `json:",omitempty"`
+
+	// Source is where the context is stored; "user" for contexts in the
+	// context store of the user, and "system" for read-only contexts that
+	// are shared by all users of the system. It is only set if there are
+	// read-only contexts.
+	Source string `json:",omitempty"`
+
 	// ContextType is a temporary field for compatibility with
 	// Visual Studio, which depends on this from the "cloud integration"
 	// wrapper.
@@ -64,6 +88,10 @@ func newClientContextContext() *clientContextContext {
 		"Description":    DescriptionHeader,
 		"DockerEndpoint": dockerEndpointHeader,
 		"Error":          ErrorHeader,
+		"Status":         StatusHeader,
+		"Latency":        latencyHeader,
+		"APIVersion":     apiVersionHeader,
+		"Source":         sourceHeader,
 	}
 	return &ctx
 }
@@ -76,7 +104,20 @@ func (c *clientContextContext) MarshalJSON() ([]byte, error) {
 		return json.Marshal(c.c)
 	}
 	// FIXME(thaJeztah): why do we need a special marshal function here?
-	return MarshalJSON(c)
+	m, err := marshalMap(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.c.Status == "" {
+		// Only include the results of checking the endpoint if it was checked.
+		delete(m, "Status")
+		delete(m, "Latency")
+		delete(m, "APIVersion")
+	}
+	if c.c.Source == "" {
+		delete(m, "Source")
+	}
+	return json.Marshal(m)
 }
 
 func (c *clientContextContext) Current() bool {
@@ -100,3 +141,23 @@ func (c *clientContextContext) Error() string {
 	// TODO(thaJeztah) add "--no-trunc" option to context ls and set default to 30 cols to match "docker service ps"
 	return Ellipsis(c.c.Error, maxErrLength)
 }
+
+// Status returns the status of the Docker endpoint, if it was checked.
+func (c *clientContextContext) Status() string {
+	return c.c.Status
+}
+
+// Latency returns the latency of the Docker endpoint, if it was checked.
+func (c *clientContextContext) Latency() string {
+	return c.c.Latency
+}
+
+// APIVersion returns the API version of the Docker endpoint, if it was checked.
+func (c *clientContextContext) APIVersion() string {
+	return c.c.APIVersion
+}
+
+// Source returns where the context is stored, if there are read-only contexts.
+func (c *clientContextContext) Source() string {
+	return c.c.Source
+}
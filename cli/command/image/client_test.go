@@ -28,6 +28,14 @@ type fakeClient struct {
 	imageImportFunc  func(source image.ImportSource, ref string, options image.ImportOptions) (io.ReadCloser, error)
 	imageHistoryFunc func(img string, options ...client.ImageHistoryOption) ([]image.HistoryResponseItem, error)
 	imageBuildFunc   func(context.Context, io.Reader, types.ImageBuildOptions) (types.ImageBuildResponse, error)
+	diskUsageFunc    func(types.DiskUsageOptions) (types.DiskUsage, error)
+}
+
+func (cli *fakeClient) DiskUsage(_ context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	if cli.diskUsageFunc != nil {
+		return cli.diskUsageFunc(options)
+	}
+	return types.DiskUsage{}, nil
 }
 
 func (cli *fakeClient) ImageTag(_ context.Context, img, ref string) error {
@@ -26,6 +26,7 @@ type imagesOptions struct {
 	filter      opts.FilterOpt
 	calledAs    string
 	tree        bool
+	layers      bool
 }
 
 // NewImagesCommand creates a new `docker images` command
@@ -65,6 +66,9 @@ func NewImagesCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.SetAnnotation("tree", "version", []string{"1.47"})
 	flags.SetAnnotation("tree", "experimentalCLI", nil)
 
+	flags.BoolVar(&options.layers, "layers", false, "Show the layers of each image, and the size that removing it would free (requires --tree) (EXPERIMENTAL)")
+	flags.SetAnnotation("layers", "experimentalCLI", nil)
+
 	return cmd
 }
 
@@ -91,15 +95,20 @@ func runImages(ctx context.Context, dockerCLI command.Cli, options imagesOptions
 		if options.showDigests {
 			return errors.New("--show-digest is not yet supported with --tree")
 		}
-		if options.format != "" {
-			return errors.New("--format is not yet supported with --tree")
+		if options.format != "" && options.format != formatter.JSONFormatKey {
+			return errors.New("only --format=json is supported with --tree")
 		}
 
 		return runTree(ctx, dockerCLI, treeOptions{
 			all:     options.all,
 			filters: filters,
+			layers:  options.layers,
+			format:  options.format,
 		})
 	}
+	if options.layers {
+		return errors.New("--layers is only supported with --tree")
+	}
 
 	images, err := dockerCLI.Client().ImageList(ctx, image.ListOptions{
 		All:     options.all,
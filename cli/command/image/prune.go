@@ -9,6 +9,7 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/errdefs"
 	units "github.com/docker/go-units"
@@ -20,6 +21,7 @@ type pruneOptions struct {
 	force  bool
 	all    bool
 	filter opts.FilterOpt
+	dryRun prune.DryRunOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for images
@@ -31,6 +33,17 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove unused images",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.dryRun.Validate(); err != nil {
+				return err
+			}
+			if options.dryRun.Enabled {
+				return runPruneDryRun(cmd.Context(), dockerCli, options)
+			}
+			confirmed, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, options.force)
+			if err != nil {
+				return err
+			}
+			options.force = options.force || confirmed
 			spaceReclaimed, output, err := runPrune(cmd.Context(), dockerCli, options)
 			if err != nil {
 				return err
@@ -49,6 +62,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVarP(&options.force, "force", "f", false, "Do not prompt for confirmation")
 	flags.BoolVarP(&options.all, "all", "a", false, "Remove all unused images, not just dangling ones")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "until=<timestamp>")`)
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -105,6 +119,21 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return spaceReclaimed, output, nil
 }
 
+// runPruneDryRun lists the images that would be removed, without removing
+// them.
+func runPruneDryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	pruneFilters := options.filter.Value().Clone()
+	pruneFilters.Add("dangling", strconv.FormatBool(!options.all))
+	pruneFilters = command.PruneFilters(dockerCli, pruneFilters)
+	candidates, err := prune.Plan(ctx, dockerCli.Client(), prune.Policy{
+		Images: &prune.Rule{Filters: pruneFilters},
+	})
+	if err != nil {
+		return err
+	}
+	return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+}
+
 // RunPrune calls the Image Prune API
 // This returns the amount of space reclaimed and a detailed output string
 func RunPrune(ctx context.Context, dockerCli command.Cli, all bool, filter opts.FilterOpt) (uint64, string, error) {
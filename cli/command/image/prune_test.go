@@ -10,6 +10,8 @@
 
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"gotest.tools/v3/assert"
@@ -127,3 +129,37 @@ func TestPrunePromptTermination(t *testing.T) {
 	cmd.SetErr(io.Discard)
 	test.TerminatePrompt(ctx, t, cmd, cli)
 }
+
+func TestPruneDryRun(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		diskUsageFunc: func(types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				Containers: []*container.Summary{{ID: "c1", State: "exited", ImageID: "sha256:used"}},
+				Images: []*image.Summary{
+					{ID: "sha256:dangling", Size: 1024},
+					{ID: "sha256:tagged", RepoTags: []string{"app:latest"}, Size: 2048},
+					{ID: "sha256:used", Size: 4096},
+				},
+			}, nil
+		},
+		imagesPruneFunc: func(filters.Args) (image.PruneReport, error) {
+			return image.PruneReport{}, errors.New("fakeClient imagesPruneFunc should not be called")
+		},
+	})
+	cmd := NewPruneCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--dry-run", "--all", "--format", "json"})
+	assert.NilError(t, cmd.Execute())
+
+	expected := `{"ID":"sha256:dangling","Name":"","Size":"1.024kB","Type":"image"}
+{"ID":"sha256:tagged","Name":"app:latest","Size":"2.048kB","Type":"image"}
+`
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+
+	cmd = NewPruneCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--format", "json"})
+	assert.Check(t, is.Error(cmd.Execute(), "--format is only supported with --dry-run"))
+}
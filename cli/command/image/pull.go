@@ -70,7 +70,7 @@ func RunPull(ctx context.Context, dockerCLI command.Cli, opts PullOptions) error
 		}
 	}
 
-	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, AuthResolver(dockerCLI), distributionRef.String())
+	imgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, RepositoryAuthResolver(dockerCLI), distributionRef.String())
 	if err != nil {
 		return err
 	}
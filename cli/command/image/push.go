@@ -113,7 +113,7 @@ func RunPush(ctx context.Context, dockerCli command.Cli, opts pushOptions) error
 	}
 
 	// Resolve the Auth config relevant for this server
-	authConfig := command.ResolveAuthConfig(dockerCli.ConfigFile(), repoInfo.Index)
+	authConfig := command.ResolveRepositoryAuthConfig(ctx, dockerCli.ConfigFile(), repoInfo)
 	encodedAuth, err := registrytypes.EncodeAuthConfig(authConfig)
 	if err != nil {
 		return err
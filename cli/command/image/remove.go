@@ -27,6 +27,9 @@ func NewRemoveCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove one or more images",
 		Args:  cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runRemove(cmd.Context(), dockerCli, opts, args)
 		},
 		ValidArgsFunction: completion.ImageNames(dockerCli, -1),
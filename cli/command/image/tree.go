@@ -2,24 +2,31 @@
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/containerd/platforms"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
 	"github.com/docker/cli/internal/tui"
 	"github.com/docker/docker/api/types/filters"
 	imagetypes "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/go-units"
 	"github.com/morikuni/aec"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type treeOptions struct {
 	all     bool
 	filters filters.Args
+	layers  bool
+	format  string
 }
 
 type treeView struct {
@@ -27,6 +34,9 @@ type treeView struct {
 
 	// imageSpacing indicates whether there should be extra spacing between images.
 	imageSpacing bool
+
+	// layers indicates whether each image was expanded into its layers.
+	layers bool
 }
 
 func runTree(ctx context.Context, dockerCLI command.Cli, opts treeOptions) error {
@@ -63,6 +73,7 @@ func runTree(ctx context.Context, dockerCLI command.Cli, opts treeOptions) error
 			}
 
 			sub := subImage{
+				platform:  im.ImageData.Platform,
 				Platform:  platforms.Format(im.ImageData.Platform),
 				Available: im.Available,
 				Details: imageDetails{
@@ -99,6 +110,18 @@ func runTree(ctx context.Context, dockerCLI command.Cli, opts treeOptions) error
 		return view.images[i].created > view.images[j].created
 	})
 
+	if opts.layers {
+		if err := loadLayers(ctx, dockerCLI.Client(), view.images); err != nil {
+			return err
+		}
+		attributeLayers(view.images)
+		view.layers = true
+		view.imageSpacing = true
+	}
+
+	if opts.format == formatter.JSONFormatKey {
+		return printImageTreeJSON(dockerCLI, view)
+	}
 	return printImageTree(dockerCLI, view)
 }
 
@@ -106,7 +129,15 @@ type imageDetails struct {
 	ID          string
 	DiskUsage   string
 	InUse       bool
-	ContentSize string
+	ContentSize string `json:",omitempty"`
+
+	// UniqueSize is the size of the layers that are not shared with any
+	// other image, and would be freed if the image was removed. It is only
+	// set for top-level images when listing layers.
+	UniqueSize string `json:",omitempty"`
+
+	// Shared indicates whether a layer is shared with other images.
+	Shared bool `json:",omitempty"`
 }
 
 type topImage struct {
@@ -114,6 +145,10 @@ type topImage struct {
 	Details  imageDetails
 	Children []subImage
 
+	// Layers is only set for images that don't have any platform variants
+	// (for example, when using the graphdriver image store).
+	Layers []imageLayer `json:",omitempty"`
+
 	created int64
 }
 
@@ -121,6 +156,180 @@ type subImage struct {
 	Platform  string
 	Available bool
 	Details   imageDetails
+	Layers    []imageLayer `json:",omitempty"`
+
+	// LayersError is set if the layers of the platform variant could not
+	// be retrieved.
+	LayersError string `json:",omitempty"`
+
+	platform ocispec.Platform
+}
+
+type imageLayer struct {
+	CreatedBy string
+	Details   imageDetails
+
+	size int64
+}
+
+// loadLayers expands each platform variant of the given images into its
+// layers, based on the image history and the layers of its root filesystem.
+func loadLayers(ctx context.Context, apiClient client.ImageAPIClient, images []topImage) error {
+	for i := range images {
+		img := &images[i]
+		if len(img.Children) == 0 {
+			layers, err := imageLayers(ctx, apiClient, img.Details.ID)
+			if err != nil {
+				return err
+			}
+			img.Layers = layers
+			continue
+		}
+		for j := range img.Children {
+			sub := &img.Children[j]
+			if !sub.Available {
+				// The history of an image that's not fully available
+				// locally can't be retrieved.
+				continue
+			}
+			layers, err := imageLayers(ctx, apiClient, sub.Details.ID, client.ImageHistoryWithPlatform(sub.platform))
+			if err != nil {
+				if errdefs.IsNotFound(err) {
+					// The daemon may not be able to inspect a platform
+					// variant by the digest of its manifest.
+					sub.LayersError = "layers not available: " + err.Error()
+					continue
+				}
+				return err
+			}
+			sub.Layers = layers
+		}
+	}
+	return nil
+}
+
+func imageLayers(ctx context.Context, apiClient client.ImageAPIClient, imageID string, opts ...client.ImageHistoryOption) ([]imageLayer, error) {
+	inspect, err := apiClient.ImageInspect(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := apiClient.ImageHistory(ctx, imageID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var diffIDs []string
+	if inspect.RootFS.Type == "layers" || inspect.RootFS.Type == "" {
+		diffIDs = inspect.RootFS.Layers
+	}
+	return layersFromHistory(history, diffIDs), nil
+}
+
+// emptyLayerDiffID is the diff ID of a layer that doesn't contain any changes.
+const emptyLayerDiffID = "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"
+
+// layersFromHistory returns the layers described by the given image history,
+// ordered from the base layer up, and identified by the diff IDs of the
+// image's root filesystem. History items that didn't produce a layer are
+// omitted, as are layers without any content.
+//
+// The history doesn't tell which items produced a layer, so items without a
+// size are matched with layers without any changes. If the history doesn't
+// match the diff IDs that way (for example, because an item produced a layer
+// that only removes files), the layers are returned without an ID, as they
+// can't be identified.
+func layersFromHistory(history []imagetypes.HistoryResponseItem, diffIDs []string) []imageLayer {
+	var layers []imageLayer
+	next := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if h.Size == 0 {
+			// History items without a size either didn't produce a
+			// layer, or produced a layer without any changes.
+			if next < len(diffIDs) && diffIDs[next] == emptyLayerDiffID {
+				next++
+			}
+			continue
+		}
+		l := imageLayer{
+			CreatedBy: h.CreatedBy,
+			Details: imageDetails{
+				DiskUsage: units.HumanSizeWithPrecision(float64(h.Size), 3),
+			},
+			size: h.Size,
+		}
+		if next < len(diffIDs) {
+			l.Details.ID = diffIDs[next]
+		}
+		layers = append(layers, l)
+		next++
+	}
+	if next != len(diffIDs) {
+		for i := range layers {
+			layers[i].Details.ID = ""
+		}
+	}
+	return layers
+}
+
+// attributeLayers marks the layers that are shared between images, and
+// calculates the size that would be freed by removing each image. Layers
+// that couldn't be identified are not taken into account.
+func attributeLayers(images []topImage) {
+	imageLayers := make([]map[string]int64, len(images))
+	refs := make(map[string]int)
+	for i, img := range images {
+		unique := make(map[string]int64)
+		for _, l := range img.allLayers() {
+			if l.Details.ID == "" {
+				continue
+			}
+			unique[l.Details.ID] = l.size
+		}
+		for id := range unique {
+			refs[id]++
+		}
+		imageLayers[i] = unique
+	}
+
+	for i := range images {
+		var uniqueSize int64
+		for id, size := range imageLayers[i] {
+			if refs[id] == 1 {
+				uniqueSize += size
+			}
+		}
+		images[i].Details.UniqueSize = units.HumanSizeWithPrecision(float64(uniqueSize), 3)
+
+		markShared := func(layers []imageLayer) {
+			for j := range layers {
+				layers[j].Details.Shared = refs[layers[j].Details.ID] > 1
+			}
+		}
+		markShared(images[i].Layers)
+		for j := range images[i].Children {
+			markShared(images[i].Children[j].Layers)
+		}
+	}
+}
+
+// allLayers returns the layers of the image and all its platform variants.
+func (img topImage) allLayers() []imageLayer {
+	layers := append([]imageLayer(nil), img.Layers...)
+	for _, sub := range img.Children {
+		layers = append(layers, sub.Layers...)
+	}
+	return layers
+}
+
+// printImageTreeJSON prints each image of the tree as a single line of JSON.
+func printImageTreeJSON(dockerCLI command.Cli, view treeView) error {
+	enc := json.NewEncoder(dockerCLI.Out())
+	for _, img := range view.images {
+		if err := enc.Encode(img); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 const columnSpacing = 3
@@ -133,6 +342,14 @@ type subImage struct {
 	check:  func(d *imageDetails) bool { return d.InUse },
 }
 
+var chipShared = imageChip{
+	letter: "S",
+	desc:   "Shared",
+	fg:     0,
+	bg:     11,
+	check:  func(d *imageDetails) bool { return d.Shared },
+}
+
 var chipPlaceholder = tui.Str{
 	Plain: " ",
 	Fancy: "   ",
@@ -156,19 +373,41 @@ func (c imageChip) String(isTerm bool) string {
 	chipInUse,
 }
 
+// layerChips are the chips that are only relevant when listing layers.
+var layerChips = []imageChip{
+	chipShared,
+}
+
+func (v treeView) chips() []imageChip {
+	if v.layers {
+		return append(append([]imageChip(nil), allChips...), layerChips...)
+	}
+	return allChips
+}
+
 func getPossibleChips(view treeView) (chips []imageChip) {
-	remaining := make([]imageChip, len(allChips))
-	copy(remaining, allChips)
+	candidates := view.chips()
+	remaining := make([]imageChip, len(candidates))
+	copy(remaining, candidates)
 
 	var possible []imageChip
+	check := func(d *imageDetails) {
+		for idx := len(remaining) - 1; idx >= 0; idx-- {
+			chip := remaining[idx]
+			if chip.check(d) {
+				possible = append(possible, chip)
+				remaining = append(remaining[:idx], remaining[idx+1:]...)
+			}
+		}
+	}
 	for _, img := range view.images {
+		for _, l := range img.Layers {
+			check(&l.Details)
+		}
 		for _, c := range img.Children {
-			for idx := len(remaining) - 1; idx >= 0; idx-- {
-				chip := remaining[idx]
-				if chip.check(&c.Details) {
-					possible = append(possible, chip)
-					remaining = append(remaining[:idx], remaining[idx+1:]...)
-				}
+			check(&c.Details)
+			for _, l := range c.Layers {
+				check(&l.Details)
 			}
 		}
 	}
@@ -193,7 +432,7 @@ func printImageTree(dockerCLI command.Cli, view treeView) error {
 
 	out.PrintlnWithColor(tui.ColorWarning, "WARNING: This is an experimental feature. The output may change and shouldn't be depended on.")
 
-	out.Println(generateLegend(out, width))
+	out.Println(generateLegend(out, width, view.chips()))
 	out.Println()
 
 	possibleChips := getPossibleChips(view)
@@ -227,37 +466,47 @@ func printImageTree(dockerCLI command.Cli, view treeView) error {
 				return d.ContentSize
 			},
 		},
-		{
-			Title: "Extra",
-			Align: alignLeft,
-			Width: func() int {
-				maxChipsWidth := 0
-				for _, chip := range possibleChips {
-					s := chip.String(isTerm)
-					l := tui.Width(s)
-					maxChipsWidth += l
-				}
-
-				le := len("Extra")
-				if le > maxChipsWidth {
-					return le
-				}
-				return maxChipsWidth
-			}(),
-			Color: &tui.ColorNone,
+	}
+	if view.layers {
+		columns = append(columns, imgColumn{
+			Title: "Unique size",
+			Align: alignRight,
+			Width: 11,
 			DetailsValue: func(d *imageDetails) string {
-				var out string
-				for _, chip := range possibleChips {
-					if chip.check(d) {
-						out += chip.String(isTerm)
-					} else {
-						out += chipPlaceholder.String(isTerm)
-					}
-				}
-				return out
+				return d.UniqueSize
 			},
-		},
+		})
 	}
+	columns = append(columns, imgColumn{
+		Title: "Extra",
+		Align: alignLeft,
+		Width: func() int {
+			maxChipsWidth := 0
+			for _, chip := range possibleChips {
+				s := chip.String(isTerm)
+				l := tui.Width(s)
+				maxChipsWidth += l
+			}
+
+			le := len("Extra")
+			if le > maxChipsWidth {
+				return le
+			}
+			return maxChipsWidth
+		}(),
+		Color: &tui.ColorNone,
+		DetailsValue: func(d *imageDetails) string {
+			var out string
+			for _, chip := range possibleChips {
+				if chip.check(d) {
+					out += chip.String(isTerm)
+				} else {
+					out += chipPlaceholder.String(isTerm)
+				}
+			}
+			return out
+		},
+	})
 
 	columns = adjustColumns(width, columns, view.images)
 
@@ -279,6 +528,7 @@ func printImageTree(dockerCLI command.Cli, view treeView) error {
 		if len(img.Children) > 0 || view.imageSpacing {
 			_, _ = fmt.Fprintln(out)
 		}
+		printLayers(out, columns, "", img.Layers, normalColor)
 		printChildren(out, columns, img, normalColor)
 		_, _ = fmt.Fprintln(out)
 	}
@@ -316,12 +566,12 @@ func adjustColumns(width uint, columns []imgColumn, images []topImage) []imgColu
 	return columns
 }
 
-func generateLegend(out tui.Output, width uint) string {
+func generateLegend(out tui.Output, width uint, chips []imageChip) string {
 	var legend string
 	legend += out.Sprint(tui.InfoHeader)
-	for idx, chip := range allChips {
+	for idx, chip := range chips {
 		legend += " " + out.Sprint(chip) + " " + chip.desc
-		if idx < len(allChips)-1 {
+		if idx < len(chips)-1 {
 			legend += " |"
 		}
 	}
@@ -367,7 +617,42 @@ func printChildren(out tui.Output, headers []imgColumn, img topImage, normalColo
 
 		printDetails(out, headers, clr, sub.Details)
 		_, _ = fmt.Fprintln(out, "")
+
+		indent := "   "
+		if idx != len(img.Children)-1 {
+			indent = "│  "
+		}
+		printLayers(out, headers, indent, sub.Layers, clr)
+		if sub.LayersError != "" {
+			_, _ = fmt.Fprintln(out, clr.With(aec.Faint).Apply(indent+"└─ "+sub.LayersError))
+		}
+	}
+}
+
+func printLayers(out tui.Output, headers []imgColumn, indent string, layers []imageLayer, clr aec.ANSI) {
+	for idx, l := range layers {
+		text := layerDescription(l)
+		if idx != len(layers)-1 {
+			_, _ = fmt.Fprint(out, headers[0].Print(clr, indent+"├─ "+text))
+		} else {
+			_, _ = fmt.Fprint(out, headers[0].Print(clr, indent+"└─ "+text))
+		}
+
+		printDetails(out, headers, clr, l.Details)
+		_, _ = fmt.Fprintln(out, "")
+	}
+}
+
+// layerDescription returns a single-line description of the command that
+// created the layer.
+func layerDescription(l imageLayer) string {
+	createdBy := strings.TrimPrefix(l.CreatedBy, "/bin/sh -c ")
+	createdBy = strings.TrimPrefix(createdBy, "#(nop) ")
+	createdBy = strings.Join(strings.Fields(createdBy), " ")
+	if createdBy == "" {
+		return "<missing>"
 	}
+	return createdBy
 }
 
 func printNames(out tui.Output, headers []imgColumn, img topImage, color, untaggedColor aec.ANSI) {
@@ -0,0 +1,197 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLayersFromHistory(t *testing.T) {
+	history := []image.HistoryResponseItem{
+		{CreatedBy: `/bin/sh -c #(nop)  CMD ["sh"]`, Created: 4},
+		{CreatedBy: "RUN apk add curl", Created: 3, Size: 2000},
+		{CreatedBy: "RUN true", Created: 2},
+		{CreatedBy: "ADD rootfs.tar.gz / # buildkit", Created: 1, Size: 1000},
+	}
+	diffIDs := []string{"sha256:base", emptyLayerDiffID, "sha256:curl"}
+
+	layers := layersFromHistory(history, diffIDs)
+	assert.Assert(t, is.Len(layers, 2))
+	assert.Check(t, is.Equal(layers[0].CreatedBy, "ADD rootfs.tar.gz / # buildkit"))
+	assert.Check(t, is.Equal(layers[0].Details.ID, "sha256:base"))
+	assert.Check(t, is.Equal(layers[0].size, int64(1000)))
+	assert.Check(t, is.Equal(layers[1].CreatedBy, "RUN apk add curl"))
+	assert.Check(t, is.Equal(layers[1].Details.ID, "sha256:curl"))
+	assert.Check(t, is.Equal(layers[1].size, int64(2000)))
+}
+
+func TestLayersFromHistoryZeroSizeLayer(t *testing.T) {
+	history := []image.HistoryResponseItem{
+		{CreatedBy: "RUN apk add curl", Created: 3, Size: 2000},
+		{CreatedBy: "RUN rm /etc/motd", Created: 2},
+		{CreatedBy: "ADD rootfs.tar.gz / # buildkit", Created: 1, Size: 1000},
+	}
+	// The layer created by "RUN rm /etc/motd" only contains a whiteout, so
+	// it has no size, but isn't the empty layer.
+	diffIDs := []string{"sha256:base", "sha256:whiteout", "sha256:curl"}
+
+	layers := layersFromHistory(history, diffIDs)
+	assert.Assert(t, is.Len(layers, 2))
+	assert.Check(t, is.Equal(layers[0].CreatedBy, "ADD rootfs.tar.gz / # buildkit"))
+	assert.Check(t, is.Equal(layers[0].Details.ID, ""), "layers must not be identified with the wrong diff ID")
+	assert.Check(t, is.Equal(layers[1].CreatedBy, "RUN apk add curl"))
+	assert.Check(t, is.Equal(layers[1].Details.ID, ""), "layers must not be identified with the wrong diff ID")
+	assert.Check(t, is.Equal(layers[1].size, int64(2000)))
+
+	images := []topImage{{Layers: layers}, {Layers: layers}}
+	attributeLayers(images)
+	assert.Check(t, is.Equal(images[0].Details.UniqueSize, "0B"))
+	assert.Check(t, !images[0].Layers[0].Details.Shared)
+}
+
+func TestAttributeLayers(t *testing.T) {
+	base := []image.HistoryResponseItem{
+		{CreatedBy: "ADD rootfs.tar.gz /", Created: 1, Size: 1000},
+	}
+	baseLayers := []string{"sha256:base"}
+	withApp := append([]image.HistoryResponseItem{
+		{CreatedBy: "COPY app /app", Created: 2, Size: 500},
+	}, base...)
+	withAppLayers := []string{"sha256:base", "sha256:app"}
+
+	images := []topImage{
+		{
+			Names: []string{"base:latest"},
+			Children: []subImage{
+				{Platform: "linux/amd64", Layers: layersFromHistory(base, baseLayers)},
+				{Platform: "linux/arm64", Layers: layersFromHistory(base, baseLayers)},
+			},
+		},
+		{
+			Names:  []string{"app:latest"},
+			Layers: layersFromHistory(withApp, withAppLayers),
+		},
+	}
+	attributeLayers(images)
+
+	assert.Check(t, is.Equal(images[0].Details.UniqueSize, "0B"))
+	assert.Check(t, images[0].Children[0].Layers[0].Details.Shared)
+
+	assert.Check(t, is.Equal(images[1].Details.UniqueSize, "500B"))
+	assert.Check(t, images[1].Layers[0].Details.Shared)
+	assert.Check(t, !images[1].Layers[1].Details.Shared)
+}
+
+func TestRunTreeLayersJSON(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options image.ListOptions) ([]image.Summary, error) {
+			assert.Check(t, options.Manifests)
+			return []image.Summary{{ID: "sha256:abc", RepoTags: []string{"app:latest"}, Size: 1500}}, nil
+		},
+		imageInspectFunc: func(img string) (image.InspectResponse, error) {
+			assert.Check(t, is.Equal(img, "sha256:abc"))
+			return image.InspectResponse{
+				RootFS: image.RootFS{Type: "layers", Layers: []string{"sha256:base", "sha256:app"}},
+			}, nil
+		},
+		imageHistoryFunc: func(img string, options ...client.ImageHistoryOption) ([]image.HistoryResponseItem, error) {
+			assert.Check(t, is.Equal(img, "sha256:abc"))
+			return []image.HistoryResponseItem{
+				{CreatedBy: "COPY app /app", Created: 2, Size: 500},
+				{CreatedBy: "ADD rootfs.tar.gz /", Created: 1, Size: 1000},
+			}, nil
+		},
+	})
+	err := runTree(context.Background(), cli, treeOptions{layers: true, format: "json"})
+	assert.NilError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(cli.OutBuffer().String()), "\n")
+	assert.Assert(t, is.Len(lines, 1))
+
+	var img topImage
+	assert.NilError(t, json.Unmarshal([]byte(lines[0]), &img))
+	assert.Check(t, is.DeepEqual(img.Names, []string{"app:latest"}))
+	assert.Check(t, is.Equal(img.Details.UniqueSize, "1.5kB"))
+	assert.Assert(t, is.Len(img.Layers, 2))
+	assert.Check(t, is.Equal(img.Layers[0].CreatedBy, "ADD rootfs.tar.gz /"))
+	assert.Check(t, is.Equal(img.Layers[0].Details.ID, "sha256:base"))
+	assert.Check(t, is.Equal(img.Layers[0].Details.DiskUsage, "1kB"))
+}
+
+func TestRunTreeLayersMultiPlatform(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options image.ListOptions) ([]image.Summary, error) {
+			return []image.Summary{{
+				ID:       "sha256:index",
+				RepoTags: []string{"app:latest"},
+				Manifests: []image.ManifestSummary{
+					{
+						ID:        "sha256:amd64",
+						Kind:      image.ManifestKindImage,
+						Available: true,
+						ImageData: &image.ImageProperties{Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}},
+					},
+					{
+						ID:        "sha256:arm64",
+						Kind:      image.ManifestKindImage,
+						Available: true,
+						ImageData: &image.ImageProperties{Platform: ocispec.Platform{OS: "linux", Architecture: "arm64"}},
+					},
+					{
+						ID:        "sha256:s390x",
+						Kind:      image.ManifestKindImage,
+						Available: false,
+						ImageData: &image.ImageProperties{Platform: ocispec.Platform{OS: "linux", Architecture: "s390x"}},
+					},
+				},
+			}}, nil
+		},
+		imageInspectFunc: func(img string) (image.InspectResponse, error) {
+			switch img {
+			case "sha256:amd64":
+				return image.InspectResponse{
+					RootFS: image.RootFS{Type: "layers", Layers: []string{"sha256:base"}},
+				}, nil
+			case "sha256:arm64":
+				return image.InspectResponse{}, errdefs.NotFound(errors.New("No such image: sha256:arm64"))
+			default:
+				t.Errorf("unexpected inspect of %s", img)
+				return image.InspectResponse{}, errdefs.NotFound(errors.New("No such image: " + img))
+			}
+		},
+		imageHistoryFunc: func(img string, options ...client.ImageHistoryOption) ([]image.HistoryResponseItem, error) {
+			assert.Check(t, is.Equal(img, "sha256:amd64"))
+			return []image.HistoryResponseItem{
+				{CreatedBy: "ADD rootfs.tar.gz /", Created: 1, Size: 1000},
+			}, nil
+		},
+	})
+	err := runTree(context.Background(), cli, treeOptions{layers: true, format: "json"})
+	assert.NilError(t, err)
+
+	var img topImage
+	assert.NilError(t, json.Unmarshal(cli.OutBuffer().Bytes(), &img))
+	assert.Assert(t, is.Len(img.Children, 3))
+	assert.Assert(t, is.Len(img.Children[0].Layers, 1))
+	assert.Check(t, is.Equal(img.Children[0].Layers[0].Details.ID, "sha256:base"))
+	assert.Check(t, is.Equal(img.Children[0].LayersError, ""))
+	assert.Check(t, is.Len(img.Children[1].Layers, 0))
+	assert.Check(t, is.Equal(img.Children[1].LayersError, "layers not available: No such image: sha256:arm64"))
+	assert.Check(t, is.Len(img.Children[2].Layers, 0))
+	assert.Check(t, is.Equal(img.Children[2].LayersError, ""))
+
+	cli.OutBuffer().Reset()
+	err = runTree(context.Background(), cli, treeOptions{layers: true})
+	assert.NilError(t, err)
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "└─ layers not available: No such image: sha256:arm64"))
+}
@@ -187,7 +187,7 @@ func trustedPull(ctx context.Context, cli command.Cli, imgRefAndAuth trust.Image
 		if err != nil {
 			return err
 		}
-		updatedImgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, AuthResolver(cli), trustedRef.String())
+		updatedImgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, RepositoryAuthResolver(cli), trustedRef.String())
 		if err != nil {
 			return err
 		}
@@ -288,7 +288,7 @@ func imagePullPrivileged(ctx context.Context, cli command.Cli, imgRefAndAuth tru
 
 // TrustedReference returns the canonical trusted reference for an image reference
 func TrustedReference(ctx context.Context, cli command.Cli, ref reference.NamedTagged) (reference.Canonical, error) {
-	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, AuthResolver(cli), ref.String())
+	imgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, RepositoryAuthResolver(cli), ref.String())
 	if err != nil {
 		return nil, err
 	}
@@ -340,6 +340,15 @@ func TagTrusted(ctx context.Context, cli command.Cli, trustedRef reference.Canon
 // AuthResolver returns an auth resolver function from a command.Cli
 func AuthResolver(cli command.Cli) func(ctx context.Context, index *registrytypes.IndexInfo) registrytypes.AuthConfig {
 	return func(ctx context.Context, index *registrytypes.IndexInfo) registrytypes.AuthConfig {
-		return command.ResolveAuthConfig(cli.ConfigFile(), index)
+		return command.ResolveAuthConfig(ctx, cli.ConfigFile(), index)
+	}
+}
+
+// RepositoryAuthResolver returns an auth resolver function from a command.Cli
+// that resolves credentials for a repository, including credentials that are
+// scoped to a repository path.
+func RepositoryAuthResolver(cli command.Cli) func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig {
+	return func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig {
+		return command.ResolveRepositoryAuthConfig(ctx, cli.ConfigFile(), repoInfo)
 	}
 }
@@ -6,6 +6,7 @@
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/errdefs"
 	"github.com/pkg/errors"
@@ -15,6 +16,7 @@
 type pruneOptions struct {
 	force  bool
 	filter opts.FilterOpt
+	dryRun prune.DryRunOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for networks
@@ -26,6 +28,17 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove all unused networks",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.dryRun.Validate(); err != nil {
+				return err
+			}
+			if options.dryRun.Enabled {
+				return runPruneDryRun(cmd.Context(), dockerCli, options)
+			}
+			confirmed, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, options.force)
+			if err != nil {
+				return err
+			}
+			options.force = options.force || confirmed
 			output, err := runPrune(cmd.Context(), dockerCli, options)
 			if err != nil {
 				return err
@@ -41,6 +54,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVarP(&options.force, "force", "f", false, "Do not prompt for confirmation")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "until=<timestamp>")`)
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -76,6 +90,19 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return output, nil
 }
 
+// runPruneDryRun lists the networks that would be removed, without removing
+// them.
+func runPruneDryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
+	candidates, err := prune.Plan(ctx, dockerCli.Client(), prune.Policy{
+		Networks: &prune.Rule{Filters: pruneFilters},
+	})
+	if err != nil {
+		return err
+	}
+	return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+}
+
 // RunPrune calls the Network Prune API
 // This returns the amount of space reclaimed and a detailed output string
 func RunPrune(ctx context.Context, dockerCli command.Cli, _ bool, filter opts.FilterOpt) (uint64, string, error) {
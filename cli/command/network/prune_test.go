@@ -9,6 +9,8 @@
 	"github.com/docker/cli/internal/test"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestNetworkPrunePromptTermination(t *testing.T) {
@@ -26,3 +28,38 @@ func TestNetworkPrunePromptTermination(t *testing.T) {
 	cmd.SetErr(io.Discard)
 	test.TerminatePrompt(ctx, t, cmd, cli)
 }
+
+func TestNetworkPruneDryRun(t *testing.T) {
+	networks := []network.Summary{
+		{ID: "bridge-id", Name: "bridge"},
+		{ID: "1111111111111111", Name: "unused"},
+		{ID: "2222222222222222", Name: "used", Containers: map[string]network.EndpointResource{"c1": {}}},
+	}
+	cli := test.NewFakeCli(&fakeClient{
+		networkListFunc: func(context.Context, network.ListOptions) ([]network.Summary, error) {
+			return networks, nil
+		},
+		networkInspectFunc: func(_ context.Context, networkID string, _ network.InspectOptions) (network.Inspect, []byte, error) {
+			for _, nw := range networks {
+				if nw.ID == networkID {
+					return nw, nil, nil
+				}
+			}
+			return network.Inspect{}, nil, errors.New("not found")
+		},
+		networkPruneFunc: func(context.Context, filters.Args) (network.PruneReport, error) {
+			return network.PruneReport{}, errors.New("fakeClient networkPruneFunc should not be called")
+		},
+	})
+	cmd := NewPruneCommand(cli)
+	cmd.SetArgs([]string{"--dry-run"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expected := `TYPE      ID             NAME      SIZE
+network   111111111111   unused    0B
+Total reclaimable space: 0B
+`
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+}
@@ -26,6 +26,9 @@ func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
 		Short:   "Remove one or more networks",
 		Args:    cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runRemove(cmd.Context(), dockerCli, args, &opts)
 		},
 		ValidArgsFunction: completion.NetworkNames(dockerCli),
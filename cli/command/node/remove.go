@@ -24,6 +24,9 @@ func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
 		Short:   "Remove one or more nodes from the swarm",
 		Args:    cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runRemove(cmd.Context(), dockerCli, args, opts)
 		},
 		ValidArgsFunction: completeNodeNames(dockerCli),
@@ -35,7 +35,7 @@ func RegistryAuthenticationPrivilegedFunc(cli Cli, index *registrytypes.IndexInf
 		_, _ = fmt.Fprintf(cli.Out(), "\nLogin prior to %s:\n", cmdName)
 		indexServer := registry.GetAuthConfigKey(index)
 		isDefaultRegistry := indexServer == registry.IndexServer
-		authConfig, err := GetDefaultAuthConfig(cli.ConfigFile(), true, indexServer, isDefaultRegistry)
+		authConfig, err := GetDefaultAuthConfig(ctx, cli.ConfigFile(), true, indexServer, isDefaultRegistry)
 		if err != nil {
 			_, _ = fmt.Fprintf(cli.Err(), "Unable to retrieve stored credentials for %s, error: %s.\n", indexServer, err)
 		}
@@ -56,29 +56,57 @@ func RegistryAuthenticationPrivilegedFunc(cli Cli, index *registrytypes.IndexInf
 
 // ResolveAuthConfig returns auth-config for the given registry from the
 // credential-store. It returns an empty AuthConfig if no credentials were
-// found.
+// found. Credentials that are about to expire are refreshed first, if they
+// can be refreshed.
 //
 // It is similar to [registry.ResolveAuthConfig], but uses the credentials-
 // store, instead of looking up credentials from a map.
-func ResolveAuthConfig(cfg *configfile.ConfigFile, index *registrytypes.IndexInfo) registrytypes.AuthConfig {
+func ResolveAuthConfig(ctx context.Context, cfg *configfile.ConfigFile, index *registrytypes.IndexInfo) registrytypes.AuthConfig {
 	configKey := index.Name
 	if index.Official {
 		configKey = registry.IndexServer
 	}
 
+	refreshExpiringCredentials(ctx, cfg, cfg.ResolveCredentialsKey(configKey))
 	a, _ := cfg.GetAuthConfig(configKey)
 	return registrytypes.AuthConfig(a)
 }
 
+// ResolveRepositoryAuthConfig returns auth-config for the given repository
+// from the credential-store. Credentials that are scoped to the longest
+// matching repository path (such as "registry.example.com/team-a") are used
+// if there are any, and the credentials for the registry otherwise. It returns
+// an empty AuthConfig if no credentials were found. Credentials that are about
+// to expire are refreshed first, if they can be refreshed.
+func ResolveRepositoryAuthConfig(ctx context.Context, cfg *configfile.ConfigFile, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig {
+	if key := cfg.ResolveCredentialsKey(repoInfo.Name.Name()); key != reference.Domain(repoInfo.Name) {
+		refreshExpiringCredentials(ctx, cfg, key)
+		a, _ := cfg.GetAuthConfig(key)
+		return registrytypes.AuthConfig(a)
+	}
+	return ResolveAuthConfig(ctx, cfg, repoInfo.Index)
+}
+
+// GetAuthConfig returns the credentials that are stored under the given key
+// in the credential-store, such as a registry hostname or a repository path.
+// Unlike [configfile.ConfigFile.GetAuthConfig], credentials that are about to
+// expire are refreshed first, if they can be refreshed.
+func GetAuthConfig(ctx context.Context, cfg *configfile.ConfigFile, key string) (configtypes.AuthConfig, error) {
+	refreshExpiringCredentials(ctx, cfg, key)
+	return cfg.GetAuthConfig(key)
+}
+
 // GetDefaultAuthConfig gets the default auth config given a serverAddress
-// If credentials for given serverAddress exists in the credential store, the configuration will be populated with values in it
-func GetDefaultAuthConfig(cfg *configfile.ConfigFile, checkCredStore bool, serverAddress string, isDefaultRegistry bool) (registrytypes.AuthConfig, error) {
+// If credentials for given serverAddress exists in the credential store, the configuration will be populated with values in it.
+// Credentials that are about to expire are refreshed first, if they can be refreshed.
+func GetDefaultAuthConfig(ctx context.Context, cfg *configfile.ConfigFile, checkCredStore bool, serverAddress string, isDefaultRegistry bool) (registrytypes.AuthConfig, error) {
 	if !isDefaultRegistry {
 		serverAddress = credentials.ConvertToHostname(serverAddress)
 	}
 	authconfig := configtypes.AuthConfig{}
 	var err error
 	if checkCredStore {
+		refreshExpiringCredentials(ctx, cfg, serverAddress)
 		authconfig, err = cfg.GetAuthConfig(serverAddress)
 		if err != nil {
 			return registrytypes.AuthConfig{
@@ -91,6 +119,28 @@ func GetDefaultAuthConfig(cfg *configfile.ConfigFile, checkCredStore bool, serve
 	return registrytypes.AuthConfig(authconfig), nil
 }
 
+// GetScopedAuthConfig is like [GetDefaultAuthConfig], but for credentials that
+// are scoped to a repository path, such as "registry.example.com/team-a".
+// Credentials for the registry, or a less specific repository path, are not
+// used as default.
+func GetScopedAuthConfig(ctx context.Context, cfg *configfile.ConfigFile, checkCredStore bool, repositoryPath string) (registrytypes.AuthConfig, error) {
+	key := credentials.ConvertToCredentialsKey(repositoryPath)
+	authconfig := configtypes.AuthConfig{}
+	var err error
+	if checkCredStore && cfg.ResolveCredentialsKey(key) == key {
+		refreshExpiringCredentials(ctx, cfg, key)
+		authconfig, err = cfg.GetAuthConfig(key)
+		if err != nil {
+			return registrytypes.AuthConfig{
+				ServerAddress: key,
+			}, err
+		}
+	}
+	authconfig.ServerAddress = key
+	authconfig.IdentityToken = ""
+	return registrytypes.AuthConfig(authconfig), nil
+}
+
 // ConfigureAuth handles prompting of user's username and password if needed.
 //
 // Deprecated: use [PromptUserForCredentials] instead.
@@ -206,9 +256,9 @@ func PromptUserForCredentials(ctx context.Context, cli Cli, argUser, argPassword
 //
 // For details on base64url encoding, see:
 // - RFC4648, section 5:   https://tools.ietf.org/html/rfc4648#section-5
-func RetrieveAuthTokenFromImage(cfg *configfile.ConfigFile, image string) (string, error) {
+func RetrieveAuthTokenFromImage(ctx context.Context, cfg *configfile.ConfigFile, image string) (string, error) {
 	// Retrieve encoded auth token from the image reference
-	authConfig, err := resolveAuthConfigFromImage(cfg, image)
+	authConfig, err := resolveAuthConfigFromImage(ctx, cfg, image)
 	if err != nil {
 		return "", err
 	}
@@ -220,7 +270,7 @@ func RetrieveAuthTokenFromImage(cfg *configfile.ConfigFile, image string) (strin
 }
 
 // resolveAuthConfigFromImage retrieves that AuthConfig using the image string
-func resolveAuthConfigFromImage(cfg *configfile.ConfigFile, image string) (registrytypes.AuthConfig, error) {
+func resolveAuthConfigFromImage(ctx context.Context, cfg *configfile.ConfigFile, image string) (registrytypes.AuthConfig, error) {
 	registryRef, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
 		return registrytypes.AuthConfig{}, err
@@ -229,5 +279,5 @@ func resolveAuthConfigFromImage(cfg *configfile.ConfigFile, image string) (regis
 	if err != nil {
 		return registrytypes.AuthConfig{}, err
 	}
-	return ResolveAuthConfig(cfg, repoInfo.Index), nil
+	return ResolveRepositoryAuthConfig(ctx, cfg, repoInfo), nil
 }
@@ -7,12 +7,15 @@
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
 	configtypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/cli/internal/oauth"
 	"github.com/docker/cli/cli/internal/oauth/manager"
 	"github.com/docker/cli/internal/tui"
 	registrytypes "github.com/docker/docker/api/types/registry"
@@ -28,6 +31,9 @@ type loginOptions struct {
 	user          string
 	password      string
 	passwordStdin bool
+	oidcIssuer    string
+	clientID      string
+	scope         bool
 }
 
 // NewLoginCommand creates a new `docker login` command
@@ -56,11 +62,23 @@ func NewLoginCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.StringVarP(&opts.user, "username", "u", "", "Username")
 	flags.StringVarP(&opts.password, "password", "p", "", "Password or Personal Access Token (PAT)")
 	flags.BoolVar(&opts.passwordStdin, "password-stdin", false, "Take the Password or Personal Access Token (PAT) from stdin")
+	flags.StringVar(&opts.oidcIssuer, "oidc-issuer", "", "Authenticate with the OpenID Connect provider at this issuer URL")
+	flags.StringVar(&opts.clientID, "client-id", "", "Client ID to authenticate with the OpenID Connect provider")
+	flags.BoolVar(&opts.scope, "scope", false, "Scope the credentials to the repository path of the server, such as registry.example.com/team-a")
 
 	return cmd
 }
 
 func verifyLoginOptions(dockerCLI command.Cli, opts *loginOptions) error {
+	if opts.oidcIssuer != "" || opts.clientID != "" {
+		if opts.oidcIssuer == "" || opts.clientID == "" {
+			return errors.New("--oidc-issuer and --client-id must be used together")
+		}
+		if opts.user != "" || opts.password != "" || opts.passwordStdin {
+			return errors.New("--oidc-issuer cannot be used with --username, --password, or --password-stdin")
+		}
+	}
+
 	if opts.password != "" {
 		_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING! Using --password via the CLI is insecure. Use --password-stdin.")
 		if opts.passwordStdin {
@@ -84,6 +102,18 @@ func verifyLoginOptions(dockerCLI command.Cli, opts *loginOptions) error {
 	return nil
 }
 
+// validateScope validates that the server address has a repository path, to
+// which the credentials can be scoped.
+func validateScope(serverAddress string) error {
+	if !strings.Contains(credentials.ConvertToCredentialsKey(serverAddress), "/") {
+		return errors.Errorf("invalid server %q: --scope requires a server with a repository path, such as registry.example.com/team-a", serverAddress)
+	}
+	if credentials.IsOAuthTokenKey(credentials.ConvertToCredentialsKey(serverAddress)) {
+		return errors.Errorf("invalid server %q: not a valid repository path", serverAddress)
+	}
+	return nil
+}
+
 func runLogin(ctx context.Context, dockerCLI command.Cli, opts loginOptions) error {
 	if err := verifyLoginOptions(dockerCLI, &opts); err != nil {
 		return err
@@ -98,9 +128,26 @@ func runLogin(ctx context.Context, dockerCLI command.Cli, opts loginOptions) err
 		serverAddress = registry.IndexServer
 	}
 	isDefaultRegistry := serverAddress == registry.IndexServer
+	if isDefaultRegistry && opts.oidcIssuer != "" {
+		return errors.New("--oidc-issuer cannot be used to log in to Docker Hub: specify the registry to log in to")
+	}
+	if opts.scope {
+		if err := validateScope(serverAddress); err != nil {
+			return err
+		}
+	}
 
 	// attempt login with current (stored) credentials
-	authConfig, err := command.GetDefaultAuthConfig(dockerCLI.ConfigFile(), opts.user == "" && opts.password == "", serverAddress, isDefaultRegistry)
+	var (
+		authConfig     registrytypes.AuthConfig
+		err            error
+		checkCredStore = opts.user == "" && opts.password == "" && opts.oidcIssuer == ""
+	)
+	if opts.scope {
+		authConfig, err = command.GetScopedAuthConfig(ctx, dockerCLI.ConfigFile(), checkCredStore, serverAddress)
+	} else {
+		authConfig, err = command.GetDefaultAuthConfig(ctx, dockerCLI.ConfigFile(), checkCredStore, serverAddress, isDefaultRegistry)
+	}
 	if err == nil && authConfig.Username != "" && authConfig.Password != "" {
 		msg, err = loginWithStoredCredentials(ctx, dockerCLI, authConfig)
 	}
@@ -117,6 +164,9 @@ func runLogin(ctx context.Context, dockerCLI command.Cli, opts loginOptions) err
 	if msg != "" {
 		_, _ = fmt.Fprintln(dockerCLI.Out(), msg)
 	}
+	for _, w := range command.CredentialExpiryWarnings(dockerCLI.ConfigFile(), time.Now(), authConfig.ServerAddress) {
+		_, _ = fmt.Fprintln(dockerCLI.Err(), w)
+	}
 	return nil
 }
 
@@ -141,12 +191,14 @@ func loginWithStoredCredentials(ctx context.Context, dockerCLI command.Cli, auth
 		}
 	}
 
+	expiry := dockerCLI.ConfigFile().GetCredentialExpiry(authConfig.ServerAddress)
 	if response.IdentityToken != "" {
 		authConfig.Password = ""
 		authConfig.IdentityToken = response.IdentityToken
+		expiry = tokenExpiry(authConfig)
 	}
 
-	if err := storeCredentials(dockerCLI.ConfigFile(), authConfig); err != nil {
+	if err := storeCredentials(dockerCLI.ConfigFile(), authConfig, expiry); err != nil {
 		return "", err
 	}
 
@@ -167,6 +219,13 @@ func isOauthLoginDisabled() bool {
 }
 
 func loginUser(ctx context.Context, dockerCLI command.Cli, opts loginOptions, defaultUsername, serverAddress string) (msg string, _ error) {
+	// If the registry authenticates with an OpenID Connect provider, use the
+	// device flow. It does not need a TTY, as the user authenticates in the
+	// browser, possibly on another device.
+	if provider, ok := oidcProvider(dockerCLI.ConfigFile(), opts, serverAddress); ok && opts.user == "" && opts.password == "" {
+		return loginWithOIDCDeviceFlow(ctx, dockerCLI, serverAddress, provider, opts.oidcIssuer != "")
+	}
+
 	// Some links documenting this:
 	// - https://code.google.com/archive/p/mintty/issues/56
 	// - https://github.com/docker/docker/issues/15272
@@ -209,7 +268,7 @@ func loginWithUsernameAndPassword(ctx context.Context, dockerCLI command.Cli, op
 		authConfig.Password = ""
 		authConfig.IdentityToken = response.IdentityToken
 	}
-	if err = storeCredentials(dockerCLI.ConfigFile(), authConfig); err != nil {
+	if err = storeCredentials(dockerCLI.ConfigFile(), authConfig, tokenExpiry(authConfig)); err != nil {
 		return "", err
 	}
 
@@ -218,7 +277,12 @@ func loginWithUsernameAndPassword(ctx context.Context, dockerCLI command.Cli, op
 
 func loginWithDeviceCodeFlow(ctx context.Context, dockerCLI command.Cli) (msg string, _ error) {
 	store := dockerCLI.ConfigFile().GetCredentialsStore(registry.IndexServer)
-	authConfig, err := manager.NewManager(store).LoginDevice(ctx, dockerCLI.Err())
+	oauthManager := manager.NewManager(store)
+	authConfig, err := oauthManager.LoginDevice(ctx, dockerCLI.Err())
+	if err != nil {
+		return "", err
+	}
+	expiry, err := oauthManager.Expiry()
 	if err != nil {
 		return "", err
 	}
@@ -228,14 +292,83 @@ func loginWithDeviceCodeFlow(ctx context.Context, dockerCLI command.Cli) (msg st
 		return "", err
 	}
 
-	if err = storeCredentials(dockerCLI.ConfigFile(), registrytypes.AuthConfig(*authConfig)); err != nil {
+	if err = storeCredentials(dockerCLI.ConfigFile(), registrytypes.AuthConfig(*authConfig), expiry); err != nil {
 		return "", err
 	}
 
 	return response.Status, nil
 }
 
-func storeCredentials(cfg *configfile.ConfigFile, authConfig registrytypes.AuthConfig) error {
+// oidcProvider returns the OpenID Connect provider to authenticate to the
+// registry with, as set with the --oidc-issuer and --client-id options, or
+// configured in the "oidcProviders" property of the configuration file.
+func oidcProvider(cfg *configfile.ConfigFile, opts loginOptions, serverAddress string) (configfile.OIDCProvider, bool) {
+	if serverAddress == registry.IndexServer {
+		return configfile.OIDCProvider{}, false
+	}
+	if opts.oidcIssuer != "" {
+		return configfile.OIDCProvider{
+			Issuer:   opts.oidcIssuer,
+			ClientID: opts.clientID,
+		}, true
+	}
+	provider, ok := cfg.OIDCProviders[serverAddress]
+	return provider, ok
+}
+
+// loginWithOIDCDeviceFlow authenticates to the registry with the device flow
+// of its OpenID Connect provider, and stores the access token as password for
+// the registry. If save is set, the provider is saved in the configuration
+// file, so that the credentials can be refreshed before they expire.
+func loginWithOIDCDeviceFlow(ctx context.Context, dockerCLI command.Cli, serverAddress string, provider configfile.OIDCProvider, save bool) (msg string, _ error) {
+	cfg := dockerCLI.ConfigFile()
+	oauthManager, err := manager.NewOIDCManager(ctx, manager.OIDCOptions{
+		Store:         cfg.GetCredentialsStore(serverAddress),
+		ServerAddress: serverAddress,
+		Issuer:        provider.Issuer,
+		ClientID:      provider.ClientID,
+		Scopes:        provider.Scopes,
+		Audience:      provider.Audience,
+	})
+	if err != nil {
+		return "", err
+	}
+	authConfig, err := oauthManager.LoginDevice(ctx, dockerCLI.Err())
+	if err != nil {
+		return "", err
+	}
+	expiry, err := oauthManager.Expiry()
+	if err != nil {
+		return "", err
+	}
+
+	response, err := loginWithRegistry(ctx, dockerCLI.Client(), registrytypes.AuthConfig(*authConfig))
+	if err != nil {
+		return "", err
+	}
+
+	if save {
+		if cfg.OIDCProviders == nil {
+			cfg.OIDCProviders = make(map[string]configfile.OIDCProvider)
+		}
+		cfg.OIDCProviders[serverAddress] = provider
+	}
+	if err = storeCredentials(cfg, registrytypes.AuthConfig(*authConfig), expiry); err != nil {
+		return "", err
+	}
+	if save {
+		if err := cfg.Save(); err != nil {
+			return "", errors.Wrap(err, "failed to save OpenID Connect provider")
+		}
+	}
+
+	return response.Status, nil
+}
+
+// storeCredentials stores the credentials in the credentials store, and
+// records when they expire. A zero expiry means that it is not known.
+func storeCredentials(cfg *configfile.ConfigFile, authConfig registrytypes.AuthConfig, expiry time.Time) error {
+	cfg.SetCredentialExpiry(authConfig.ServerAddress, expiry)
 	creds := cfg.GetCredentialsStore(authConfig.ServerAddress)
 	if err := creds.Store(configtypes.AuthConfig(authConfig)); err != nil {
 		return errors.Errorf("Error saving credentials: %v", err)
@@ -244,6 +377,21 @@ func storeCredentials(cfg *configfile.ConfigFile, authConfig registrytypes.AuthC
 	return nil
 }
 
+// tokenExpiry returns when the identity token or password of the credentials
+// expires, if it is a JSON Web Token (as issued by some registries) with an
+// expiry. It returns a zero time otherwise.
+func tokenExpiry(authConfig registrytypes.AuthConfig) time.Time {
+	for _, token := range []string{authConfig.IdentityToken, authConfig.Password} {
+		if token == "" {
+			continue
+		}
+		if claims, err := oauth.GetClaims(token); err == nil && claims.Expiry != nil {
+			return claims.Expiry.Time()
+		}
+	}
+	return time.Time{}
+}
+
 func loginWithRegistry(ctx context.Context, apiClient client.SystemAPIClient, authConfig registrytypes.AuthConfig) (*registrytypes.AuthenticateOKBody, error) {
 	response, err := apiClient.RegistryLogin(ctx, authConfig)
 	if err != nil {
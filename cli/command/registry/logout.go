@@ -3,28 +3,36 @@
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/cli/cli/internal/oauth/manager"
 	"github.com/docker/docker/registry"
 	"github.com/spf13/cobra"
 )
 
+type logoutOptions struct {
+	serverAddress string
+	scope         bool
+}
+
 // NewLogoutCommand creates a new `docker logout` command
 func NewLogoutCommand(dockerCli command.Cli) *cobra.Command {
+	var opts logoutOptions
+
 	cmd := &cobra.Command{
-		Use:   "logout [SERVER]",
+		Use:   "logout [OPTIONS] [SERVER]",
 		Short: "Log out from a registry",
 		Long:  "Log out from a registry.\nIf no server is specified, the default is defined by the daemon.",
 		Args:  cli.RequiresMaxArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var serverAddress string
 			if len(args) > 0 {
-				serverAddress = args[0]
+				opts.serverAddress = args[0]
 			}
-			return runLogout(cmd.Context(), dockerCli, serverAddress)
+			return runLogout(cmd.Context(), dockerCli, opts)
 		},
 		Annotations: map[string]string{
 			"category-top": "9",
@@ -32,12 +40,23 @@ func NewLogoutCommand(dockerCli command.Cli) *cobra.Command {
 		// TODO (thaJeztah) add completion for registries we have authentication stored for
 	}
 
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.scope, "scope", false, "Remove the credentials that are scoped to the repository path of the server")
+
 	return cmd
 }
 
-func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string) error {
-	var isDefaultRegistry bool
+func runLogout(ctx context.Context, dockerCLI command.Cli, opts logoutOptions) error {
+	var (
+		serverAddress     = opts.serverAddress
+		isDefaultRegistry bool
+	)
 
+	if opts.scope {
+		if err := validateScope(serverAddress); err != nil {
+			return err
+		}
+	}
 	if serverAddress == "" {
 		serverAddress = registry.IndexServer
 		isDefaultRegistry = true
@@ -47,7 +66,13 @@ func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string)
 		regsToLogout    = []string{serverAddress}
 		hostnameAddress = serverAddress
 	)
-	if !isDefaultRegistry {
+	switch {
+	case opts.scope:
+		// credentials that are scoped to a repository path are only
+		// stored under the hostname and repository path.
+		hostnameAddress = credentials.ConvertToCredentialsKey(serverAddress)
+		regsToLogout = []string{hostnameAddress}
+	case !isDefaultRegistry:
 		hostnameAddress = credentials.ConvertToHostname(serverAddress)
 		// the tries below are kept for backward compatibility where a user could have
 		// saved the registry in one of the following format.
@@ -59,11 +84,16 @@ func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string)
 		if err := manager.NewManager(store).Logout(ctx); err != nil {
 			_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING:", err)
 		}
+	} else if provider, ok := dockerCLI.ConfigFile().OIDCProviders[hostnameAddress]; ok {
+		if err := logoutOIDC(ctx, dockerCLI, hostnameAddress, provider); err != nil {
+			_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING:", err)
+		}
 	}
 
 	_, _ = fmt.Fprintln(dockerCLI.Out(), "Removing login credentials for", hostnameAddress)
 	errs := make(map[string]error)
 	for _, r := range regsToLogout {
+		dockerCLI.ConfigFile().SetCredentialExpiry(r, time.Time{})
 		if err := dockerCLI.ConfigFile().GetCredentialsStore(r).Erase(r); err != nil {
 			errs[r] = err
 		}
@@ -79,3 +109,22 @@ func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string)
 
 	return nil
 }
+
+// logoutOIDC revokes the refresh token that was obtained from the OpenID
+// Connect provider of the registry, and erases the tokens from the store.
+// The tokens are erased without revoking them if the provider cannot be
+// reached.
+func logoutOIDC(ctx context.Context, dockerCLI command.Cli, registryHostname string, provider configfile.OIDCProvider) error {
+	store := dockerCLI.ConfigFile().GetCredentialsStore(registryHostname)
+	m, err := manager.NewOIDCManager(ctx, manager.OIDCOptions{
+		Store:         store,
+		ServerAddress: registryHostname,
+		Issuer:        provider.Issuer,
+		ClientID:      provider.ClientID,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING: failed to revoke the OAuth refresh token:", err)
+		return manager.EraseTokens(store, registryHostname)
+	}
+	return m.Logout(ctx)
+}
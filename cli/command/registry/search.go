@@ -57,7 +57,7 @@ func runSearch(ctx context.Context, dockerCli command.Cli, options searchOptions
 		return err
 	}
 
-	authConfig := command.ResolveAuthConfig(dockerCli.ConfigFile(), indexInfo)
+	authConfig := command.ResolveAuthConfig(ctx, dockerCli.ConfigFile(), indexInfo)
 	encodedAuth, err := registrytypes.EncodeAuthConfig(authConfig)
 	if err != nil {
 		return err
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	configtypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/cli/internal/oauth/manager"
+	"github.com/docker/docker/registry"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CredentialExpiryWarningPeriod is how long before credentials expire
+	// that commands such as "docker info" and "docker login" warn about it.
+	CredentialExpiryWarningPeriod = 7 * 24 * time.Hour
+
+	// credentialRefreshTimeout is the maximum time to wait for credentials
+	// to be refreshed when resolving credentials.
+	credentialRefreshTimeout = 30 * time.Second
+)
+
+// credentialRefresher returns the function to refresh the credentials for the
+// given registry, or nil if they cannot be refreshed. Credentials for
+// registries that authenticate with an OpenID Connect provider, and Docker Hub
+// credentials that were obtained with the web-based login can be refreshed.
+//
+// var for unit testing.
+var credentialRefresher = func(cfg *configfile.ConfigFile, registryHostname string) configfile.CredentialRefreshFunc {
+	if provider, ok := cfg.OIDCProviders[registryHostname]; ok {
+		return oidcCredentialRefresher(registryHostname, provider)
+	}
+	if registryHostname == registry.IndexServer {
+		return refreshHubCredentials
+	}
+	return nil
+}
+
+// refreshExpiringCredentials refreshes the credentials for the given registry
+// if they are about to expire, and can be refreshed. Failing to refresh
+// credentials is not fatal, as the current credentials may still be accepted.
+func refreshExpiringCredentials(ctx context.Context, cfg *configfile.ConfigFile, registryHostname string) {
+	if !cfg.NeedsRefresh(registryHostname) {
+		return
+	}
+	refresh := credentialRefresher(cfg, registryHostname)
+	if refresh == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, credentialRefreshTimeout)
+	defer cancel()
+	if err := cfg.RefreshCredentials(ctx, registryHostname, refresh); err != nil {
+		logrus.WithError(err).Warnf("Failed to refresh credentials for registry: %s", registryHostname)
+	}
+}
+
+// refreshHubCredentials refreshes Docker Hub credentials that were obtained
+// with the web-based login, using the refresh token that was stored with them.
+func refreshHubCredentials(ctx context.Context, store credentials.Store) (configtypes.AuthConfig, time.Time, error) {
+	return refreshOAuthCredentials(ctx, manager.NewManager(store))
+}
+
+// oidcCredentialRefresher returns the function to refresh the credentials for
+// a registry that authenticates with the given OpenID Connect provider.
+func oidcCredentialRefresher(registryHostname string, provider configfile.OIDCProvider) configfile.CredentialRefreshFunc {
+	return func(ctx context.Context, store credentials.Store) (configtypes.AuthConfig, time.Time, error) {
+		m, err := manager.NewOIDCManager(ctx, manager.OIDCOptions{
+			Store:         store,
+			ServerAddress: registryHostname,
+			Issuer:        provider.Issuer,
+			ClientID:      provider.ClientID,
+			Scopes:        provider.Scopes,
+			Audience:      provider.Audience,
+		})
+		if err != nil {
+			return configtypes.AuthConfig{}, time.Time{}, err
+		}
+		return refreshOAuthCredentials(ctx, m)
+	}
+}
+
+func refreshOAuthCredentials(ctx context.Context, m *manager.OAuthManager) (configtypes.AuthConfig, time.Time, error) {
+	authConfig, err := m.Refresh(ctx)
+	if err != nil {
+		return configtypes.AuthConfig{}, time.Time{}, err
+	}
+	expiry, err := m.Expiry()
+	if err != nil {
+		return configtypes.AuthConfig{}, time.Time{}, err
+	}
+	return *authConfig, expiry, nil
+}
+
+// CredentialExpiryWarnings returns warnings for credentials that have expired,
+// or that expire within the CredentialExpiryWarningPeriod. Credentials that
+// are refreshed automatically are not included. If registries are given, only
+// warnings for the credentials of those registries are returned.
+func CredentialExpiryWarnings(cfg *configfile.ConfigFile, now time.Time, registries ...string) []string {
+	if len(registries) == 0 {
+		for reg := range cfg.CredentialExpiry {
+			registries = append(registries, reg)
+		}
+		sort.Strings(registries)
+	}
+
+	var warnings []string
+	for _, reg := range registries {
+		expiry := cfg.GetCredentialExpiry(reg)
+		if expiry.IsZero() || expiry.Sub(now) > CredentialExpiryWarningPeriod || credentialRefresher(cfg, reg) != nil {
+			continue
+		}
+		loginCmd := "docker login"
+		if reg != registry.IndexServer {
+			loginCmd += " " + reg
+		}
+		if expiry.After(now) {
+			warnings = append(warnings, fmt.Sprintf("WARNING: Credentials for %s expire in %s. Run '%s' to renew them.", reg, units.HumanDuration(expiry.Sub(now)), loginCmd))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("WARNING: Credentials for %s expired %s ago. Run '%s' to renew them.", reg, units.HumanDuration(now.Sub(expiry)), loginCmd))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	configtypes "github.com/docker/cli/cli/config/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type ctxKey struct{}
+
+func TestResolveAuthConfigRefreshesExpiringCredentials(t *testing.T) {
+	const registryHostname = "refresh.example.com"
+
+	var refreshed int
+	var refreshErr error
+	defer func(orig func(*configfile.ConfigFile, string) configfile.CredentialRefreshFunc) {
+		credentialRefresher = orig
+	}(credentialRefresher)
+	credentialRefresher = func(_ *configfile.ConfigFile, reg string) configfile.CredentialRefreshFunc {
+		if reg != registryHostname {
+			return nil
+		}
+		return func(ctx context.Context, _ credentials.Store) (configtypes.AuthConfig, time.Time, error) {
+			refreshed++
+			// The context of the command is used to refresh credentials.
+			assert.Check(t, is.Equal(ctx.Value(ctxKey{}), "command"))
+			if refreshErr != nil {
+				return configtypes.AuthConfig{}, time.Time{}, refreshErr
+			}
+			return configtypes.AuthConfig{Username: "user", Password: "new-password"}, time.Now().Add(time.Hour), nil
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "command")
+	index := &registrytypes.IndexInfo{Name: registryHostname}
+	setup := func(t *testing.T, expiry time.Time) *configfile.ConfigFile {
+		t.Helper()
+		cfg := configfile.New(filepath.Join(t.TempDir(), "config.json"))
+		cfg.AuthConfigs = map[string]configtypes.AuthConfig{
+			registryHostname: {Username: "user", Password: "old-password"},
+		}
+		cfg.SetCredentialExpiry(registryHostname, expiry)
+		refreshed = 0
+		refreshErr = nil
+		return cfg
+	}
+
+	t.Run("not expiring", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(time.Hour))
+		authConfig := ResolveAuthConfig(ctx, cfg, index)
+		assert.Check(t, is.Equal(authConfig.Password, "old-password"))
+		assert.Check(t, is.Equal(refreshed, 0))
+	})
+
+	t.Run("expiring", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(time.Minute))
+		authConfig := ResolveAuthConfig(ctx, cfg, index)
+		assert.Check(t, is.Equal(authConfig.Password, "new-password"))
+		assert.Check(t, is.Equal(refreshed, 1))
+		assert.Check(t, !cfg.NeedsRefresh(registryHostname))
+	})
+
+	t.Run("refresh fails", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(-time.Minute))
+		refreshErr = errors.New("refresh token expired")
+		authConfig := ResolveAuthConfig(ctx, cfg, index)
+		assert.Check(t, is.Equal(authConfig.Password, "old-password"))
+		assert.Check(t, is.Equal(refreshed, 1))
+	})
+
+	t.Run("default auth config", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(time.Minute))
+		authConfig, err := GetDefaultAuthConfig(ctx, cfg, true, registryHostname, false)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(authConfig.Password, "new-password"))
+		assert.Check(t, is.Equal(refreshed, 1))
+	})
+
+	t.Run("auth config by key", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(time.Minute))
+		authConfig, err := GetAuthConfig(ctx, cfg, registryHostname)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(authConfig.Password, "new-password"))
+		assert.Check(t, is.Equal(refreshed, 1))
+	})
+
+	t.Run("not refreshed when reading the configuration file", func(t *testing.T) {
+		cfg := setup(t, time.Now().Add(time.Minute))
+		authConfig, err := cfg.GetAuthConfig(registryHostname)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(authConfig.Password, "old-password"))
+		assert.Check(t, is.Equal(refreshed, 0))
+	})
+}
@@ -1,7 +1,9 @@
 package command_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/config/configfile"
@@ -61,7 +63,7 @@ func TestGetDefaultAuthConfig(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		serverAddress := tc.inputServerAddress
-		authconfig, err := command.GetDefaultAuthConfig(cfg, tc.checkCredStore, serverAddress, serverAddress == "https://index.docker.io/v1/")
+		authconfig, err := command.GetDefaultAuthConfig(context.Background(), cfg, tc.checkCredStore, serverAddress, serverAddress == "https://index.docker.io/v1/")
 		assert.NilError(t, err)
 		assert.Check(t, is.DeepEqual(tc.expectedAuthConfig, authconfig))
 	}
@@ -76,7 +78,94 @@ func TestGetDefaultAuthConfig_HelperError(t *testing.T) {
 		ServerAddress: serverAddress,
 	}
 	const isDefaultRegistry = false // registry is not "https://index.docker.io/v1/"
-	authconfig, err := command.GetDefaultAuthConfig(cfg, true, serverAddress, isDefaultRegistry)
+	authconfig, err := command.GetDefaultAuthConfig(context.Background(), cfg, true, serverAddress, isDefaultRegistry)
 	assert.Check(t, is.DeepEqual(expectedAuthConfig, authconfig))
 	assert.Check(t, is.ErrorContains(err, "docker-credential-fake-does-not-exist"))
 }
+
+func TestGetScopedAuthConfig(t *testing.T) {
+	cfg := configfile.New("filename")
+	assert.NilError(t, cfg.GetCredentialsStore("server1.io").Store(configtypes.AuthConfig{
+		ServerAddress: "server1.io",
+		Username:      "u1",
+		Password:      "p1",
+	}))
+
+	// credentials for the registry are not used for a repository path
+	authconfig, err := command.GetScopedAuthConfig(context.Background(), cfg, true, "server1.io/team-a/")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(authconfig, registry.AuthConfig{ServerAddress: "server1.io/team-a"}))
+
+	assert.NilError(t, cfg.GetCredentialsStore("server1.io/team-a").Store(configtypes.AuthConfig{
+		ServerAddress: "server1.io/team-a",
+		Username:      "team-a",
+		Password:      "team-a-password",
+	}))
+	authconfig, err = command.GetScopedAuthConfig(context.Background(), cfg, true, "server1.io/team-a")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(authconfig, registry.AuthConfig{
+		ServerAddress: "server1.io/team-a",
+		Username:      "team-a",
+		Password:      "team-a-password",
+	}))
+
+	// credentials scoped to a repository path are not used for the registry
+	authconfig, err = command.GetDefaultAuthConfig(context.Background(), cfg, true, "server1.io/team-a", false)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(authconfig, registry.AuthConfig{
+		ServerAddress: "server1.io",
+		Username:      "u1",
+		Password:      "p1",
+	}))
+}
+
+func TestResolveRepositoryAuthConfig(t *testing.T) {
+	cfg := configfile.New("filename")
+	cfg.AuthConfigs = map[string]configtypes.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hub-user"},
+		"docker.io/myorg":             {Username: "hub-org-user"},
+		"server1.io":                  {Username: "registry-user"},
+		"server1.io/team-a":           {Username: "team-a-user"},
+	}
+
+	testCases := []struct {
+		image        string
+		expectedUser string
+	}{
+		{image: "ubuntu", expectedUser: "hub-user"},
+		{image: "otherorg/app", expectedUser: "hub-user"},
+		{image: "myorg/app", expectedUser: "hub-org-user"},
+		{image: "server1.io/app:latest", expectedUser: "registry-user"},
+		{image: "server1.io/team-b/app", expectedUser: "registry-user"},
+		{image: "server1.io/team-a/app", expectedUser: "team-a-user"},
+		{image: "server1.io/team-a/nested/app@sha256:7173b809ca12ec5dee4506cd86be934c4596dd234ee82c0662eac04a8c2c71dc", expectedUser: "team-a-user"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			encodedAuth, err := command.RetrieveAuthTokenFromImage(context.Background(), cfg, tc.image)
+			assert.NilError(t, err)
+			authConfig, err := registry.DecodeAuthConfig(encodedAuth)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(authConfig.Username, tc.expectedUser))
+		})
+	}
+}
+
+func TestCredentialExpiryWarnings(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := configfile.New("filename")
+	cfg.SetCredentialExpiry("expired.example.com", now.Add(-2*time.Hour))
+	cfg.SetCredentialExpiry("expiring.example.com", now.Add(3*24*time.Hour))
+	cfg.SetCredentialExpiry("valid.example.com", now.Add(30*24*time.Hour))
+	// Docker Hub credentials are refreshed automatically.
+	cfg.SetCredentialExpiry("https://index.docker.io/v1/", now.Add(-time.Hour))
+
+	assert.Check(t, is.DeepEqual(command.CredentialExpiryWarnings(cfg, now), []string{
+		"WARNING: Credentials for expired.example.com expired 2 hours ago. Run 'docker login expired.example.com' to renew them.",
+		"WARNING: Credentials for expiring.example.com expire in 3 days. Run 'docker login expiring.example.com' to renew them.",
+	}))
+	assert.Check(t, is.DeepEqual(command.CredentialExpiryWarnings(cfg, now, "expiring.example.com"), []string{
+		"WARNING: Credentials for expiring.example.com expire in 3 days. Run 'docker login expiring.example.com' to renew them.",
+	}))
+	assert.Check(t, is.Len(command.CredentialExpiryWarnings(cfg, now, "unknown.example.com"), 0))
+}
@@ -127,14 +127,14 @@ func runCreate(ctx context.Context, dockerCLI command.Cli, flags *pflag.FlagSet,
 		return err
 	}
 
-	if err := resolveServiceImageDigestContentTrust(dockerCLI, &service); err != nil {
+	if err := resolveServiceImageDigestContentTrust(ctx, dockerCLI, &service); err != nil {
 		return err
 	}
 
 	// only send auth if flag was set
 	if opts.registryAuth {
 		// Retrieve encoded auth token from the image reference
-		encodedAuth, err := command.RetrieveAuthTokenFromImage(dockerCLI.ConfigFile(), opts.image)
+		encodedAuth, err := command.RetrieveAuthTokenFromImage(ctx, dockerCLI.ConfigFile(), opts.image)
 		if err != nil {
 			return err
 		}
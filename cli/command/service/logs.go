@@ -13,6 +13,7 @@
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
 	"github.com/docker/cli/cli/command/idresolver"
+	"github.com/docker/cli/cli/internal/logfilter"
 	"github.com/docker/cli/service/logs"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -36,6 +37,7 @@ type logsOptions struct {
 	tail       string
 	details    bool
 	raw        bool
+	logFilter  logfilter.Options
 
 	target string
 }
@@ -71,6 +73,7 @@ func newLogsCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVar(&opts.details, "details", false, "Show extra details provided to logs")
 	flags.SetAnnotation("details", "version", []string{"1.30"})
 	flags.StringVarP(&opts.tail, "tail", "n", "all", "Number of lines to show from the end of the logs")
+	opts.logFilter.InstallFlags(flags)
 
 	flags.VisitAll(func(flag *pflag.Flag) {
 		// Set a default completion function if none was set. We don't look
@@ -84,6 +87,23 @@ func newLogsCommand(dockerCli command.Cli) *cobra.Command {
 func runLogs(ctx context.Context, dockerCli command.Cli, opts *logsOptions) error {
 	apiClient := dockerCli.Client()
 
+	// Filtering and formatting options are applied to the output, after
+	// the logs are formatted, so that they apply to both the raw and the
+	// neatly formatted logs.
+	var stdout, stderr io.Writer = dockerCli.Out(), dockerCli.Err()
+	if opts.logFilter.Enabled() {
+		f, err := logfilter.New(opts.logFilter)
+		if err != nil {
+			return err
+		}
+		outW, errW := f.Writer(dockerCli.Out()), f.Writer(dockerCli.Err())
+		defer func() {
+			_ = outW.Flush()
+			_ = errW.Flush()
+		}()
+		stdout, stderr = outW, errW
+	}
+
 	var (
 		maxLength    = 1
 		responseBody io.ReadCloser
@@ -152,15 +172,12 @@ func runLogs(ctx context.Context, dockerCli command.Cli, opts *logsOptions) erro
 
 	// tty logs get straight copied. they're not muxed with stdcopy
 	if tty {
-		_, err = io.Copy(dockerCli.Out(), responseBody)
+		_, err = io.Copy(stdout, responseBody)
 		return err
 	}
 
 	// otherwise, logs are multiplexed. if we're doing pretty printing, also
 	// create a task formatter.
-	var stdout, stderr io.Writer
-	stdout = dockerCli.Out()
-	stderr = dockerCli.Err()
 	if !opts.raw {
 		taskFormatter := newTaskFormatter(apiClient, opts, maxLength)
 
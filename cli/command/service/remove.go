@@ -17,6 +17,9 @@ func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
 		Short:   "Remove one or more services",
 		Args:    cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, true); err != nil {
+				return err
+			}
 			return runRemove(cmd.Context(), dockerCli, args)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
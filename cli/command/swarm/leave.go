@@ -22,6 +22,9 @@ func newLeaveCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Leave the swarm",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runLeave(cmd.Context(), dockerCli, opts)
 		},
 		Annotations: map[string]string{
@@ -21,6 +21,7 @@ type fakeClient struct {
 	version            string
 	containerListFunc  func(context.Context, container.ListOptions) ([]container.Summary, error)
 	containerPruneFunc func(ctx context.Context, pruneFilters filters.Args) (container.PruneReport, error)
+	diskUsageFunc      func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
 	eventsFn           func(context.Context, events.ListOptions) (<-chan events.Message, <-chan error)
 	imageListFunc      func(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
 	infoFunc           func(ctx context.Context) (system.Info, error)
@@ -49,6 +50,13 @@ func (cli *fakeClient) ContainersPrune(ctx context.Context, pruneFilters filters
 	return container.PruneReport{}, nil
 }
 
+func (cli *fakeClient) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	if cli.diskUsageFunc != nil {
+		return cli.diskUsageFunc(ctx, options)
+	}
+	return types.DiskUsage{}, nil
+}
+
 func (cli *fakeClient) Events(ctx context.Context, opts events.ListOptions) (<-chan events.Message, <-chan error) {
 	return cli.eventsFn(ctx, opts)
 }
@@ -0,0 +1,239 @@
+package system
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/docker/cli/cli/internal/oauth/manager"
+	"github.com/docker/docker/registry"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultCredentialsTableFormat = "table {{.Registry}}\t{{.Stores}}\t{{.AnsweredBy}}\t{{.Warnings}}"
+
+	registryHeader   = "REGISTRY"
+	storesHeader     = "STORES"
+	answeredByHeader = "ANSWERED BY"
+	warningsHeader   = "WARNINGS"
+
+	// fileStoreName is the name used for the credentials that are stored in
+	// the configuration file.
+	fileStoreName = "file"
+)
+
+// Status of a credentials store for a registry.
+const (
+	storeStatusOK            = "ok"
+	storeStatusNoCredentials = "no credentials"
+	storeStatusMissing       = "missing"
+	storeStatusError         = "error"
+)
+
+type credentialsOptions struct {
+	registries []string
+	format     string
+}
+
+func newCredentialsCommand(dockerCli command.Cli) *cobra.Command {
+	var opts credentialsOptions
+
+	cmd := &cobra.Command{
+		Use:   "credentials [OPTIONS]",
+		Short: "Show the credential stores that are used for each registry",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCredentials(dockerCli, opts)
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&opts.registries, "registry", nil, "Also show the credential stores for the given registries")
+	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+
+	cmd.AddCommand(newMigrateCredentialsCommand(dockerCli))
+	return cmd
+}
+
+// storeStatus is the status of a credentials store for a registry.
+type storeStatus struct {
+	name   string
+	status string
+}
+
+// registryCredentials describes which credentials stores are consulted for
+// a registry, and which of them has credentials for it. It never holds the
+// credentials themselves.
+type registryCredentials struct {
+	registry   string
+	stores     []storeStatus
+	answeredBy string
+	warnings   []string
+}
+
+func runCredentials(dockerCli command.Cli, opts credentialsOptions) error {
+	configFile := dockerCli.ConfigFile()
+
+	registries := make(map[string]struct{})
+	for reg := range configFile.GetAuthConfigs() {
+		registries[reg] = struct{}{}
+	}
+	for reg := range configFile.CredentialHelpers {
+		registries[reg] = struct{}{}
+	}
+	if len(configFile.GetCredentialHelpers("")) > 0 {
+		auths, err := configFile.GetCredentialsStore("").GetAll()
+		if err != nil {
+			_, _ = fmt.Fprintf(dockerCli.Err(), "WARNING: unable to list the registries in the default credentials store: %v\n", firstLine(err.Error()))
+		}
+		for reg := range auths {
+			registries[reg] = struct{}{}
+		}
+	}
+	// OAuth tokens are stored next to the credentials, but aren't the
+	// credentials of a registry.
+	for reg := range registries {
+		if manager.IsTokenKey(reg) {
+			delete(registries, reg)
+		}
+	}
+	for _, reg := range opts.registries {
+		registries[reg] = struct{}{}
+	}
+	if len(registries) == 0 {
+		registries[registry.IndexServer] = struct{}{}
+	}
+
+	names := make([]string, 0, len(registries))
+	for reg := range registries {
+		names = append(names, reg)
+	}
+	sort.Strings(names)
+
+	result := make([]registryCredentials, 0, len(names))
+	for _, reg := range names {
+		result = append(result, checkRegistryCredentials(configFile, reg))
+	}
+
+	format := opts.format
+	if len(format) == 0 {
+		format = formatter.TableFormatKey
+	}
+	return credentialsFormatWrite(formatter.Context{
+		Output: dockerCli.Out(),
+		Format: newCredentialsFormat(format),
+	}, result)
+}
+
+// checkRegistryCredentials checks each credentials store that is consulted
+// for the given registry.
+func checkRegistryCredentials(configFile *configfile.ConfigFile, reg string) registryCredentials {
+	rc := registryCredentials{registry: reg}
+	plain := configFile.GetAuthConfigs()[reg]
+	hasPlaintext := plain.Username != "" || plain.Password != "" || plain.IdentityToken != ""
+
+	helpers := configFile.GetCredentialHelpers(reg)
+	if len(helpers) == 0 {
+		st := storeStatus{name: fileStoreName, status: storeStatusNoCredentials}
+		if hasPlaintext {
+			st.status = storeStatusOK
+			rc.answeredBy = fileStoreName
+			rc.warnings = append(rc.warnings, "credentials are stored unencrypted in the configuration file")
+		}
+		rc.stores = append(rc.stores, st)
+		return rc
+	}
+
+	for _, helper := range helpers {
+		check := credentials.CheckHelper(helper, reg)
+		st := storeStatus{name: helper}
+		switch {
+		case check.Path == "":
+			st.status = storeStatusMissing
+			rc.warnings = append(rc.warnings, fmt.Sprintf("docker-credential-%s was not found in PATH", helper))
+		case check.Err != nil:
+			st.status = storeStatusError
+			rc.warnings = append(rc.warnings, fmt.Sprintf("docker-credential-%s: %s", helper, firstLine(check.Err.Error())))
+		case check.Found:
+			st.status = storeStatusOK
+			if rc.answeredBy == "" {
+				rc.answeredBy = helper
+			}
+		default:
+			st.status = storeStatusNoCredentials
+		}
+		rc.stores = append(rc.stores, st)
+	}
+	if hasPlaintext {
+		rc.warnings = append(rc.warnings, "credentials in the configuration file are ignored, because a credential helper is configured")
+	}
+	return rc
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func newCredentialsFormat(source string) formatter.Format {
+	if source == formatter.TableFormatKey {
+		return defaultCredentialsTableFormat
+	}
+	return formatter.Format(source)
+}
+
+func credentialsFormatWrite(ctx formatter.Context, result []registryCredentials) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, rc := range result {
+			if err := format(&credentialsContext{rc: rc}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	credsCtx := credentialsContext{}
+	credsCtx.Header = formatter.SubHeaderContext{
+		"Registry":   registryHeader,
+		"Stores":     storesHeader,
+		"AnsweredBy": answeredByHeader,
+		"Warnings":   warningsHeader,
+	}
+	return ctx.Write(&credsCtx, render)
+}
+
+type credentialsContext struct {
+	formatter.HeaderContext
+	rc registryCredentials
+}
+
+func (c *credentialsContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *credentialsContext) Registry() string {
+	return c.rc.registry
+}
+
+func (c *credentialsContext) Stores() string {
+	stores := make([]string, 0, len(c.rc.stores))
+	for _, st := range c.rc.stores {
+		stores = append(stores, st.name+" ("+st.status+")")
+	}
+	return strings.Join(stores, ", ")
+}
+
+func (c *credentialsContext) AnsweredBy() string {
+	return c.rc.answeredBy
+}
+
+func (c *credentialsContext) Warnings() string {
+	return strings.Join(c.rc.warnings, "; ")
+}
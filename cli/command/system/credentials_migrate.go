@@ -0,0 +1,147 @@
+package system
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type migrateCredentialsOptions struct {
+	dryRun bool
+}
+
+func newMigrateCredentialsCommand(dockerCli command.Cli) *cobra.Command {
+	var opts migrateCredentialsOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate [OPTIONS]",
+		Short: "Move credentials from the configuration file into credential helpers",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateCredentials(dockerCli, opts)
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Show the credentials that would be moved, without moving them")
+	return cmd
+}
+
+// scratchStore is an in-memory replacement for the configuration file, used
+// as file store by the native stores that credentials are migrated to, so
+// that the configuration file is not saved before all credentials are
+// migrated.
+type scratchStore struct {
+	configs map[string]types.AuthConfig
+}
+
+func (s *scratchStore) GetAuthConfigs() map[string]types.AuthConfig {
+	return s.configs
+}
+
+func (*scratchStore) Save() error {
+	return nil
+}
+
+func (*scratchStore) GetFilename() string {
+	return ""
+}
+
+// runMigrateCredentials moves the credentials that are stored in the
+// configuration file into the credential helper that is configured for
+// each registry. Credentials are only removed from the configuration file
+// once all credentials are stored in, and read back from their credential
+// helper.
+//
+// Note that the credentials are read from the configuration file directly,
+// and not through ConfigFile.GetAllCredentials, which ignores credentials
+// in the configuration file when a default credential store is configured.
+func runMigrateCredentials(dockerCli command.Cli, opts migrateCredentialsOptions) error {
+	configFile := dockerCli.ConfigFile()
+	if len(configFile.GetCredentialHelpers("")) == 0 && len(configFile.CredentialHelpers) == 0 {
+		return errors.Errorf(`no credential helper is configured: set "credsStore", "credsStores", or "credHelpers" in %s`, configFile.Filename)
+	}
+
+	var registries []string
+	for reg, ac := range configFile.GetAuthConfigs() {
+		if ac.Username != "" || ac.Password != "" || ac.IdentityToken != "" {
+			registries = append(registries, reg)
+		}
+	}
+	sort.Strings(registries)
+
+	type migration struct {
+		registry string
+		helper   string
+		auth     types.AuthConfig
+	}
+	var migrations []migration
+	for _, reg := range registries {
+		helpers := configFile.GetCredentialHelpers(reg)
+		if len(helpers) == 0 {
+			_, _ = fmt.Fprintf(dockerCli.Err(), "Skipping %s: no credential helper is configured for this registry\n", reg)
+			continue
+		}
+		auth := configFile.GetAuthConfigs()[reg]
+		auth.ServerAddress = reg
+		migrations = append(migrations, migration{registry: reg, helper: helpers[0], auth: auth})
+	}
+	if len(migrations) == 0 {
+		_, _ = fmt.Fprintln(dockerCli.Out(), "No credentials to migrate")
+		return nil
+	}
+
+	if opts.dryRun {
+		for _, m := range migrations {
+			_, _ = fmt.Fprintf(dockerCli.Out(), "Would move credentials for %s to docker-credential-%s\n", m.registry, m.helper)
+		}
+		return nil
+	}
+
+	for _, m := range migrations {
+		store := credentials.NewNativeStore(&scratchStore{configs: make(map[string]types.AuthConfig)}, m.helper)
+		if err := store.Store(m.auth); err != nil {
+			return errors.Wrapf(err, "failed to store credentials for %s in docker-credential-%s; no credentials were removed from %s", m.registry, m.helper, configFile.Filename)
+		}
+		stored, err := store.Get(m.registry)
+		if err == nil && !sameCredentials(m.auth, stored) {
+			err = errors.New("credentials read back do not match")
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify credentials for %s in docker-credential-%s; no credentials were removed from %s", m.registry, m.helper, configFile.Filename)
+		}
+		_, _ = fmt.Fprintf(dockerCli.Out(), "Moved credentials for %s to docker-credential-%s\n", m.registry, m.helper)
+	}
+
+	auths := configFile.GetAuthConfigs()
+	for _, m := range migrations {
+		ac := auths[m.registry]
+		ac.Username = ""
+		ac.Password = ""
+		ac.Auth = ""
+		ac.IdentityToken = ""
+		auths[m.registry] = ac
+	}
+	if err := configFile.Save(); err != nil {
+		return errors.Wrap(err, "failed to remove credentials from the configuration file")
+	}
+	_, _ = fmt.Fprintf(dockerCli.Out(), "Removed %d credentials from %s\n", len(migrations), configFile.Filename)
+	return nil
+}
+
+// sameCredentials returns whether the credentials read back from a native
+// store match the credentials that were stored. Native stores do not keep
+// the username of credentials that use an identity token.
+func sameCredentials(stored, got types.AuthConfig) bool {
+	if stored.IdentityToken != "" {
+		return got.IdentityToken == stored.IdentityToken
+	}
+	return got.Username == stored.Username && got.Password == stored.Password
+}
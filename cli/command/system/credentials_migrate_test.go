@@ -0,0 +1,130 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// storingHelper is a credential helper that stores credentials in files next
+// to it. It does not store anything if it is named "docker-credential-lossy".
+const storingHelper = `#!/bin/sh
+dir="$(dirname "$0")/store"
+mkdir -p "$dir"
+case "$1" in
+store)
+	creds="$(cat)"
+	server="$(echo "$creds" | sed -n 's/.*"ServerURL":"\([^"]*\)".*/\1/p' | tr -c 'a-zA-Z0-9\n' '_')"
+	if [ "$(basename "$0")" != "docker-credential-lossy" ]; then
+		echo "$creds" > "$dir/$server"
+	fi
+	;;
+get)
+	server="$(cat | tr -c 'a-zA-Z0-9\n' '_')"
+	if [ -f "$dir/$server" ]; then
+		cat "$dir/$server"
+		exit 0
+	fi
+	echo "credentials not found in native keychain"
+	exit 1
+	;;
+esac
+`
+
+func setupMigrateCredentials(t *testing.T, credsStore string) (*test.FakeCli, *configfile.ConfigFile) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a shell script credential helper")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"docker-credential-fake", "docker-credential-lossy"} {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(storingHelper), 0o755))
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configFile := configfile.New(filepath.Join(t.TempDir(), "config.json"))
+	configFile.CredentialsStore = credsStore
+	configFile.CredentialHelpers = map[string]string{"file.example.com": ""}
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "password", Email: "user@example.com"},
+		"token.example.com":    {Username: "user", IdentityToken: "token"},
+		"file.example.com":     {Username: "user", Password: "password"},
+		"empty.example.com":    {},
+	}
+	assert.NilError(t, configFile.Save())
+
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetConfigFile(configFile)
+	return cli, configFile
+}
+
+func TestMigrateCredentials(t *testing.T) {
+	cli, configFile := setupMigrateCredentials(t, "fake")
+	assert.NilError(t, runMigrateCredentials(cli, migrateCredentialsOptions{}))
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), `Moved credentials for registry.example.com to docker-credential-fake
+Moved credentials for token.example.com to docker-credential-fake
+Removed 2 credentials from `+configFile.Filename+"\n"))
+	assert.Check(t, is.Equal(cli.ErrBuffer().String(), "Skipping file.example.com: no credential helper is configured for this registry\n"))
+
+	saved := configfile.New(configFile.Filename)
+	f, err := os.Open(configFile.Filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	assert.NilError(t, saved.LoadFromReader(f))
+	assert.Check(t, is.DeepEqual(saved.AuthConfigs["registry.example.com"], types.AuthConfig{Email: "user@example.com", ServerAddress: "registry.example.com"}))
+	assert.Check(t, is.DeepEqual(saved.AuthConfigs["token.example.com"], types.AuthConfig{ServerAddress: "token.example.com"}))
+	assert.Check(t, is.Equal(saved.AuthConfigs["file.example.com"].Password, "password"))
+
+	auth, err := saved.GetAuthConfig("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "user"))
+	assert.Check(t, is.Equal(auth.Password, "password"))
+	auth, err = saved.GetAuthConfig("token.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.IdentityToken, "token"))
+}
+
+func TestMigrateCredentialsDryRun(t *testing.T) {
+	cli, configFile := setupMigrateCredentials(t, "fake")
+	before, err := os.ReadFile(configFile.Filename)
+	assert.NilError(t, err)
+
+	assert.NilError(t, runMigrateCredentials(cli, migrateCredentialsOptions{dryRun: true}))
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), `Would move credentials for registry.example.com to docker-credential-fake
+Would move credentials for token.example.com to docker-credential-fake
+`))
+
+	after, err := os.ReadFile(configFile.Filename)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(after), string(before)))
+	auth, err := configFile.GetCredentialsStore("registry.example.com").Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, ""), "credentials should not be stored in the credential helper")
+}
+
+func TestMigrateCredentialsVerifyFails(t *testing.T) {
+	cli, configFile := setupMigrateCredentials(t, "lossy")
+	before, err := os.ReadFile(configFile.Filename)
+	assert.NilError(t, err)
+
+	err = runMigrateCredentials(cli, migrateCredentialsOptions{})
+	assert.Check(t, is.ErrorContains(err, "failed to verify credentials for registry.example.com in docker-credential-lossy"))
+	assert.Check(t, is.ErrorContains(err, "no credentials were removed"))
+
+	after, err := os.ReadFile(configFile.Filename)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(after), string(before)))
+}
+
+func TestMigrateCredentialsNoHelper(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{})
+	err := runMigrateCredentials(cli, migrateCredentialsOptions{})
+	assert.Check(t, is.ErrorContains(err, "no credential helper is configured"))
+}
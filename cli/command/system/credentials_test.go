@@ -0,0 +1,77 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/golden"
+)
+
+// fakeHelper is a credential helper that has credentials for
+// registry.example.com only.
+const fakeHelper = `#!/bin/sh
+read server
+case "$1" in
+get)
+	if [ "$server" = "registry.example.com" ]; then
+		echo '{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}'
+		exit 0
+	fi
+	echo "credentials not found in native keychain"
+	exit 1
+	;;
+list)
+	echo '{"registry.example.com":"user"}'
+	;;
+esac
+`
+
+func TestCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a shell script credential helper")
+	}
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(fakeHelper), 0o755))
+	t.Setenv("PATH", dir)
+
+	configFile := configfile.New("")
+	configFile.CredentialsStores = []string{"missing", "fake"}
+	configFile.CredentialHelpers = map[string]string{
+		"other.example.com": "fake",
+	}
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"other.example.com": {Username: "user", Password: "password"},
+	}
+
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetConfigFile(configFile)
+	assert.NilError(t, runCredentials(cli, credentialsOptions{registries: []string{"plain.example.com", "registry.example.com"}}))
+	golden.Assert(t, cli.OutBuffer().String(), "credentials.golden")
+	assert.Check(t, !strings.Contains(cli.OutBuffer().String(), "secret"))
+	// Credential helpers that are not installed are skipped when listing.
+	assert.Check(t, is.Equal(cli.ErrBuffer().String(), ""))
+}
+
+func TestCredentialsFileStore(t *testing.T) {
+	configFile := configfile.New("")
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "password"},
+		// OAuth tokens are not listed as registries.
+		"registry.example.com/_oauth/access-token":  {Username: "user", Password: "access-token"},
+		"registry.example.com/_oauth/refresh-token": {Username: "user", Password: "refresh-token"},
+		"https://index.docker.io/v1/refresh-token":  {Username: "user", Password: "refresh-token"},
+	}
+
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetConfigFile(configFile)
+	assert.NilError(t, runCredentials(cli, credentialsOptions{format: "{{.Registry}}: {{.AnsweredBy}}: {{.Warnings}}"}))
+	assert.Equal(t, cli.OutBuffer().String(), "registry.example.com: file: credentials are stored unencrypted in the configuration file\n")
+}
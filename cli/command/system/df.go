@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
@@ -9,12 +10,15 @@
 	"github.com/docker/cli/cli/command/formatter"
 	flagsHelper "github.com/docker/cli/cli/flags"
 	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type diskUsageOptions struct {
 	verbose bool
 	format  string
+	record  bool
+	trend   bool
 }
 
 // newDiskUsageCommand creates a new cobra.Command for `docker df`
@@ -36,11 +40,33 @@ func newDiskUsageCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "Show detailed information on space usage")
 	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+	flags.BoolVar(&opts.record, "record", false, "Record a snapshot of the disk usage in the local history")
+	flags.BoolVar(&opts.trend, "trend", false, "Show the growth of disk usage over the recorded history")
 
 	return cmd
 }
 
 func runDiskUsage(ctx context.Context, dockerCli command.Cli, opts diskUsageOptions) error {
+	if opts.trend {
+		if opts.verbose || opts.format != "" {
+			return errors.New("--trend cannot be combined with --verbose or --format")
+		}
+		if opts.record {
+			du, err := dockerCli.Client().DiskUsage(ctx, types.DiskUsageOptions{})
+			if err != nil {
+				return err
+			}
+			if err := recordDiskUsage(dockerCli, newDiskUsageSnapshot(du, time.Now())); err != nil {
+				return err
+			}
+		}
+		snapshots, err := readDiskUsageHistory(dockerCli)
+		if err != nil {
+			return err
+		}
+		return writeDiskUsageTrend(dockerCli.Out(), snapshots)
+	}
+
 	// TODO expose types.DiskUsageOptions.Types as flag on the command-line and/or as separate commands (docker container df / docker container usage)
 	du, err := dockerCli.Client().DiskUsage(ctx, types.DiskUsageOptions{})
 	if err != nil {
@@ -73,5 +99,11 @@ func runDiskUsage(ctx context.Context, dockerCli command.Cli, opts diskUsageOpti
 		Verbose:     opts.verbose,
 	}
 
-	return duCtx.Write()
+	if err := duCtx.Write(); err != nil {
+		return err
+	}
+	if opts.record {
+		return recordDiskUsage(dockerCli, newDiskUsageSnapshot(du, time.Now()))
+	}
+	return nil
 }
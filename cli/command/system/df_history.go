@@ -0,0 +1,300 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter/tabwriter"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/atomicwriter"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+// dfHistoryDir is the directory, relative to the config directory, in which
+// disk usage snapshots are stored, using a file per context.
+const dfHistoryDir = "df-history"
+
+// topGrowing is the number of volumes and images to show in the trend.
+const topGrowing = 5
+
+// Snapshots that are older than dfHistoryMaxAge are removed from the history
+// when recording a snapshot, as well as the oldest snapshots if there are
+// more than dfHistoryMaxEntries.
+const (
+	dfHistoryMaxAge     = 90 * 24 * time.Hour
+	dfHistoryMaxEntries = 1000
+)
+
+// diskUsageSnapshot is a snapshot of the disk usage, as recorded by
+// "docker system df --record".
+type diskUsageSnapshot struct {
+	Time       time.Time
+	Images     int64
+	Containers int64
+	Volumes    int64
+	BuildCache int64
+
+	// VolumeSizes are the sizes of volumes, by name.
+	VolumeSizes map[string]int64 `json:",omitempty"`
+
+	// ImageSizes are the sizes of images that are not shared with other
+	// images, by name (or ID for untagged images).
+	ImageSizes map[string]int64 `json:",omitempty"`
+}
+
+func newDiskUsageSnapshot(du types.DiskUsage, now time.Time) diskUsageSnapshot {
+	s := diskUsageSnapshot{
+		Time:        now.UTC(),
+		Images:      du.LayersSize,
+		VolumeSizes: make(map[string]int64),
+		ImageSizes:  make(map[string]int64),
+	}
+	for _, c := range du.Containers {
+		s.Containers += c.SizeRw
+	}
+	for _, v := range du.Volumes {
+		if v.UsageData != nil && v.UsageData.Size >= 0 {
+			s.Volumes += v.UsageData.Size
+			s.VolumeSizes[v.Name] = v.UsageData.Size
+		}
+	}
+	for _, img := range du.Images {
+		name := img.ID
+		for _, t := range img.RepoTags {
+			if t != "<none>:<none>" {
+				name = t
+				break
+			}
+		}
+		size := img.Size
+		if img.SharedSize > 0 {
+			size -= img.SharedSize
+		}
+		s.ImageSizes[name] = size
+	}
+	for _, bc := range du.BuildCache {
+		if !bc.Shared {
+			s.BuildCache += bc.Size
+		}
+	}
+	return s
+}
+
+// dfHistoryPath returns the path of the file in which the disk usage
+// snapshots of the current context are stored.
+func dfHistoryPath(dockerCli command.Cli) string {
+	name := command.EffectiveContext(dockerCli)
+	if name == "" {
+		name = command.DefaultContextName
+	}
+	return filepath.Join(config.Dir(), dfHistoryDir, name+".json")
+}
+
+// recordDiskUsage appends a snapshot to the history of the current context,
+// and removes old snapshots from the history.
+func recordDiskUsage(dockerCli command.Cli, s diskUsageSnapshot) error {
+	snapshots, err := readDiskUsageHistory(dockerCli)
+	if err != nil {
+		return errors.Wrap(err, "failed to record disk usage")
+	}
+	snapshots = capDiskUsageHistory(append(snapshots, s), s.Time)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, snapshot := range snapshots {
+		if err := enc.Encode(snapshot); err != nil {
+			return errors.Wrap(err, "failed to record disk usage")
+		}
+	}
+	path := dfHistoryPath(dockerCli)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.Wrap(err, "failed to record disk usage")
+	}
+	if err := atomicwriter.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return errors.Wrap(err, "failed to record disk usage")
+	}
+	return nil
+}
+
+// capDiskUsageHistory orders the snapshots by time, and removes the snapshots
+// that are older than dfHistoryMaxAge, and the oldest snapshots if there are
+// more than dfHistoryMaxEntries.
+func capDiskUsageHistory(snapshots []diskUsageSnapshot, now time.Time) []diskUsageSnapshot {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	cutoff := now.Add(-dfHistoryMaxAge)
+	first := sort.Search(len(snapshots), func(i int) bool {
+		return !snapshots[i].Time.Before(cutoff)
+	})
+	snapshots = snapshots[first:]
+	if len(snapshots) > dfHistoryMaxEntries {
+		snapshots = snapshots[len(snapshots)-dfHistoryMaxEntries:]
+	}
+	return snapshots
+}
+
+// readDiskUsageHistory reads the snapshots of the current context, ordered
+// by time.
+func readDiskUsageHistory(dockerCli command.Cli) ([]diskUsageSnapshot, error) {
+	f, err := os.Open(dfHistoryPath(dockerCli))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var snapshots []diskUsageSnapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var s diskUsageSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, errors.Wrapf(err, "invalid disk usage history: line %d", n)
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// growth is the change in size of a category or object between the first
+// and last snapshot it appears in.
+type growth struct {
+	name        string
+	first, last int64
+	days        float64
+}
+
+func (g growth) change() int64 {
+	return g.last - g.first
+}
+
+func (g growth) perDay() string {
+	if g.days <= 0 {
+		return "-"
+	}
+	return signedSize(float64(g.change()) / g.days)
+}
+
+// topGrowth returns the objects that grew the most, based on the sizes
+// recorded in each snapshot.
+func topGrowth(snapshots []diskUsageSnapshot, sizes func(diskUsageSnapshot) map[string]int64) []growth {
+	byName := make(map[string]*growth)
+	firstSeen := make(map[string]time.Time)
+	for _, s := range snapshots {
+		for name, size := range sizes(s) {
+			g, ok := byName[name]
+			if !ok {
+				g = &growth{name: name, first: size}
+				byName[name] = g
+				firstSeen[name] = s.Time
+			}
+			g.last = size
+			g.days = s.Time.Sub(firstSeen[name]).Hours() / 24
+		}
+	}
+
+	var result []growth
+	for _, g := range byName {
+		if g.change() > 0 {
+			result = append(result, *g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].change() != result[j].change() {
+			return result[i].change() > result[j].change()
+		}
+		return result[i].name < result[j].name
+	})
+	if len(result) > topGrowing {
+		result = result[:topGrowing]
+	}
+	return result
+}
+
+// writeDiskUsageTrend writes the growth of each category between the first
+// and last snapshot, and the volumes and images that grew the most.
+func writeDiskUsageTrend(out io.Writer, snapshots []diskUsageSnapshot) error {
+	if len(snapshots) < 2 {
+		return errors.New("at least two disk usage snapshots are needed to show a trend; use --record to record snapshots")
+	}
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	days := last.Time.Sub(first.Time).Hours() / 24
+
+	_, _ = fmt.Fprintf(out, "%d snapshots from %s to %s\n\n", len(snapshots), first.Time.Format(time.RFC3339), last.Time.Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(out, 10, 1, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TYPE\tFIRST\tLAST\tCHANGE\tPER DAY")
+	for _, g := range []growth{
+		{name: "Images", first: first.Images, last: last.Images, days: days},
+		{name: "Containers", first: first.Containers, last: last.Containers, days: days},
+		{name: "Local Volumes", first: first.Volumes, last: last.Volumes, days: days},
+		{name: "Build Cache", first: first.BuildCache, last: last.BuildCache, days: days},
+	} {
+		writeGrowth(w, g)
+	}
+
+	sections := []struct {
+		header string
+		sizes  func(diskUsageSnapshot) map[string]int64
+	}{
+		{"VOLUME NAME", func(s diskUsageSnapshot) map[string]int64 { return s.VolumeSizes }},
+		{"IMAGE", func(s diskUsageSnapshot) map[string]int64 { return s.ImageSizes }},
+	}
+	for _, section := range sections {
+		top := topGrowth(snapshots, section.sizes)
+		if len(top) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, section.header+"\tFIRST\tLAST\tCHANGE\tPER DAY")
+		for _, g := range top {
+			writeGrowth(w, g)
+		}
+	}
+	return w.Flush()
+}
+
+func writeGrowth(w io.Writer, g growth) {
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+		g.name,
+		units.HumanSize(float64(g.first)),
+		units.HumanSize(float64(g.last)),
+		signedSize(float64(g.change())),
+		g.perDay(),
+	)
+}
+
+// signedSize formats a size, prefixed with its sign.
+func signedSize(size float64) string {
+	switch {
+	case size > 0:
+		return "+" + units.HumanSize(size)
+	case size < 0:
+		return "-" + units.HumanSize(-size)
+	default:
+		return "0B"
+	}
+}
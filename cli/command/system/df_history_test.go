@@ -0,0 +1,90 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/golden"
+)
+
+func TestDiskUsageRecord(t *testing.T) {
+	config.SetDir(t.TempDir())
+	cli := test.NewFakeCli(&fakeClient{
+		diskUsageFunc: func(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				LayersSize: 1000,
+				Volumes:    []*volume.Volume{{Name: "data", UsageData: &volume.UsageData{Size: 200}}},
+			}, nil
+		},
+	})
+	assert.NilError(t, runDiskUsage(context.Background(), cli, diskUsageOptions{record: true}))
+	assert.NilError(t, runDiskUsage(context.Background(), cli, diskUsageOptions{record: true}))
+
+	_, err := os.Stat(filepath.Join(config.Dir(), dfHistoryDir, "default.json"))
+	assert.NilError(t, err)
+
+	snapshots, err := readDiskUsageHistory(cli)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(snapshots, 2))
+	assert.Check(t, is.Equal(snapshots[1].Images, int64(1000)))
+	assert.Check(t, is.DeepEqual(snapshots[1].VolumeSizes, map[string]int64{"data": 200}))
+}
+
+func TestCapDiskUsageHistory(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	snapshots := []diskUsageSnapshot{
+		{Time: now.Add(-dfHistoryMaxAge + time.Hour), Images: 2},
+		{Time: now.Add(-dfHistoryMaxAge - time.Hour), Images: 1},
+		{Time: now, Images: 3},
+	}
+	snapshots = capDiskUsageHistory(snapshots, now)
+	assert.Assert(t, is.Len(snapshots, 2))
+	assert.Check(t, is.Equal(snapshots[0].Images, int64(2)))
+	assert.Check(t, is.Equal(snapshots[1].Images, int64(3)))
+
+	snapshots = nil
+	for i := 0; i < dfHistoryMaxEntries+10; i++ {
+		snapshots = append(snapshots, diskUsageSnapshot{Time: now.Add(time.Duration(i) * time.Minute), Images: int64(i)})
+	}
+	snapshots = capDiskUsageHistory(snapshots, now)
+	assert.Assert(t, is.Len(snapshots, dfHistoryMaxEntries))
+	assert.Check(t, is.Equal(snapshots[0].Images, int64(10)))
+}
+
+func TestDiskUsageTrend(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var snapshots []diskUsageSnapshot
+	for day := 0; day <= 2; day++ {
+		du := types.DiskUsage{
+			LayersSize: 1_000_000,
+			Images: []*image.Summary{
+				{ID: "sha256:aaaa", RepoTags: []string{"app:latest"}, Size: int64(100_000 + day*50_000)},
+				{ID: "sha256:bbbb", Size: 10_000},
+			},
+			Volumes: []*volume.Volume{
+				{Name: "logs", UsageData: &volume.UsageData{Size: int64(day) * 2_000_000_000}},
+				{Name: "db", UsageData: &volume.UsageData{Size: int64(500_000_000 + day*1_000_000)}},
+				{Name: "cache", UsageData: &volume.UsageData{Size: int64(300_000_000 - day*1_000_000)}},
+			},
+		}
+		snapshots = append(snapshots, newDiskUsageSnapshot(du, start.Add(time.Duration(day)*24*time.Hour)))
+	}
+
+	cli := test.NewFakeCli(&fakeClient{})
+	assert.NilError(t, writeDiskUsageTrend(cli.OutBuffer(), snapshots))
+	golden.Assert(t, cli.OutBuffer().String(), "df-trend.golden")
+
+	err := writeDiskUsageTrend(cli.OutBuffer(), snapshots[:1])
+	assert.Check(t, is.ErrorContains(err, "at least two disk usage snapshots are needed"))
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -17,14 +18,23 @@
 	"github.com/docker/cli/opts"
 	"github.com/docker/cli/templates"
 	"github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type eventsOptions struct {
-	since  string
-	until  string
-	filter opts.FilterOpt
-	format string
+	since          string
+	until          string
+	filter         opts.FilterOpt
+	filterExpr     string
+	format         string
+	outputFile     string
+	outputMaxSize  opts.MemBytes
+	outputMaxFiles int
+	exec           string
+	execParallel   int
+	execTimeout    time.Duration
+	rootCmd        *cobra.Command
 }
 
 // NewEventsCommand creates a new cobra.Command for `docker events`
@@ -36,6 +46,7 @@ func NewEventsCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Get real time events from the server",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			options.rootCmd = cmd.Root()
 			return runEvents(cmd.Context(), dockerCli, &options)
 		},
 		Annotations: map[string]string{
@@ -48,7 +59,14 @@ func NewEventsCommand(dockerCli command.Cli) *cobra.Command {
 	flags.StringVar(&options.since, "since", "", "Show all events created since timestamp")
 	flags.StringVar(&options.until, "until", "", "Stream events until this timestamp")
 	flags.VarP(&options.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.StringVar(&options.filterExpr, "filter-expr", "", `Filter output using an expression (e.g. "type=container && action=die && attr.exitCode!=0")`)
 	flags.StringVar(&options.format, "format", "", flagsHelper.InspectFormatHelp) // using the same flag description as "inspect" commands for now.
+	flags.StringVar(&options.outputFile, "output-file", "", "Write events to a file instead of STDOUT")
+	flags.Var(&options.outputMaxSize, "output-max-size", "Rotate the output file when it reaches the given size (0 to disable)")
+	flags.IntVar(&options.outputMaxFiles, "output-max-files", 5, "Number of rotated output files to keep")
+	flags.StringVar(&options.exec, "exec", "", "Run a command for each event, with the event as JSON on STDIN")
+	flags.IntVar(&options.execParallel, "exec-concurrency", 1, "Maximum number of --exec commands to run concurrently")
+	flags.DurationVar(&options.execTimeout, "exec-timeout", 0, "Timeout for each --exec command (0 for no timeout)")
 
 	_ = cmd.RegisterFlagCompletionFunc("filter", completeEventFilters(dockerCli))
 
@@ -63,6 +81,32 @@ func runEvents(ctx context.Context, dockerCli command.Cli, options *eventsOption
 			Status:     "Error parsing format: " + err.Error(),
 		}
 	}
+	var expr eventExpr
+	if options.filterExpr != "" {
+		expr, err = parseEventExpr(options.filterExpr)
+		if err != nil {
+			return errors.Wrap(err, "invalid --filter-expr")
+		}
+	}
+
+	var out io.Writer = dockerCli.Out()
+	if options.outputFile != "" {
+		f, err := openRotatingFile(options.outputFile, options.outputMaxSize.Value(), options.outputMaxFiles)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var hook *eventHook
+	if options.exec != "" {
+		hook, err = newEventHook(dockerCli, options.rootCmd, options.exec, options.execParallel, options.execTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	evts, errs := dockerCli.Client().Events(ctx, events.ListOptions{
 		Since:   options.since,
@@ -70,15 +114,35 @@ func runEvents(ctx context.Context, dockerCli command.Cli, options *eventsOption
 		Filters: options.filter.Value(),
 	})
 	defer cancel()
+	if hook != nil {
+		// Wait for running commands before cancelling the context, which
+		// would terminate them.
+		defer hook.wait()
+	}
 
-	out := dockerCli.Out()
-
+	// Events are rendered to a buffer first, so that each event is written
+	// to the output in a single write.
+	var buf bytes.Buffer
 	for {
 		select {
 		case event := <-evts:
-			if err := handleEvent(out, event, tmpl); err != nil {
+			if expr != nil && !expr.match(event) {
+				continue
+			}
+			buf.Reset()
+			if err := handleEvent(&buf, event, tmpl); err != nil {
+				return err
+			}
+			if hook == nil {
+				if _, err := out.Write(buf.Bytes()); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := hook.write(out, buf.Bytes()); err != nil {
 				return err
 			}
+			hook.run(ctx, event)
 		case err := <-errs:
 			if err == io.EOF {
 				return nil
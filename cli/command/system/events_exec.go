@@ -0,0 +1,172 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/templates"
+	"github.com/docker/docker/api/types/events"
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// eventHook runs a command for each event, passing the event as JSON on
+// STDIN. The arguments of the command are templates that are executed with
+// the event. Commands in the form "docker <plugin> ..." invoke the CLI plugin
+// directly.
+type eventHook struct {
+	dockerCli command.Cli
+	rootCmd   *cobra.Command
+	args      []*template.Template
+	timeout   time.Duration
+	slots     chan struct{}
+	wg        sync.WaitGroup
+
+	// mu serializes writing the output of commands (and events).
+	mu sync.Mutex
+}
+
+func newEventHook(dockerCli command.Cli, rootCmd *cobra.Command, cmdline string, concurrency int, timeout time.Duration) (*eventHook, error) {
+	if concurrency < 1 {
+		return nil, errors.New("--exec-concurrency must be at least 1")
+	}
+	if timeout < 0 {
+		return nil, errors.New("--exec-timeout cannot be negative")
+	}
+	words, err := shlex.Split(cmdline)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --exec command")
+	}
+	if len(words) == 0 {
+		return nil, errors.New("invalid --exec command: no command specified")
+	}
+	h := &eventHook{
+		dockerCli: dockerCli,
+		rootCmd:   rootCmd,
+		timeout:   timeout,
+		slots:     make(chan struct{}, concurrency),
+	}
+	for _, w := range words {
+		tmpl, err := templates.Parse(w)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid --exec command: %q", w)
+		}
+		h.args = append(h.args, tmpl)
+	}
+	return h, nil
+}
+
+// run runs the command for the given event in the background. It blocks
+// until fewer than the maximum number of commands are running, so that events
+// are not dropped when commands are slow.
+func (h *eventHook) run(ctx context.Context, event events.Message) {
+	args := make([]string, 0, len(h.args))
+	for _, tmpl := range h.args {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, event); err != nil {
+			h.warn(event, err)
+			return
+		}
+		args = append(args, buf.String())
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		h.warn(event, err)
+		return
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	h.wg.Add(1)
+	go func() {
+		defer func() {
+			<-h.slots
+			h.wg.Done()
+		}()
+		h.exec(ctx, event, args, payload)
+	}()
+}
+
+func (h *eventHook) exec(ctx context.Context, event events.Message, args []string, payload []byte) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	cmd, err := h.command(ctx, args)
+	if err != nil {
+		h.warn(event, err)
+		return
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = errors.Errorf("timed out after %s", h.timeout)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, _ = h.dockerCli.Out().Write(stdout.Bytes())
+	_, _ = h.dockerCli.Err().Write(stderr.Bytes())
+	if err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+		_, _ = fmt.Fprintf(h.dockerCli.Err(), "Failed to run %q for %s %s event of %s: %v\n", strings.Join(args, " "), event.Type, event.Action, event.Actor.ID, err)
+	}
+}
+
+// command returns the command to run. Commands in the form "docker <plugin>"
+// run the CLI plugin directly, instead of through the docker CLI, in the same
+// way as the docker CLI runs the plugin.
+func (h *eventHook) command(ctx context.Context, args []string) (*exec.Cmd, error) {
+	if len(args) > 1 && args[0] == "docker" && h.rootCmd != nil {
+		pluginCmd, err := pluginmanager.PluginRunCommand(h.dockerCli, args[1], h.rootCmd)
+		switch {
+		case err == nil:
+			// The plugin command is created with the arguments of the
+			// current invocation; use the arguments of the hook instead.
+			cmd := exec.CommandContext(ctx, pluginCmd.Path, args[1:]...) // #nosec G204 -- the command is provided by the user
+			cmd.Env = pluginCmd.Env
+			return cmd, nil
+		case !pluginmanager.IsNotFound(err):
+			return nil, err
+		}
+	}
+	return exec.CommandContext(ctx, args[0], args[1:]...), nil // #nosec G204 -- the command is provided by the user
+}
+
+// write writes the output of an event, without interleaving it with the
+// output of commands.
+func (h *eventHook) write(out io.Writer, p []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := out.Write(p)
+	return err
+}
+
+func (h *eventHook) warn(event events.Message, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, _ = fmt.Fprintf(h.dockerCli.Err(), "Failed to run --exec command for %s %s event of %s: %v\n", event.Type, event.Action, event.Actor.ID, err)
+}
+
+// wait waits for running commands to complete.
+func (h *eventHook) wait() {
+	h.wg.Wait()
+}
@@ -0,0 +1,278 @@
+package system
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
+)
+
+// eventExpr is a boolean expression that is evaluated against events, for
+// example:
+//
+//	type=container && action=die && attr.exitCode!=0
+//
+// Expressions compare a field with a value using "=" (or "=="), "!=", "=~"
+// (regular expression), or "!~", and are combined using "&&", "||", "!" and
+// parentheses. The fields are "type", "action", "scope", "id", and
+// "attr.<name>" for the attributes of the actor. Values that contain spaces,
+// or special characters must be quoted.
+type eventExpr interface {
+	match(events.Message) bool
+}
+
+type andExpr struct{ left, right eventExpr }
+
+func (e andExpr) match(m events.Message) bool { return e.left.match(m) && e.right.match(m) }
+
+type orExpr struct{ left, right eventExpr }
+
+func (e orExpr) match(m events.Message) bool { return e.left.match(m) || e.right.match(m) }
+
+type notExpr struct{ expr eventExpr }
+
+func (e notExpr) match(m events.Message) bool { return !e.expr.match(m) }
+
+type compareExpr struct {
+	field  string
+	negate bool
+	value  string
+	re     *regexp.Regexp
+}
+
+func (e compareExpr) match(m events.Message) bool {
+	v := eventField(m, e.field)
+	var ok bool
+	if e.re != nil {
+		ok = e.re.MatchString(v)
+	} else {
+		ok = v == e.value
+	}
+	return ok != e.negate
+}
+
+// eventField returns the value of a field of an event. Attributes that are not
+// set have an empty value.
+func eventField(m events.Message, field string) string {
+	switch field {
+	case "type":
+		return string(m.Type)
+	case "action":
+		return string(m.Action)
+	case "scope":
+		return m.Scope
+	case "id":
+		return m.Actor.ID
+	default:
+		return m.Actor.Attributes[strings.TrimPrefix(field, "attr.")]
+	}
+}
+
+// parseEventExpr parses a filter expression.
+func parseEventExpr(s string) (eventExpr, error) {
+	tokens, err := tokenizeEventExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, errors.Errorf("unexpected %q", t.text)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var exprOperators = []string{"&&", "||", "==", "!=", "=~", "!~", "=", "!", "(", ")"}
+
+func tokenizeEventExpr(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(s) && s[end] != c {
+				if s[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, errors.New("unterminated quoted string")
+			}
+			text := s[i+1 : end]
+			if c == '"' {
+				var err error
+				if text, err = strconv.Unquote(s[i : end+1]); err != nil {
+					return nil, errors.Errorf("invalid quoted string: %s", s[i:end+1])
+				}
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: text})
+			i = end + 1
+			continue
+		}
+		if op := matchOperator(s[i:]); op != "" {
+			tokens = append(tokens, token{kind: tokenOp, text: op})
+			i += len(op)
+			continue
+		}
+		end := i
+		for end < len(s) && !unicode.IsSpace(rune(s[end])) && matchOperator(s[end:]) == "" && s[end] != '"' && s[end] != '\'' {
+			end++
+		}
+		tokens = append(tokens, token{kind: tokenWord, text: s[i:end]})
+		i = end
+	}
+	return tokens, nil
+}
+
+func matchOperator(s string) string {
+	for _, op := range exprOperators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *exprParser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+func (p *exprParser) acceptOp(op string) bool {
+	if t, ok := p.peek(); ok && t.kind == tokenOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (eventExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (eventExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (eventExpr, error) {
+	if p.acceptOp("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	if p.acceptOp("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptOp(")") {
+			return nil, errors.New(`missing ")"`)
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (eventExpr, error) {
+	field, ok := p.next()
+	if !ok {
+		return nil, errors.New("unexpected end of expression")
+	}
+	if field.kind != tokenWord {
+		return nil, errors.Errorf("unexpected %q", field.text)
+	}
+	switch {
+	case field.text == "type", field.text == "action", field.text == "scope", field.text == "id":
+	case strings.HasPrefix(field.text, "attr.") && len(field.text) > len("attr."):
+	default:
+		return nil, errors.Errorf(`invalid field %q: must be "type", "action", "scope", "id", or "attr.<name>"`, field.text)
+	}
+
+	op, ok := p.next()
+	if !ok || op.kind != tokenOp {
+		return nil, errors.Errorf("expected an operator after %q", field.text)
+	}
+	value, ok := p.next()
+	if !ok || value.kind != tokenWord {
+		return nil, errors.Errorf("expected a value after %q", field.text+op.text)
+	}
+
+	expr := compareExpr{field: field.text, value: value.text}
+	switch op.text {
+	case "=", "==":
+	case "!=":
+		expr.negate = true
+	case "=~", "!~":
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression %q", value.text)
+		}
+		expr.re = re
+		expr.negate = op.text == "!~"
+	default:
+		return nil, errors.Errorf("unexpected %q after %q", op.text, field.text)
+	}
+	return expr, nil
+}
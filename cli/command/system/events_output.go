@@ -0,0 +1,84 @@
+package system
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// rotatingFile is a file that is rotated when it reaches a maximum size. The
+// current file is renamed to "<path>.1", the previous "<path>.1" is renamed
+// to "<path>.2", and so on, keeping at most maxFiles rotated files.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	f    *os.File
+	size int64
+}
+
+// openRotatingFile opens (or creates) the file at path for appending. Files
+// are not rotated if maxSize is zero.
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if maxSize < 0 {
+		return nil, errors.New("maximum size of the output file cannot be negative")
+	}
+	if maxFiles < 1 {
+		return nil, errors.New("number of output files to keep must be at least 1")
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open output file")
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "failed to open output file")
+	}
+	r.f, r.size = f, st.Size()
+	return nil
+}
+
+// Write writes p to the file. The file is rotated before writing if writing
+// p would exceed the maximum size, so that p is never split across files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to rotate output file")
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return errors.Wrap(err, "failed to rotate output file")
+	}
+	return r.open()
+}
+
+// Close closes the file.
+func (r *rotatingFile) Close() error {
+	return r.f.Close()
+}
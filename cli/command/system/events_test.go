@@ -2,15 +2,22 @@
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/internal/test"
 	"github.com/docker/docker/api/types/events"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
 	"gotest.tools/v3/golden"
 )
 
@@ -81,3 +88,218 @@ func TestEventsFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestEventExpr(t *testing.T) {
+	die := events.Message{
+		Type:   events.ContainerEventType,
+		Action: events.ActionDie,
+		Scope:  "local",
+		Actor: events.Actor{
+			ID:         "abc123",
+			Attributes: map[string]string{"exitCode": "137", "name": "web", "com.example.team": "shop"},
+		},
+	}
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: `type=container && action=die && attr.exitCode!=0`, expected: true},
+		{expr: `type=container && action=die && attr.exitCode==0`, expected: false},
+		{expr: `type=network || action=die`, expected: true},
+		{expr: `!(type=container)`, expected: false},
+		{expr: `type=image || (action=die && attr.com.example.team=shop)`, expected: true},
+		{expr: `attr.name=~"^we" && attr.missing=""`, expected: true},
+		{expr: `id!~abc`, expected: false},
+		{expr: `action="exec_start: sh"`, expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := parseEventExpr(tc.expr)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(expr.match(die), tc.expected))
+		})
+	}
+}
+
+func TestEventExprInvalid(t *testing.T) {
+	for expr, expected := range map[string]string{
+		`type=`:                   `expected a value after "type="`,
+		`type=container &&`:       "unexpected end of expression",
+		`(type=container`:         `missing ")"`,
+		`status=die`:              `invalid field "status"`,
+		`type=container action`:   `unexpected "action"`,
+		`attr.name=~"("`:          "invalid regular expression",
+		`attr.name="unterminated`: "unterminated quoted string",
+	} {
+		_, err := parseEventExpr(expr)
+		assert.Check(t, is.ErrorContains(err, expected), expr)
+	}
+}
+
+func TestEventsOutputFile(t *testing.T) {
+	var evts []events.Message
+	for i := 0; i < 6; i++ {
+		evts = append(evts, events.Message{
+			Type:     events.ContainerEventType,
+			Action:   events.ActionDie,
+			Actor:    events.Actor{ID: fmt.Sprintf("container-%d", i), Attributes: map[string]string{"exitCode": fmt.Sprint(i % 2)}},
+			TimeNano: int64(time.Second) * int64(i+1),
+		})
+	}
+	cli := test.NewFakeCli(&fakeClient{eventsFn: func(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+		messages := make(chan events.Message)
+		errs := make(chan error, 1)
+		go func() {
+			for _, msg := range evts {
+				messages <- msg
+			}
+			errs <- io.EOF
+		}()
+		return messages, errs
+	}})
+
+	path := filepath.Join(t.TempDir(), "events.log")
+	cmd := NewEventsCommand(cli)
+	cmd.SetArgs([]string{
+		"--filter-expr", "attr.exitCode!=0",
+		"--format", "{{.Actor.ID}}",
+		"--output-file", path,
+		"--output-max-size", "24",
+		"--output-max-files", "1",
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), ""))
+
+	// Each line is 12 bytes, so each file holds two events, and only a
+	// single rotated file is kept.
+	current, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(current), "container-5\n"))
+	rotated, err := os.ReadFile(path + ".1")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(rotated), "container-1\ncontainer-3\n"))
+	_, err = os.Stat(path + ".2")
+	assert.Check(t, os.IsNotExist(err))
+}
+
+func TestEventsExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	evts := []events.Message{
+		{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{ID: "container-1"}, TimeNano: 1},
+		{Type: events.ContainerEventType, Action: events.ActionDie, Actor: events.Actor{ID: "container-2"}, TimeNano: 2},
+	}
+	cli := test.NewFakeCli(&fakeClient{eventsFn: func(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+		messages := make(chan events.Message)
+		errs := make(chan error, 1)
+		go func() {
+			for _, msg := range evts {
+				messages <- msg
+			}
+			errs <- io.EOF
+		}()
+		return messages, errs
+	}})
+
+	cmd := NewEventsCommand(cli)
+	cmd.SetArgs([]string{
+		"--output-file", filepath.Join(t.TempDir(), "events.log"),
+		"--exec", `sh -c 'echo "{{.Action}} $(cat)"'`,
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.ErrBuffer().String(), ""))
+
+	expected := ""
+	for _, evt := range evts {
+		payload, err := json.Marshal(evt)
+		assert.NilError(t, err)
+		expected += string(evt.Action) + " " + string(payload) + "\n"
+	}
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+}
+
+func TestEventsExecPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	pluginDir := fs.NewDir(t, "plugins",
+		fs.WithFile("docker-hook", `#!/bin/sh
+if [ "$1" = "docker-cli-plugin-metadata" ]; then
+	echo '{"SchemaVersion":"0.1.0","Vendor":"e2e-testing","Permissions":{}}'
+	exit 0
+fi
+echo "$@ ${SECRET_TOKEN:-unset} $(cat)"
+`, fs.WithMode(0o777)),
+	)
+	t.Setenv("SECRET_TOKEN", "secret")
+
+	evt := events.Message{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{ID: "container-1"}}
+	cli := test.NewFakeCli(&fakeClient{eventsFn: func(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+		messages := make(chan events.Message)
+		errs := make(chan error, 1)
+		go func() {
+			messages <- evt
+			errs <- io.EOF
+		}()
+		return messages, errs
+	}})
+	cli.SetConfigFile(&configfile.ConfigFile{CLIPluginsExtraDirs: []string{pluginDir.Path()}})
+
+	cmd := NewEventsCommand(cli)
+	cmd.SetArgs([]string{"--output-file", filepath.Join(t.TempDir(), "events.log"), "--exec", "docker hook {{.Action}}"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.ErrBuffer().String(), ""))
+
+	// The plugin is run in the same way as by the docker CLI, so it does not
+	// get environment variables it did not ask for.
+	payload, err := json.Marshal(evt)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "hook start unset "+string(payload)+"\n"))
+}
+
+func TestEventsExecTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+	cli := test.NewFakeCli(&fakeClient{eventsFn: func(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+		messages := make(chan events.Message)
+		errs := make(chan error, 1)
+		go func() {
+			messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{ID: "container-1"}}
+			errs <- io.EOF
+		}()
+		return messages, errs
+	}})
+
+	cmd := NewEventsCommand(cli)
+	cmd.SetArgs([]string{"--format", "{{.Actor.ID}}", "--exec", "sleep 10", "--exec-timeout", "100ms"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "container-1\n"))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), `Failed to run "sleep 10" for container start event of container-1: timed out after 100ms`))
+}
+
+func TestEventsExecInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"--exec", "echo '"}, expected: "invalid --exec command"},
+		{args: []string{"--exec", "echo {{.Foo"}, expected: "invalid --exec command"},
+		{args: []string{"--exec", "echo", "--exec-concurrency", "0"}, expected: "--exec-concurrency must be at least 1"},
+	} {
+		cmd := NewEventsCommand(test.NewFakeCli(&fakeClient{}))
+		cmd.SetArgs(tc.args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		assert.Check(t, is.ErrorContains(cmd.Execute(), tc.expected))
+	}
+}
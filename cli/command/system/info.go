@@ -11,6 +11,7 @@
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli"
 	pluginmanager "github.com/docker/cli/cli-plugins/manager"
@@ -96,6 +97,7 @@ func runInfo(ctx context.Context, cmd *cobra.Command, dockerCli command.Cli, opt
 	} else {
 		info.ClientErrors = append(info.ClientErrors, err.Error())
 	}
+	info.ClientInfo.Warnings = append(info.ClientInfo.Warnings, command.CredentialExpiryWarnings(dockerCli.ConfigFile(), time.Now())...)
 
 	if needsServerInfo(opts.format, info) {
 		if dinfo, err := dockerCli.Client().Info(ctx); err == nil {
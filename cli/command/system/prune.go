@@ -15,6 +15,7 @@
 	"github.com/docker/cli/cli/command/image"
 	"github.com/docker/cli/cli/command/network"
 	"github.com/docker/cli/cli/command/volume"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/errdefs"
@@ -30,6 +31,8 @@ type pruneOptions struct {
 	pruneVolumes    bool
 	pruneBuildCache bool
 	filter          opts.FilterOpt
+	policy          string
+	dryRun          prune.DryRunOptions
 }
 
 // newPruneCommand creates a new cobra.Command for `docker prune`
@@ -55,6 +58,8 @@ func newPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "label=<key>=<value>")`)
 	// "filter" flag is available in 1.28 (docker 17.04) and up
 	flags.SetAnnotation("filter", "version", []string{"1.28"})
+	flags.StringVar(&options.policy, "policy", "", "Prune using a named prune policy from the configuration file")
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -76,6 +81,19 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	if options.pruneVolumes && options.filter.Value().Contains("until") {
 		return errors.New(`ERROR: The "until" filter is not supported with "--volumes"`)
 	}
+	if err := options.dryRun.Validate(); err != nil {
+		return err
+	}
+	if !options.dryRun.Enabled {
+		confirmed, err := command.ConfirmProtectedContext(ctx, dockerCli, options.force)
+		if err != nil {
+			return err
+		}
+		options.force = options.force || confirmed
+	}
+	if options.policy != "" || options.dryRun.Enabled {
+		return runPlannedPrune(ctx, dockerCli, options)
+	}
 	if !options.force {
 		r, err := command.PromptForConfirmation(ctx, dockerCli.In(), dockerCli.Out(), confirmationMessage(dockerCli, options))
 		if err != nil {
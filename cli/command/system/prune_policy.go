@@ -0,0 +1,130 @@
+package system
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/internal/prune"
+	"github.com/docker/cli/opts"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+// deletedHeaders are the headers printed before the objects of each type
+// that were removed, matching the output of the individual prune commands.
+var deletedHeaders = map[prune.Type]string{
+	prune.TypeContainer:  "Deleted Containers:",
+	prune.TypeNetwork:    "Deleted Networks:",
+	prune.TypeVolume:     "Deleted Volumes:",
+	prune.TypeImage:      "Deleted Images:",
+	prune.TypeBuildCache: "Deleted build cache objects:",
+}
+
+// runPlannedPrune determines the objects to remove on the client side, so
+// that they can be listed (--dry-run), or counted before pruning them using a
+// prune policy from the configuration file (--policy).
+func runPlannedPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	policy, err := prunePolicy(dockerCli, options)
+	if err != nil {
+		return err
+	}
+	apiClient := dockerCli.Client()
+	candidates, err := prune.Plan(ctx, apiClient, policy)
+	if err != nil {
+		return err
+	}
+
+	if options.dryRun.Enabled {
+		return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+	}
+
+	if len(candidates) > 0 && !options.force {
+		msg := fmt.Sprintf("WARNING! This will remove %d objects (%s) as defined by prune policy %q.\nUse --dry-run to list them.\nAre you sure you want to continue?",
+			len(candidates), units.HumanSize(float64(prune.TotalSize(candidates))), options.policy)
+		r, err := command.PromptForConfirmation(ctx, dockerCli.In(), dockerCli.Out(), msg)
+		if err != nil {
+			return err
+		}
+		if !r {
+			return errdefs.Cancelled(errors.New("system prune has been cancelled"))
+		}
+	}
+
+	reports, err := prune.Prune(ctx, apiClient, policy, candidates)
+	var spaceReclaimed uint64
+	for _, r := range reports {
+		_, _ = fmt.Fprintln(dockerCli.Out(), deletedHeaders[r.Type])
+		for _, d := range r.Deleted {
+			_, _ = fmt.Fprintln(dockerCli.Out(), d)
+		}
+		_, _ = fmt.Fprintln(dockerCli.Out())
+		spaceReclaimed += r.SpaceReclaimed
+	}
+	_, _ = fmt.Fprintln(dockerCli.Out(), "Total reclaimed space:", units.HumanSize(float64(spaceReclaimed)))
+	return err
+}
+
+// prunePolicy returns the prune policy with the given name from the
+// configuration file, or a policy equivalent to the command-line options if
+// no policy is given. The default prune filters from the configuration file
+// are applied to all object types.
+func prunePolicy(dockerCli command.Cli, options pruneOptions) (prune.Policy, error) {
+	if options.policy == "" {
+		pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
+		policy := prune.Policy{
+			Containers: &prune.Rule{Filters: pruneFilters},
+			Networks:   &prune.Rule{Filters: pruneFilters},
+			Images:     &prune.Rule{Filters: pruneFilters, All: options.all},
+		}
+		if options.pruneVolumes {
+			policy.Volumes = &prune.Rule{Filters: pruneFilters}
+		}
+		if options.pruneBuildCache {
+			policy.BuildCache = &prune.Rule{Filters: pruneFilters, All: options.all}
+		}
+		return policy, nil
+	}
+
+	if options.all || options.pruneVolumes || options.filter.Value().Len() > 0 {
+		return prune.Policy{}, errors.New("--policy cannot be combined with --all, --volumes, or --filter")
+	}
+	var p configfile.PrunePolicy
+	var ok bool
+	if cfg := dockerCli.ConfigFile(); cfg != nil {
+		p, ok = cfg.PrunePolicies[options.policy]
+	}
+	if !ok {
+		return prune.Policy{}, errors.Errorf("prune policy %q not found in the configuration file", options.policy)
+	}
+
+	var policy prune.Policy
+	for _, r := range []struct {
+		rule   *configfile.PruneRule
+		target **prune.Rule
+	}{
+		{p.Containers, &policy.Containers},
+		{p.Networks, &policy.Networks},
+		{p.Volumes, &policy.Volumes},
+		{p.Images, &policy.Images},
+		{p.BuildCache, &policy.BuildCache},
+	} {
+		if r.rule == nil {
+			continue
+		}
+		f := opts.NewFilterOpt()
+		for _, v := range r.rule.Filters {
+			if err := f.Set(v); err != nil {
+				return prune.Policy{}, errors.Wrapf(err, "invalid prune policy %q", options.policy)
+			}
+		}
+		*r.target = &prune.Rule{
+			Filters:  command.PruneFilters(dockerCli, f.Value()),
+			All:      r.rule.All,
+			KeepLast: r.rule.KeepLast,
+		}
+	}
+	return policy, nil
+}
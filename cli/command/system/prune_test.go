@@ -8,8 +8,10 @@
 
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -79,3 +81,57 @@ func TestSystemPrunePromptTermination(t *testing.T) {
 	cmd.SetErr(io.Discard)
 	test.TerminatePrompt(ctx, t, cmd, cli)
 }
+
+func TestPrunePolicyDryRun(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		version: "1.47",
+		diskUsageFunc: func(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				Containers: []*container.Summary{
+					{ID: "0123456789abcdef", Names: []string{"/web"}, State: "running", ImageID: "sha256:2222222222222222"},
+				},
+				Images: []*image.Summary{
+					{ID: "sha256:1111111111111111", RepoTags: []string{"app:v1"}, Created: 1, Size: 2048},
+					{ID: "sha256:2222222222222222", RepoTags: []string{"app:v2"}, Created: 2, Size: 2048},
+					{ID: "sha256:3333333333333333", RepoTags: []string{"app:v3"}, Created: 3, Size: 2048},
+				},
+			}, nil
+		},
+		containerPruneFunc: func(context.Context, filters.Args) (container.PruneReport, error) {
+			return container.PruneReport{}, errors.New("fakeClient containerPruneFunc should not be called")
+		},
+	})
+	cli.SetConfigFile(&configfile.ConfigFile{
+		PrunePolicies: map[string]configfile.PrunePolicy{
+			"ci": {Images: &configfile.PruneRule{KeepLast: 1}},
+		},
+	})
+	cmd := newPruneCommand(cli)
+	cmd.SetArgs([]string{"--policy", "ci", "--dry-run"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expected := `TYPE      ID             NAME      SIZE
+image     111111111111   app:v1    2.048kB
+Total reclaimable space: 2.048kB
+`
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+}
+
+func TestPrunePolicyErrors(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{version: "1.47"})
+	cli.SetConfigFile(&configfile.ConfigFile{})
+
+	cmd := newPruneCommand(cli)
+	cmd.SetArgs([]string{"--policy", "nope", "--dry-run"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), `prune policy "nope" not found in the configuration file`))
+
+	cmd = newPruneCommand(cli)
+	cmd.SetArgs([]string{"--policy", "nope", "--all"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), "--policy cannot be combined with --all, --volumes, or --filter"))
+}
@@ -35,7 +35,7 @@ func newRevokeCommand(dockerCLI command.Cli) *cobra.Command {
 }
 
 func revokeTrust(ctx context.Context, dockerCLI command.Cli, remote string, options revokeOptions) error {
-	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, image.AuthResolver(dockerCLI), remote)
+	imgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, image.RepositoryAuthResolver(dockerCLI), remote)
 	if err != nil {
 		return err
 	}
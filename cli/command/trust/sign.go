@@ -44,7 +44,7 @@ func newSignCommand(dockerCLI command.Cli) *cobra.Command {
 
 func runSignImage(ctx context.Context, dockerCLI command.Cli, options signOptions) error {
 	imageName := options.imageName
-	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, image.AuthResolver(dockerCLI), imageName)
+	imgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, image.RepositoryAuthResolver(dockerCLI), imageName)
 	if err != nil {
 		return err
 	}
@@ -93,7 +93,7 @@ func runSignImage(ctx context.Context, dockerCLI command.Cli, options signOption
 			}
 			_, _ = fmt.Fprintf(dockerCLI.Err(), "Signing and pushing trust data for local image %s, may overwrite remote trust data\n", imageName)
 
-			authConfig := command.ResolveAuthConfig(dockerCLI.ConfigFile(), imgRefAndAuth.RepoInfo().Index)
+			authConfig := command.ResolveRepositoryAuthConfig(ctx, dockerCLI.ConfigFile(), imgRefAndAuth.RepoInfo())
 			encodedAuth, err := registrytypes.EncodeAuthConfig(authConfig)
 			if err != nil {
 				return err
@@ -80,7 +80,7 @@ func addSigner(ctx context.Context, dockerCLI command.Cli, options signerAddOpti
 }
 
 func addSignerToRepo(ctx context.Context, dockerCLI command.Cli, signerName string, repoName string, signerPubKeys []data.PublicKey) error {
-	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, image.AuthResolver(dockerCLI), repoName)
+	imgRefAndAuth, err := trust.GetImageReferencesAndRepositoryAuth(ctx, image.RepositoryAuthResolver(dockerCLI), repoName)
 	if err != nil {
 		return err
 	}
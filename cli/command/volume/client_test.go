@@ -3,6 +3,7 @@
 import (
 	"context"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
@@ -15,6 +16,7 @@ type fakeClient struct {
 	volumeListFunc    func(filter filters.Args) (volume.ListResponse, error)
 	volumeRemoveFunc  func(volumeID string, force bool) error
 	volumePruneFunc   func(filter filters.Args) (volume.PruneReport, error)
+	diskUsageFunc     func(options types.DiskUsageOptions) (types.DiskUsage, error)
 }
 
 func (c *fakeClient) VolumeCreate(_ context.Context, options volume.CreateOptions) (volume.Volume, error) {
@@ -51,3 +53,10 @@ func (c *fakeClient) VolumeRemove(_ context.Context, volumeID string, force bool
 	}
 	return nil
 }
+
+func (c *fakeClient) DiskUsage(_ context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	if c.diskUsageFunc != nil {
+		return c.diskUsageFunc(options)
+	}
+	return types.DiskUsage{}, nil
+}
@@ -7,6 +7,7 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/internal/prune"
 	"github.com/docker/cli/opts"
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/errdefs"
@@ -19,6 +20,7 @@ type pruneOptions struct {
 	all    bool
 	force  bool
 	filter opts.FilterOpt
+	dryRun prune.DryRunOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for volumes
@@ -30,6 +32,17 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Remove unused local volumes",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.dryRun.Validate(); err != nil {
+				return err
+			}
+			if options.dryRun.Enabled {
+				return runPruneDryRun(cmd.Context(), dockerCli, options)
+			}
+			confirmed, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, options.force)
+			if err != nil {
+				return err
+			}
+			options.force = options.force || confirmed
 			spaceReclaimed, output, err := runPrune(cmd.Context(), dockerCli, options)
 			if err != nil {
 				return err
@@ -49,6 +62,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags.SetAnnotation("all", "version", []string{"1.42"})
 	flags.BoolVarP(&options.force, "force", "f", false, "Do not prompt for confirmation")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "label=<label>")`)
+	options.dryRun.InstallFlags(flags)
 
 	return cmd
 }
@@ -102,6 +116,24 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return spaceReclaimed, output, nil
 }
 
+// runPruneDryRun lists the volumes that would be removed, without removing
+// them.
+func runPruneDryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) error {
+	pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
+	if options.all && pruneFilters.Contains("all") {
+		return errdefs.InvalidParameter(errors.New("conflicting options: cannot specify both --all and --filter all=1"))
+	}
+	// API < v1.42 removes all volumes (anonymous and named) by default.
+	all := options.all || versions.LessThan(dockerCli.CurrentVersion(), "1.42")
+	candidates, err := prune.Plan(ctx, dockerCli.Client(), prune.Policy{
+		Volumes: &prune.Rule{Filters: pruneFilters, All: all},
+	})
+	if err != nil {
+		return err
+	}
+	return prune.WriteDryRun(dockerCli.Out(), options.dryRun.Format, candidates)
+}
+
 // RunPrune calls the Volume Prune API
 // This returns the amount of space reclaimed and a detailed output string
 func RunPrune(ctx context.Context, dockerCli command.Cli, _ bool, filter opts.FilterOpt) (uint64, string, error) {
@@ -11,7 +11,10 @@
 
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -206,3 +209,57 @@ func TestVolumePrunePromptTerminate(t *testing.T) {
 	test.TerminatePrompt(ctx, t, cmd, cli)
 	golden.Assert(t, cli.OutBuffer().String(), "volume-prune-terminate.golden")
 }
+
+func TestVolumePruneDryRun(t *testing.T) {
+	du := types.DiskUsage{
+		Containers: []*container.Summary{
+			{ID: "running", State: "running", Mounts: []container.MountPoint{{Type: mount.TypeVolume, Name: "in-use"}}},
+		},
+		Volumes: []*volume.Volume{
+			{Name: "in-use", Labels: map[string]string{"com.docker.volume.anonymous": ""}, UsageData: &volume.UsageData{Size: 100}},
+			{Name: "anonymous", Labels: map[string]string{"com.docker.volume.anonymous": ""}, UsageData: &volume.UsageData{Size: 2048}},
+			{Name: "named", UsageData: &volume.UsageData{Size: 1024}},
+		},
+	}
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "anonymous",
+			args: []string{"--dry-run"},
+			expected: `TYPE      ID          NAME      SIZE
+volume    anonymous             2.048kB
+Total reclaimable space: 2.048kB
+`,
+		},
+		{
+			name: "all",
+			args: []string{"--dry-run", "--all"},
+			expected: `TYPE      ID          NAME      SIZE
+volume    anonymous             2.048kB
+volume    named       named     1.024kB
+Total reclaimable space: 3.072kB
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{
+				diskUsageFunc: func(types.DiskUsageOptions) (types.DiskUsage, error) {
+					return du, nil
+				},
+				volumePruneFunc: func(filters.Args) (volume.PruneReport, error) {
+					return volume.PruneReport{}, errors.New("fakeClient volumePruneFunc should not be called")
+				},
+			})
+			cmd := NewPruneCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			assert.NilError(t, cmd.Execute())
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+		})
+	}
+}
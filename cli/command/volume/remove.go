@@ -28,6 +28,9 @@ func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
 		Args:    cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.volumes = args
+			if _, err := command.ConfirmProtectedContext(cmd.Context(), dockerCli, opts.force); err != nil {
+				return err
+			}
 			return runRemove(cmd.Context(), dockerCli, &opts)
 		},
 		ValidArgsFunction: completion.VolumeNames(dockerCli),
@@ -24,6 +24,13 @@
 	// command line option.
 	EnvOverrideConfigDir = "DOCKER_CONFIG"
 
+	// EnvOverrideSystemContextDirs is the name of the environment variable
+	// that can be used to override the directories of the read-only context
+	// stores that are shared by all users of the system. The directories are
+	// separated by the OS-specific path-list separator. An empty value
+	// disables system contexts.
+	EnvOverrideSystemContextDirs = "DOCKER_SYSTEM_CONTEXT_DIRS"
+
 	// ConfigFileName is the name of the client configuration file inside the
 	// config-directory.
 	ConfigFileName = "config.json"
@@ -85,6 +92,23 @@ func ContextStoreDir() string {
 	return filepath.Join(Dir(), contextsDir)
 }
 
+// SystemContextStoreDirs returns the directories of the read-only context
+// stores that are shared by all users of the system, and merged under the
+// context store of the user (see ContextStoreDir).
+func SystemContextStoreDirs() []string {
+	if dirs, ok := os.LookupEnv(EnvOverrideSystemContextDirs); ok {
+		return filepath.SplitList(dirs)
+	}
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			return nil
+		}
+		return []string{filepath.Join(programData, "docker", contextsDir)}
+	}
+	return []string{filepath.Join("/etc/docker", contextsDir)}
+}
+
 // SetDir sets the directory the configuration file is stored in
 func SetDir(dir string) {
 	// trigger the sync.Once to synchronise with Dir()
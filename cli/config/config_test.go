@@ -456,3 +456,18 @@ func TestSetDir(t *testing.T) {
 	SetDir(expected)
 	assert.Check(t, is.Equal(Dir(), expected))
 }
+
+func TestSystemContextStoreDirs(t *testing.T) {
+	t.Setenv(EnvOverrideSystemContextDirs, "")
+	assert.NilError(t, os.Unsetenv(EnvOverrideSystemContextDirs))
+	if runtime.GOOS != "windows" {
+		assert.Check(t, is.DeepEqual(SystemContextStoreDirs(), []string{"/etc/docker/contexts"}))
+	}
+
+	dirs := strings.Join([]string{"/opt/contexts", "/srv/contexts"}, string(os.PathListSeparator))
+	t.Setenv(EnvOverrideSystemContextDirs, dirs)
+	assert.Check(t, is.DeepEqual(SystemContextStoreDirs(), []string{"/opt/contexts", "/srv/contexts"}))
+
+	t.Setenv(EnvOverrideSystemContextDirs, "")
+	assert.Check(t, is.Len(SystemContextStoreDirs(), 0))
+}
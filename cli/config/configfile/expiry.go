@@ -0,0 +1,64 @@
+package configfile
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+)
+
+// CredentialRefreshWindow is how long before credentials expire that they
+// are refreshed, if they can be refreshed.
+const CredentialRefreshWindow = 5 * time.Minute
+
+// CredentialRefreshFunc obtains new credentials for a registry, to replace
+// credentials that are about to expire. It is passed the credentials store
+// of the registry, and returns the new credentials, and when they expire.
+type CredentialRefreshFunc func(ctx context.Context, store credentials.Store) (types.AuthConfig, time.Time, error)
+
+// GetCredentialExpiry returns when the credentials for the given registry
+// expire, or a zero time if it is not known.
+func (configFile *ConfigFile) GetCredentialExpiry(registryHostname string) time.Time {
+	return configFile.CredentialExpiry[registryHostname]
+}
+
+// SetCredentialExpiry records when the credentials for the given registry
+// expire. A zero time removes the expiry. Credential helpers do not store
+// expiry, so it is stored in the configuration file, and saved with it.
+func (configFile *ConfigFile) SetCredentialExpiry(registryHostname string, expiry time.Time) {
+	if expiry.IsZero() {
+		delete(configFile.CredentialExpiry, registryHostname)
+		if len(configFile.CredentialExpiry) == 0 {
+			configFile.CredentialExpiry = nil
+		}
+		return
+	}
+	if configFile.CredentialExpiry == nil {
+		configFile.CredentialExpiry = make(map[string]time.Time)
+	}
+	configFile.CredentialExpiry[registryHostname] = expiry.UTC().Truncate(time.Second)
+}
+
+// NeedsRefresh returns whether the credentials for the given registry expire
+// within the CredentialRefreshWindow. It returns false if their expiry is not
+// known.
+func (configFile *ConfigFile) NeedsRefresh(registryHostname string) bool {
+	expiry := configFile.GetCredentialExpiry(registryHostname)
+	return !expiry.IsZero() && time.Until(expiry) <= CredentialRefreshWindow
+}
+
+// RefreshCredentials obtains new credentials for the given registry using
+// the given function, and stores them with their expiry.
+func (configFile *ConfigFile) RefreshCredentials(ctx context.Context, registryHostname string, refresh CredentialRefreshFunc) error {
+	store := configFile.GetCredentialsStore(registryHostname)
+	authConfig, expiry, err := refresh(ctx, store)
+	if err != nil {
+		return err
+	}
+	configFile.SetCredentialExpiry(registryHostname, expiry)
+	if authConfig.ServerAddress == "" {
+		authConfig.ServerAddress = registryHostname
+	}
+	return store.Store(authConfig)
+}
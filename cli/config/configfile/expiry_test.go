@@ -0,0 +1,74 @@
+package configfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestSetCredentialExpiry(t *testing.T) {
+	configFile := New("filename")
+	assert.Check(t, configFile.GetCredentialExpiry("example.com").IsZero())
+
+	expiry := time.Date(2025, 1, 2, 3, 4, 5, 6, time.FixedZone("CET", 3600))
+	configFile.SetCredentialExpiry("example.com", expiry)
+	assert.Check(t, is.Equal(configFile.GetCredentialExpiry("example.com"), time.Date(2025, 1, 2, 2, 4, 5, 0, time.UTC)))
+
+	configFile.SetCredentialExpiry("example.com", time.Time{})
+	assert.Check(t, configFile.GetCredentialExpiry("example.com").IsZero())
+	assert.Check(t, is.Nil(configFile.CredentialExpiry))
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	configFile := New("filename")
+	assert.Check(t, !configFile.NeedsRefresh("example.com"))
+
+	configFile.SetCredentialExpiry("example.com", time.Now().Add(time.Hour))
+	assert.Check(t, !configFile.NeedsRefresh("example.com"))
+
+	configFile.SetCredentialExpiry("example.com", time.Now().Add(time.Minute))
+	assert.Check(t, configFile.NeedsRefresh("example.com"))
+
+	configFile.SetCredentialExpiry("example.com", time.Now().Add(-time.Minute))
+	assert.Check(t, configFile.NeedsRefresh("example.com"))
+}
+
+func TestRefreshCredentials(t *testing.T) {
+	const registryHostname = "refresh.example.com"
+
+	configFile := New(filepath.Join(t.TempDir(), "config.json"))
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		registryHostname: {Username: "user", Password: "old-password"},
+	}
+	configFile.SetCredentialExpiry(registryHostname, time.Now().Add(time.Minute))
+
+	expiry := time.Now().Add(time.Hour)
+	err := configFile.RefreshCredentials(context.Background(), registryHostname, func(ctx context.Context, store credentials.Store) (types.AuthConfig, time.Time, error) {
+		return types.AuthConfig{Username: "user", Password: "new-password"}, expiry, nil
+	})
+	assert.NilError(t, err)
+	authConfig, err := configFile.GetAuthConfig(registryHostname)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(authConfig.Password, "new-password"))
+	assert.Check(t, is.Equal(configFile.GetCredentialExpiry(registryHostname), expiry.UTC().Truncate(time.Second)))
+
+	saved, err := os.ReadFile(configFile.Filename)
+	assert.NilError(t, err)
+	assert.Check(t, is.Contains(string(saved), `"credentialExpiry"`))
+
+	err = configFile.RefreshCredentials(context.Background(), registryHostname, func(ctx context.Context, store credentials.Store) (types.AuthConfig, time.Time, error) {
+		return types.AuthConfig{}, time.Time{}, errors.New("refresh token expired")
+	})
+	assert.Check(t, is.Error(err, "refresh token expired"))
+	authConfig, err = configFile.GetAuthConfig(registryHostname)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(authConfig.Password, "new-password"))
+}
@@ -7,6 +7,7 @@
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/cli/cli/config/types"
@@ -26,7 +27,10 @@ type ConfigFile struct {
 	StatsFormat          string                       `json:"statsFormat,omitempty"`
 	DetachKeys           string                       `json:"detachKeys,omitempty"`
 	CredentialsStore     string                       `json:"credsStore,omitempty"`
+	CredentialsStores    []string                     `json:"credsStores,omitempty"`
 	CredentialHelpers    map[string]string            `json:"credHelpers,omitempty"`
+	CredentialExpiry     map[string]time.Time         `json:"credentialExpiry,omitempty"`
+	OIDCProviders        map[string]OIDCProvider      `json:"oidcProviders,omitempty"`
 	Filename             string                       `json:"-"` // Note: for internal use only
 	ServiceInspectFormat string                       `json:"serviceInspectFormat,omitempty"`
 	ServicesFormat       string                       `json:"servicesFormat,omitempty"`
@@ -35,6 +39,7 @@ type ConfigFile struct {
 	ConfigFormat         string                       `json:"configFormat,omitempty"`
 	NodesFormat          string                       `json:"nodesFormat,omitempty"`
 	PruneFilters         []string                     `json:"pruneFilters,omitempty"`
+	PrunePolicies        map[string]PrunePolicy       `json:"prunePolicies,omitempty"`
 	Proxies              map[string]ProxyConfig       `json:"proxies,omitempty"`
 	Experimental         string                       `json:"experimental,omitempty"`
 	CurrentContext       string                       `json:"currentContext,omitempty"`
@@ -42,6 +47,7 @@ type ConfigFile struct {
 	Plugins              map[string]map[string]string `json:"plugins,omitempty"`
 	Aliases              map[string]string            `json:"aliases,omitempty"`
 	Features             map[string]string            `json:"features,omitempty"`
+	Overlay              *Overlay                     `json:"-"` // Note: for internal use only
 }
 
 // ProxyConfig contains proxy configuration settings
@@ -53,6 +59,41 @@ type ProxyConfig struct {
 	AllProxy   string `json:"allProxy,omitempty"`
 }
 
+// OIDCProvider configures "docker login" to authenticate to a registry with
+// an OpenID Connect provider, using the device authorization grant (RFC 8628).
+type OIDCProvider struct {
+	Issuer   string   `json:"issuer"`
+	ClientID string   `json:"clientId"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Audience string   `json:"audience,omitempty"`
+}
+
+// PrunePolicy is a named set of rules used by "docker system prune --policy".
+// Object types without a rule are not pruned.
+type PrunePolicy struct {
+	Containers *PruneRule `json:"containers,omitempty"`
+	Images     *PruneRule `json:"images,omitempty"`
+	Networks   *PruneRule `json:"networks,omitempty"`
+	Volumes    *PruneRule `json:"volumes,omitempty"`
+	BuildCache *PruneRule `json:"buildCache,omitempty"`
+}
+
+// PruneRule describes which objects of a type are pruned by a PrunePolicy.
+type PruneRule struct {
+	// Filters are "key=value" filters, as accepted by the "--filter" option
+	// of the prune commands ("label", "label!" and "until").
+	Filters []string `json:"filters,omitempty"`
+
+	// All prunes all unused images (not only dangling ones), all unused
+	// volumes (not only anonymous ones), and all build cache (including
+	// internal and frontend records).
+	All bool `json:"all,omitempty"`
+
+	// KeepLast keeps the given number of most recently created images of
+	// each repository. Other unused images are pruned. Only used for images.
+	KeepLast int `json:"keepLast,omitempty"`
+}
+
 // New initializes an empty configuration file for the given filename 'fn'
 func New(fn string) *ConfigFile {
 	return &ConfigFile{
@@ -89,6 +130,7 @@ func (configFile *ConfigFile) LoadFromReader(configData io.Reader) error {
 // in this file or not.
 func (configFile *ConfigFile) ContainsAuth() bool {
 	return configFile.CredentialsStore != "" ||
+		len(configFile.CredentialsStores) > 0 ||
 		len(configFile.CredentialHelpers) > 0 ||
 		len(configFile.AuthConfigs) > 0
 }
@@ -127,7 +169,7 @@ func (configFile *ConfigFile) SaveToWriter(writer io.Writer) error {
 		}
 	}
 
-	data, err := json.MarshalIndent(configFile, "", "\t")
+	data, err := configFile.marshalForSave()
 	if err != nil {
 		return err
 	}
@@ -252,12 +294,51 @@ func decodeAuth(authStr string) (string, string, error) {
 }
 
 // GetCredentialsStore returns a new credentials store from the settings in the
-// configuration file
+// configuration file. If multiple credential helpers are configured for the
+// registry, they are consulted in order.
 func (configFile *ConfigFile) GetCredentialsStore(registryHostname string) credentials.Store {
-	if helper := getConfiguredCredentialStore(configFile, registryHostname); helper != "" {
-		return newNativeStore(configFile, helper)
+	helpers := configFile.GetCredentialHelpers(registryHostname)
+	switch len(helpers) {
+	case 0:
+		return credentials.NewFileStore(configFile)
+	case 1:
+		return newNativeStore(configFile, helpers[0])
+	}
+	stores := make([]credentials.Store, 0, len(helpers))
+	for _, helper := range helpers {
+		stores = append(stores, newNativeStore(configFile, helper))
+	}
+	return credentials.NewChainStore(stores...)
+}
+
+// GetCredentialHelpers returns the credential helpers that are configured for
+// the given registry, in the order in which they are consulted. A credential
+// helper is configured in "credHelpers" for a specific registry. Other
+// registries use the list of credential helpers in "credsStores", or the
+// credential helper in "credsStore" if "credsStores" is not set.
+//
+// It returns nil if credentials for the registry are stored in the
+// configuration file.
+func (configFile *ConfigFile) GetCredentialHelpers(registryHostname string) []string {
+	if helper, ok := getConfiguredCredentialHelper(configFile, registryHostname); ok {
+		if helper == "" {
+			return nil
+		}
+		return []string{helper}
+	}
+	if len(configFile.CredentialsStores) > 0 {
+		helpers := make([]string, 0, len(configFile.CredentialsStores))
+		for _, helper := range configFile.CredentialsStores {
+			if helper != "" {
+				helpers = append(helpers, helper)
+			}
+		}
+		return helpers
+	}
+	if configFile.CredentialsStore == "" {
+		return nil
 	}
-	return credentials.NewFileStore(configFile)
+	return []string{configFile.CredentialsStore}
 }
 
 // var for unit testing.
@@ -265,21 +346,63 @@ func (configFile *ConfigFile) GetCredentialsStore(registryHostname string) crede
 	return credentials.NewNativeStore(configFile, helperSuffix)
 }
 
-// GetAuthConfig for a repository from the credential store
+// GetAuthConfig for a repository from the credential store. The repository
+// is a registry hostname, or a registry hostname and repository path, such as
+// "registry.example.com/team-a/project", in which case the credentials that
+// are scoped to the longest matching repository path are used (see
+// [ConfigFile.ResolveCredentialsKey]).
 func (configFile *ConfigFile) GetAuthConfig(registryHostname string) (types.AuthConfig, error) {
-	return configFile.GetCredentialsStore(registryHostname).Get(registryHostname)
+	key := configFile.ResolveCredentialsKey(registryHostname)
+	return configFile.GetCredentialsStore(key).Get(key)
+}
+
+// ResolveCredentialsKey returns the key under which the credentials for the
+// given registry hostname and repository path are stored. Credentials can be
+// scoped to a repository path, such as "registry.example.com/team-a", to use
+// them for all repositories under that path instead of the credentials for the
+// registry. If credentials are stored or a credential helper is configured for
+// a repository path, the longest matching repository path is returned, and the
+// registry hostname otherwise. URLs are returned as-is. The keys under which
+// OAuth tokens are stored (see [credentials.OAuthTokenKey]) are never
+// returned for a repository path.
+func (configFile *ConfigFile) ResolveCredentialsKey(repository string) string {
+	if strings.Contains(repository, "://") {
+		return repository
+	}
+	for key := strings.TrimSuffix(repository, "/"); strings.Contains(key, "/"); key = key[:strings.LastIndex(key, "/")] {
+		if credentials.IsOAuthTokenKey(key) {
+			continue
+		}
+		if _, ok := configFile.AuthConfigs[key]; ok {
+			return key
+		}
+		if _, ok := configFile.CredentialHelpers[key]; ok {
+			return key
+		}
+	}
+	hostname, _, _ := strings.Cut(repository, "/")
+	return hostname
 }
 
-// getConfiguredCredentialStore returns the credential helper configured for the
-// given registry, the default credsStore, or the empty string if neither are
-// configured.
-func getConfiguredCredentialStore(c *ConfigFile, registryHostname string) string {
+// getConfiguredCredentialHelper returns the credential helper configured for
+// the given registry in "credHelpers", and whether one is configured. For
+// credentials that are scoped to a repository path, the helper configured for
+// the longest matching repository path, or the registry, is returned.
+func getConfiguredCredentialHelper(c *ConfigFile, registryHostname string) (string, bool) {
 	if c.CredentialHelpers != nil && registryHostname != "" {
 		if helper, exists := c.CredentialHelpers[registryHostname]; exists {
-			return helper
+			return helper, true
+		}
+		if !strings.Contains(registryHostname, "://") {
+			for key := registryHostname; strings.Contains(key, "/"); {
+				key = key[:strings.LastIndex(key, "/")]
+				if helper, exists := c.CredentialHelpers[key]; exists {
+					return helper, true
+				}
+			}
 		}
 	}
-	return c.CredentialsStore
+	return "", false
 }
 
 // GetAllCredentials returns all of the credentials stored in all of the
@@ -446,6 +446,92 @@ func TestGetAllCredentialsCredHelperOverridesDefaultStore(t *testing.T) {
 	assert.Check(t, is.Equal(0, testCredHelper.(*mockNativeStore).GetAllCallCount))
 }
 
+func TestGetCredentialHelpers(t *testing.T) {
+	configFile := New("filename")
+	assert.Check(t, is.Len(configFile.GetCredentialHelpers("example.com"), 0))
+
+	configFile.CredentialsStore = "desktop,pass"
+	assert.Check(t, is.DeepEqual(configFile.GetCredentialHelpers("example.com"), []string{"desktop,pass"}))
+
+	configFile.CredentialsStores = []string{"desktop", "pass"}
+	configFile.CredentialHelpers = map[string]string{
+		"example.com":       "ecr-login",
+		"other.example.com": "",
+	}
+	assert.Check(t, is.DeepEqual(configFile.GetCredentialHelpers("example.com"), []string{"ecr-login"}))
+	assert.Check(t, is.Len(configFile.GetCredentialHelpers("other.example.com"), 0))
+	assert.Check(t, is.DeepEqual(configFile.GetCredentialHelpers("registry.example.com"), []string{"desktop", "pass"}))
+
+	// credentials scoped to a repository path use the helper of the registry,
+	// unless a helper is configured for the repository path.
+	configFile.CredentialHelpers["example.com/team-a"] = "pass"
+	assert.Check(t, is.DeepEqual(configFile.GetCredentialHelpers("example.com/team-b"), []string{"ecr-login"}))
+	assert.Check(t, is.DeepEqual(configFile.GetCredentialHelpers("example.com/team-a/project"), []string{"pass"}))
+}
+
+func TestGetAuthConfigRepositoryScope(t *testing.T) {
+	configFile := New("filename")
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"example.com":                     {Username: "registry-user", ServerAddress: "example.com"},
+		"example.com/team-a":              {Username: "team-a-user", ServerAddress: "example.com/team-a"},
+		"example.com/team-a/project":      {Username: "project-user", ServerAddress: "example.com/team-a/project"},
+		"example.com/_oauth/access-token": {Username: "token-user", Password: "access-token", ServerAddress: "example.com/_oauth/access-token"},
+	}
+
+	testCases := []struct {
+		repository   string
+		expectedKey  string
+		expectedUser string
+	}{
+		{repository: "example.com", expectedKey: "example.com", expectedUser: "registry-user"},
+		{repository: "example.com/team-b/project", expectedKey: "example.com", expectedUser: "registry-user"},
+		{repository: "example.com/team-a", expectedKey: "example.com/team-a", expectedUser: "team-a-user"},
+		{repository: "example.com/team-a/other", expectedKey: "example.com/team-a", expectedUser: "team-a-user"},
+		{repository: "example.com/team-a/project/nested", expectedKey: "example.com/team-a/project", expectedUser: "project-user"},
+		{repository: "example.com/team-ab", expectedKey: "example.com", expectedUser: "registry-user"},
+		{repository: "other.example.com/team-a", expectedKey: "other.example.com"},
+		{repository: "https://example.com/team-a", expectedKey: "https://example.com/team-a"},
+		{repository: "example.com/_oauth/access-token", expectedKey: "example.com", expectedUser: "registry-user"},
+		{repository: "example.com/_oauth/access-token/nested", expectedKey: "example.com", expectedUser: "registry-user"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.repository, func(t *testing.T) {
+			assert.Check(t, is.Equal(configFile.ResolveCredentialsKey(tc.repository), tc.expectedKey))
+
+			authConfig, err := configFile.GetAuthConfig(tc.repository)
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(authConfig.Username, tc.expectedUser))
+		})
+	}
+}
+
+func TestGetAuthConfigCredHelperChain(t *testing.T) {
+	const testRegistryHostname = "example.com"
+
+	configFile := New("filename")
+	configFile.CredentialHelpers = map[string]string{testRegistryHostname: "first,second"}
+
+	expectedAuth := types.AuthConfig{
+		Username: "second_user",
+		Password: "second_pass",
+	}
+	first := NewMockNativeStore(map[string]types.AuthConfig{}, nil)
+	second := NewMockNativeStore(map[string]types.AuthConfig{testRegistryHostname: expectedAuth}, nil)
+
+	tmpNewNativeStore := newNativeStore
+	defer func() { newNativeStore = tmpNewNativeStore }()
+	newNativeStore = func(configFile *ConfigFile, helperSuffix string) credentials.Store {
+		if helperSuffix == "first" {
+			return first
+		}
+		return second
+	}
+
+	authConfig, err := configFile.GetAuthConfig(testRegistryHostname)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(expectedAuth, authConfig))
+}
+
 func TestLoadFromReaderWithUsernamePassword(t *testing.T) {
 	configFile := New("test-load")
 	defer os.Remove("test-load")
@@ -0,0 +1,208 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// overridable are the properties of the configuration file that can be set by
+// an overlay. Overlays of imported contexts are not imported, as contexts can
+// be imported from untrusted sources.
+var overridable = map[string]struct{}{
+	"HttpHeaders":          {},
+	"credsStore":           {},
+	"credHelpers":          {},
+	"proxies":              {},
+	"psFormat":             {},
+	"imagesFormat":         {},
+	"networksFormat":       {},
+	"pluginsFormat":        {},
+	"volumesFormat":        {},
+	"statsFormat":          {},
+	"serviceInspectFormat": {},
+	"servicesFormat":       {},
+	"tasksFormat":          {},
+	"secretFormat":         {},
+	"configFormat":         {},
+	"nodesFormat":          {},
+	"detachKeys":           {},
+}
+
+// Overlay holds the values of the properties of a configuration file that are
+// set by an overlay, before and after applying the overlay, so that they are
+// not saved. See [ConfigFile.ApplyOverlay].
+type Overlay struct {
+	Base    map[string]json.RawMessage
+	Applied map[string]json.RawMessage
+}
+
+// OverlayValue parses the value for the given property of the configuration
+// file for use in an overlay. Values of string properties are used as-is,
+// and values of other properties are parsed as JSON.
+func OverlayValue(key, value string) (interface{}, error) {
+	field, err := overlayField(key)
+	if err != nil {
+		return nil, err
+	}
+	if field.Type.Kind() == reflect.String {
+		return value, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, errors.Errorf("invalid value for %q: expected a JSON %s", key, jsonType(field.Type))
+	}
+	if err := ValidateOverlay(map[string]interface{}{key: v}); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ValidateOverlay validates that the overlay only sets properties of the
+// configuration file that can be overridden, using values of the right type.
+func ValidateOverlay(overlay map[string]interface{}) error {
+	for k := range overlay {
+		if _, err := overlayField(k); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &ConfigFile{}); err != nil {
+		return errors.Wrap(err, "invalid configuration overlay")
+	}
+	return nil
+}
+
+func overlayField(key string) (reflect.StructField, error) {
+	t := reflect.TypeOf(ConfigFile{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && name == key {
+			if _, ok := overridable[key]; !ok {
+				return reflect.StructField{}, errors.Errorf("configuration property %q cannot be overridden", key)
+			}
+			return t.Field(i), nil
+		}
+	}
+	return reflect.StructField{}, errors.Errorf("unknown configuration property %q", key)
+}
+
+// jsonType returns the name of the JSON type for the given type.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "value"
+	}
+}
+
+// ApplyOverlay merges the overlay over the configuration. Properties that
+// are objects (such as "HttpHeaders" or "proxies") are merged with the
+// existing values, other properties are replaced.
+//
+// The values set by the overlay are not written when saving the
+// configuration, unless they are changed after applying the overlay.
+func (configFile *ConfigFile) ApplyOverlay(overlay map[string]interface{}) error {
+	if len(overlay) == 0 {
+		return nil
+	}
+	if err := ValidateOverlay(overlay); err != nil {
+		return err
+	}
+	base, err := configFile.properties()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, configFile); err != nil {
+		return errors.Wrap(err, "invalid configuration overlay")
+	}
+	applied, err := configFile.properties()
+	if err != nil {
+		return err
+	}
+
+	if configFile.Overlay == nil {
+		configFile.Overlay = &Overlay{
+			Base:    make(map[string]json.RawMessage),
+			Applied: make(map[string]json.RawMessage),
+		}
+	}
+	for k := range overlay {
+		if _, ok := configFile.Overlay.Base[k]; !ok {
+			configFile.Overlay.Base[k] = base[k]
+		}
+		configFile.Overlay.Applied[k] = applied[k]
+	}
+	return nil
+}
+
+// properties returns the properties of the configuration file as JSON.
+func (configFile *ConfigFile) properties() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(configFile)
+	if err != nil {
+		return nil, err
+	}
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// withoutOverlay returns a copy of the configuration file, with the values of
+// properties set by an overlay restored to the values they had before
+// applying the overlay, unless they were changed since.
+func (configFile *ConfigFile) withoutOverlay() (*ConfigFile, error) {
+	props, err := configFile.properties()
+	if err != nil {
+		return nil, err
+	}
+	c := *configFile
+	c.Overlay = nil
+	v := reflect.ValueOf(&c).Elem()
+	for k, applied := range configFile.Overlay.Applied {
+		if !bytes.Equal(props[k], applied) {
+			continue
+		}
+		field, err := overlayField(k)
+		if err != nil {
+			return nil, err
+		}
+		fv := v.FieldByIndex(field.Index)
+		fv.Set(reflect.Zero(field.Type))
+		if base := configFile.Overlay.Base[k]; base != nil {
+			if err := json.Unmarshal(base, fv.Addr().Interface()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &c, nil
+}
+
+// marshalForSave marshals the configuration file for saving, without the
+// values set by a configuration overlay.
+func (configFile *ConfigFile) marshalForSave() ([]byte, error) {
+	if configFile.Overlay == nil {
+		return json.MarshalIndent(configFile, "", "\t")
+	}
+	c, err := configFile.withoutOverlay()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(c, "", "\t")
+}
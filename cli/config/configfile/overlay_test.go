@@ -0,0 +1,101 @@
+package configfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	configFile := New("test-overlay")
+	assert.NilError(t, configFile.LoadFromReader(strings.NewReader(`{
+	"psFormat": "table {{.ID}}",
+	"detachKeys": "ctrl-e,e",
+	"HttpHeaders": {"X-Team": "infra"}
+}`)))
+
+	assert.NilError(t, configFile.ApplyOverlay(map[string]any{
+		"psFormat":     "table {{.Names}}\t{{.Status}}",
+		"imagesFormat": "table {{.Repository}}",
+		"HttpHeaders":  map[string]any{"X-Env": "prod"},
+		"credsStore":   "vault",
+	}))
+	assert.Check(t, is.Equal(configFile.PsFormat, "table {{.Names}}\t{{.Status}}"))
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "table {{.Repository}}"))
+	assert.Check(t, is.Equal(configFile.DetachKeys, "ctrl-e,e"))
+	assert.Check(t, is.Equal(configFile.CredentialsStore, "vault"))
+	assert.Check(t, is.DeepEqual(configFile.HTTPHeaders, map[string]string{"X-Team": "infra", "X-Env": "prod"}))
+
+	// Values set by the overlay are not saved, but other changes are, in the
+	// same order as without an overlay.
+	configFile.AuthConfigs["example.com"] = types.AuthConfig{Username: "user", Password: "pass"}
+	configFile.DetachKeys = "ctrl-x,x"
+	var buf bytes.Buffer
+	assert.NilError(t, configFile.SaveToWriter(&buf))
+	assert.Check(t, is.Equal(buf.String(), `{
+	"auths": {
+		"example.com": {
+			"auth": "dXNlcjpwYXNz"
+		}
+	},
+	"HttpHeaders": {
+		"X-Team": "infra"
+	},
+	"psFormat": "table {{.ID}}",
+	"detachKeys": "ctrl-x,x"
+}`))
+
+	// Values set by the overlay that were changed afterwards are saved.
+	configFile.PsFormat = "table {{.Image}}"
+	buf.Reset()
+	assert.NilError(t, configFile.SaveToWriter(&buf))
+	assert.Check(t, is.Contains(buf.String(), `"psFormat": "table {{.Image}}"`))
+	assert.Check(t, !strings.Contains(buf.String(), "imagesFormat"))
+	assert.Check(t, !strings.Contains(buf.String(), "credsStore"))
+	assert.Check(t, !strings.Contains(buf.String(), "X-Env"))
+}
+
+func TestValidateOverlay(t *testing.T) {
+	for _, tc := range []struct {
+		overlay  map[string]any
+		expected string
+	}{
+		{overlay: map[string]any{"psFormat": "{{.ID}}", "detachKeys": "ctrl-x,x"}},
+		{overlay: map[string]any{"credsStore": "vault", "credHelpers": map[string]any{"registry.example.com": "vault"}}},
+		{overlay: map[string]any{"proxies": map[string]any{"default": map[string]any{"httpProxy": "http://proxy"}}}},
+		{overlay: map[string]any{"auths": map[string]any{}}, expected: `configuration property "auths" cannot be overridden`},
+		{overlay: map[string]any{"currentContext": "foo"}, expected: `configuration property "currentContext" cannot be overridden`},
+		{overlay: map[string]any{"oidcProviders": map[string]any{}}, expected: `configuration property "oidcProviders" cannot be overridden`},
+		{overlay: map[string]any{"cliPluginsExtraDirs": []any{"/tmp"}}, expected: `configuration property "cliPluginsExtraDirs" cannot be overridden`},
+		{overlay: map[string]any{"HttpHeaders": "foo"}, expected: "invalid configuration overlay"},
+		{overlay: map[string]any{"nosuchproperty": "foo"}, expected: `unknown configuration property "nosuchproperty"`},
+		{overlay: map[string]any{"psFormat": 1}, expected: "invalid configuration overlay"},
+	} {
+		err := ValidateOverlay(tc.overlay)
+		if tc.expected == "" {
+			assert.Check(t, err)
+		} else {
+			assert.Check(t, is.ErrorContains(err, tc.expected))
+		}
+	}
+}
+
+func TestOverlayValue(t *testing.T) {
+	v, err := OverlayValue("psFormat", "table {{.ID}}")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(v, "table {{.ID}}"))
+
+	v, err = OverlayValue("credHelpers", `{"registry.example.com":"vault"}`)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(v, map[string]any{"registry.example.com": "vault"}))
+
+	_, err = OverlayValue("credHelpers", "vault")
+	assert.Check(t, is.Error(err, `invalid value for "credHelpers": expected a JSON object`))
+
+	_, err = OverlayValue("auths", "{}")
+	assert.Check(t, is.Error(err, `configuration property "auths" cannot be overridden`))
+}
@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// chainStore implements a credentials store that consults an ordered list of
+// stores, falling through to the next store if a store has no credentials
+// for a server. Stores that use a credential helper that is not installed
+// are skipped.
+type chainStore struct {
+	stores []Store
+}
+
+// NewChainStore creates a new credentials store that consults the given
+// stores in order. Credentials are retrieved from the first store that has
+// credentials for a server, and saved in the first store. Stores that use a
+// credential helper that is not installed are skipped.
+func NewChainStore(stores ...Store) Store {
+	return &chainStore{stores: stores}
+}
+
+// available returns the stores of the chain that can be used.
+func (c *chainStore) available() []Store {
+	stores := make([]Store, 0, len(c.stores))
+	for _, s := range c.stores {
+		if ns, ok := s.(*nativeStore); ok && !ns.installed() {
+			continue
+		}
+		stores = append(stores, s)
+	}
+	return stores
+}
+
+// Erase removes the given credentials from all stores.
+func (c *chainStore) Erase(serverAddress string) error {
+	var firstErr error
+	for _, s := range c.available() {
+		if err := s.Erase(serverAddress); err != nil && !credentials.IsErrCredentialsNotFound(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Get retrieves credentials for a specific server from the first store that
+// has credentials for it. It falls through to the next store if a store has
+// no credentials for the server, or its credential helper is not installed,
+// but not if a store returns an error.
+func (c *chainStore) Get(serverAddress string) (types.AuthConfig, error) {
+	var first types.AuthConfig
+	for i, s := range c.available() {
+		auth, err := s.Get(serverAddress)
+		if err != nil {
+			return auth, err
+		}
+		if hasCredentials(auth) {
+			return auth, nil
+		}
+		if i == 0 {
+			first = auth
+		}
+	}
+	return first, nil
+}
+
+// GetAll retrieves all the credentials from all stores. Credentials from
+// stores earlier in the chain take precedence.
+func (c *chainStore) GetAll() (map[string]types.AuthConfig, error) {
+	all := make(map[string]types.AuthConfig)
+	stores := c.available()
+	for i := len(stores) - 1; i >= 0; i-- {
+		auths, err := stores[i].GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range auths {
+			all[k] = v
+		}
+	}
+	return all, nil
+}
+
+// Store saves the given credentials in the first store.
+func (c *chainStore) Store(authConfig types.AuthConfig) error {
+	stores := c.available()
+	if len(stores) == 0 {
+		if len(c.stores) == 0 {
+			return nil
+		}
+		// Return the error of the missing credential helper.
+		return c.stores[0].Store(authConfig)
+	}
+	return stores[0].Store(authConfig)
+}
+
+// hasCredentials returns whether auth holds credentials. The "Auth" field is
+// not considered, as native stores return it from the file store as-is.
+func hasCredentials(auth types.AuthConfig) bool {
+	return auth.Username != "" || auth.Password != "" || auth.IdentityToken != ""
+}
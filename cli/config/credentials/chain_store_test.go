@@ -0,0 +1,109 @@
+package credentials
+
+import (
+	"testing"
+
+	"github.com/docker/cli/cli/config/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestChainStoreGet(t *testing.T) {
+	first := NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com": {Email: "foo@example.com"},
+	}})
+	second := NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com":       {Username: "second", Password: "pass"},
+		"https://other.example.com": {Username: "other", Password: "pass"},
+	}})
+	s := NewChainStore(first, second)
+
+	auth, err := s.Get("https://example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "second"))
+
+	auth, err = s.Get("https://other.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "other"))
+
+	auth, err = s.Get("https://missing.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(auth, types.AuthConfig{}))
+}
+
+func TestChainStoreGetFallsThroughNotFound(t *testing.T) {
+	f := &fakeStore{configs: map[string]types.AuthConfig{}}
+	native := &nativeStore{programFunc: mockCommandFn, fileStore: NewFileStore(f)}
+	fallback := NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{
+		missingCredsAddress:  {Username: "fallback", Password: "pass"},
+		invalidServerAddress: {Username: "fallback", Password: "pass"},
+	}})
+	s := NewChainStore(native, fallback)
+
+	auth, err := s.Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "foo"))
+
+	auth, err = s.Get(missingCredsAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "fallback"))
+
+	// Errors other than "not found" are not hidden by the fallback.
+	_, err = s.Get(invalidServerAddress)
+	assert.Check(t, is.ErrorContains(err, "program failed"))
+}
+
+func TestChainStoreGetAll(t *testing.T) {
+	first := NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com": {Username: "first"},
+	}})
+	second := NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com":       {Username: "second"},
+		"https://other.example.com": {Username: "other"},
+	}})
+
+	auths, err := NewChainStore(first, second).GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(auths, 2))
+	assert.Check(t, is.Equal(auths["https://example.com"].Username, "first"))
+	assert.Check(t, is.Equal(auths["https://other.example.com"].Username, "other"))
+}
+
+func TestChainStoreStoreAndErase(t *testing.T) {
+	firstFile := &fakeStore{configs: map[string]types.AuthConfig{}}
+	secondFile := &fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com": {Username: "old"},
+	}}
+	s := NewChainStore(NewFileStore(firstFile), NewFileStore(secondFile))
+
+	assert.NilError(t, s.Store(types.AuthConfig{ServerAddress: "https://example.com", Username: "new"}))
+	assert.Check(t, is.Equal(firstFile.configs["https://example.com"].Username, "new"))
+	assert.Check(t, is.Equal(secondFile.configs["https://example.com"].Username, "old"))
+
+	assert.NilError(t, s.Erase("https://example.com"))
+	assert.Check(t, is.Len(firstFile.configs, 0))
+	assert.Check(t, is.Len(secondFile.configs, 0))
+}
+
+func TestChainStoreSkipsMissingHelper(t *testing.T) {
+	missing := &nativeStore{
+		programFunc: mockCommandFn,
+		fileStore:   NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{}}),
+		helper:      "docker-credential-does-not-exist",
+	}
+	fallbackFile := &fakeStore{configs: map[string]types.AuthConfig{
+		validServerAddress: {Username: "fallback", Password: "pass"},
+	}}
+	s := NewChainStore(missing, NewFileStore(fallbackFile))
+
+	auth, err := s.Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Username, "fallback"))
+
+	auths, err := s.GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(auths, 1))
+
+	assert.NilError(t, s.Store(types.AuthConfig{ServerAddress: "https://example.com", Username: "new"}))
+	assert.Check(t, is.Equal(fallbackFile.configs["https://example.com"].Username, "new"))
+}
@@ -46,7 +46,7 @@ func (c *fileStore) Get(serverAddress string) (types.AuthConfig, error) {
 		// Maybe they have a legacy config file, we will iterate the keys converting
 		// them to the new format and testing
 		for r, ac := range c.file.GetAuthConfigs() {
-			if serverAddress == ConvertToHostname(r) {
+			if serverAddress == ConvertToCredentialsKey(r) {
 				return ac, nil
 			}
 		}
@@ -116,3 +116,42 @@ func ConvertToHostname(maybeURL string) string {
 	hostName, _, _ := strings.Cut(stripped, "/")
 	return hostName
 }
+
+// ConvertToCredentialsKey converts a registry address to the key under which
+// its credentials are stored. This is the hostname of the registry, or the
+// hostname and repository path for credentials that are scoped to a repository
+// path, such as "registry.example.com/team-a". URLs, and addresses with a
+// "/v1/" or "/v2/" path (as used by older versions of the CLI), are converted
+// to just the hostname.
+func ConvertToCredentialsKey(maybeURL string) string {
+	if strings.Contains(maybeURL, "://") {
+		return ConvertToHostname(maybeURL)
+	}
+	hostName, repoPath, _ := strings.Cut(maybeURL, "/")
+	repoPath = strings.Trim(repoPath, "/")
+	if repoPath == "" || repoPath == "v1" || repoPath == "v2" {
+		return hostName
+	}
+	return hostName + "/" + repoPath
+}
+
+// oauthTokenPath is the path under the registry hostname where OAuth tokens
+// are stored. It is not a valid repository path, so that the tokens can't be
+// mistaken for credentials that are scoped to a repository path.
+const oauthTokenPath = "_oauth"
+
+// OAuthTokenKey returns the key under which the OAuth token with the given
+// name, such as "access-token", is stored for a registry, for example
+// "registry.example.com/_oauth/access-token".
+func OAuthTokenKey(hostname, name string) string {
+	return strings.TrimSuffix(hostname, "/") + "/" + oauthTokenPath + "/" + name
+}
+
+// IsOAuthTokenKey returns whether key is a key returned by [OAuthTokenKey].
+func IsOAuthTokenKey(key string) bool {
+	if strings.Contains(key, "://") {
+		return false
+	}
+	_, repoPath, _ := strings.Cut(key, "/")
+	return repoPath == oauthTokenPath || strings.HasPrefix(repoPath, oauthTokenPath+"/")
+}
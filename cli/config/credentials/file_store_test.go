@@ -303,3 +303,74 @@ func TestConvertToHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertToCredentialsKey(t *testing.T) {
+	tests := []struct{ input, expected string }{
+		{
+			input:    "example.com",
+			expected: "example.com",
+		},
+		{
+			input:    "example.com:6555",
+			expected: "example.com:6555",
+		},
+		{
+			input:    "https://example.com/v2/",
+			expected: "example.com",
+		},
+		{
+			input:    "https://example.com/team-a",
+			expected: "example.com",
+		},
+		{
+			input:    "example.com/v1/",
+			expected: "example.com",
+		},
+		{
+			input:    "example.com/team-a",
+			expected: "example.com/team-a",
+		},
+		{
+			input:    "example.com:6555/team-a/project/",
+			expected: "example.com:6555/team-a/project",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			actual := ConvertToCredentialsKey(tc.input)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestFileStoreGetScopedCredentials(t *testing.T) {
+	f := &fakeStore{configs: map[string]types.AuthConfig{
+		"example.com/team-a": {
+			Username:      "team-a",
+			ServerAddress: "example.com/team-a",
+		},
+	}}
+
+	s := NewFileStore(f)
+	a, err := s.Get("example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, a, types.AuthConfig{})
+
+	a, err = s.Get("example.com/team-a")
+	assert.NilError(t, err)
+	assert.Equal(t, a.Username, "team-a")
+}
+
+func TestOAuthTokenKey(t *testing.T) {
+	key := OAuthTokenKey("registry.example.com", "refresh-token")
+	assert.Equal(t, key, "registry.example.com/_oauth/refresh-token")
+	assert.Check(t, IsOAuthTokenKey(key))
+	assert.Check(t, IsOAuthTokenKey("registry.example.com/_oauth"))
+	assert.Check(t, !IsOAuthTokenKey("registry.example.com"))
+	assert.Check(t, !IsOAuthTokenKey("registry.example.com/team-a/_oauth/refresh-token"))
+	assert.Check(t, !IsOAuthTokenKey("https://registry.example.com/_oauth/refresh-token"))
+
+	// The key must not be converted to the key of the registry, so that the
+	// file store doesn't return the token for the registry.
+	assert.Equal(t, ConvertToCredentialsKey(key), key)
+}
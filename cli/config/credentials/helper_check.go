@@ -0,0 +1,45 @@
+package credentials
+
+import (
+	"os/exec"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// HelperCheck is the result of checking a credential helper.
+type HelperCheck struct {
+	// Helper is the name of the credential helper, without the
+	// "docker-credential-" prefix.
+	Helper string
+	// Path is the path of the credential helper binary, or empty if the
+	// binary was not found.
+	Path string
+	// Found indicates whether the credential helper has credentials for the
+	// server.
+	Found bool
+	// Err is the error that occurred when looking up, or running the
+	// credential helper.
+	Err error
+}
+
+// CheckHelper checks that the binary of the given credential helper is
+// installed, and asks it for the credentials of the given server. The
+// credentials themselves are not returned.
+func CheckHelper(helper, serverAddress string) HelperCheck {
+	check := HelperCheck{Helper: helper}
+	path, err := exec.LookPath(remoteCredentialsPrefix + helper)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	check.Path = path
+	if _, err := client.Get(client.NewShellProgramFunc(path), serverAddress); err != nil {
+		if !credentials.IsErrCredentialsNotFound(err) {
+			check.Err = err
+		}
+		return check
+	}
+	check.Found = true
+	return check
+}
@@ -1,6 +1,8 @@
 package credentials
 
 import (
+	"os/exec"
+
 	"github.com/docker/cli/cli/config/types"
 	"github.com/docker/docker-credential-helpers/client"
 	"github.com/docker/docker-credential-helpers/credentials"
@@ -17,6 +19,9 @@
 type nativeStore struct {
 	programFunc client.ProgramFunc
 	fileStore   Store
+
+	// helper is the name of the credential helper binary.
+	helper string
 }
 
 // NewNativeStore creates a new native store that
@@ -26,7 +31,17 @@ func NewNativeStore(file store, helperSuffix string) Store {
 	return &nativeStore{
 		programFunc: client.NewShellProgramFunc(name),
 		fileStore:   NewFileStore(file),
+		helper:      name,
+	}
+}
+
+// installed returns whether the binary of the credential helper is found.
+func (c *nativeStore) installed() bool {
+	if c.helper == "" {
+		return true
 	}
+	_, err := exec.LookPath(c.helper)
+	return err == nil
 }
 
 // Erase removes the given credentials from the native store.
@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// Health describes the result of probing a Docker endpoint.
+type Health struct {
+	// Reachable indicates whether the daemon responded to a ping.
+	Reachable bool
+	// Latency is the time it took for the daemon to respond to a ping.
+	Latency time.Duration
+	// APIVersion is the API version reported by the daemon.
+	APIVersion string
+	// Err is the error that occurred when probing the endpoint.
+	Err error
+	// Ping is the daemon's response to the ping, if it is reachable.
+	Ping types.Ping
+}
+
+// CheckHealth probes the endpoint by performing a TLS handshake (if the
+// endpoint uses TLS), and pinging the daemon.
+func (ep *Endpoint) CheckHealth(ctx context.Context) Health {
+	if err := ep.checkTLS(ctx); err != nil {
+		return Health{Err: err}
+	}
+	opts, err := ep.ClientOpts()
+	if err != nil {
+		return Health{Err: err}
+	}
+	apiClient, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return Health{Err: err}
+	}
+	defer apiClient.Close()
+
+	start := time.Now()
+	ping, err := apiClient.Ping(ctx)
+	if err != nil {
+		return Health{Err: err}
+	}
+	return Health{
+		Reachable:  true,
+		Latency:    time.Since(start),
+		APIVersion: ping.APIVersion,
+		Ping:       ping,
+	}
+}
+
+// checkTLS performs a TLS handshake with endpoints that use TLS, so that
+// errors in the TLS configuration are reported as such, instead of as a
+// connection error.
+func (ep *Endpoint) checkTLS(ctx context.Context) error {
+	if ep.Host == "" {
+		return nil
+	}
+	if helper, err := connhelper.GetConnectionHelper(ep.Host); err != nil || helper != nil {
+		return err
+	}
+	tlsConfig, err := ep.tlsConfig()
+	if err != nil || tlsConfig == nil {
+		return err
+	}
+	hostURL, err := client.ParseHostURL(ep.Host)
+	if err != nil {
+		return err
+	}
+	if hostURL.Scheme != "tcp" {
+		return nil
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", hostURL.Host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName, _, _ = net.SplitHostPort(hostURL.Host)
+	}
+	if err := tls.Client(conn, tlsConfig).HandshakeContext(ctx); err != nil {
+		return errors.Wrap(err, "TLS handshake failed")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// An encrypted context archive consists of a header, followed by the
+// exported context encrypted with AES-256-GCM, using the header as additional
+// authenticated data. The header is made up of:
+//
+//   - the encryptedMagic string,
+//   - the number of PBKDF2 iterations used to derive the key (4 bytes, big endian),
+//   - the salt used to derive the key (saltLen bytes),
+//   - the GCM nonce (nonceLen bytes).
+const (
+	encryptedMagic = "docker-context-encrypted-v1\n"
+	encryptedType  = "application/vnd.docker.context.encrypted"
+
+	kdfIterations    = 600_000
+	maxKDFIterations = 10_000_000
+	keyLen           = 32
+	saltLen          = 16
+	nonceLen         = 12
+	tagLen           = 16
+	headerLen        = len(encryptedMagic) + 4 + saltLen + nonceLen
+
+	maxEncryptedSizeToImport = maxAllowedFileSizeToImport + int64(headerLen) + tagLen
+)
+
+// ExportEncrypted exports an existing context in the same way as Export, and
+// encrypts the exported data with a key derived from the passphrase. Use
+// ImportWithPassphrase to import the encrypted context.
+func ExportEncrypted(name string, s Reader, passphrase []byte) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		exported := Export(name, s)
+		defer exported.Close()
+		data, err := io.ReadAll(&LimitedReader{R: exported, N: maxAllowedFileSizeToImport})
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		encrypted, err := encrypt(data, passphrase)
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		if _, err := writer.Write(encrypted); err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		writer.Close()
+	}()
+	return reader
+}
+
+func encrypt(data, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	header := make([]byte, headerLen)
+	n := copy(header, encryptedMagic)
+	binary.BigEndian.PutUint32(header[n:], kdfIterations)
+	salt := header[n+4 : n+4+saltLen]
+	nonce := header[n+4+saltLen:]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(header, nonce, data, header), nil
+}
+
+func decrypt(data, passphrase []byte) ([]byte, error) {
+	if len(data) < headerLen || !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		return nil, errdefs.InvalidParameter(errors.New("invalid encrypted context"))
+	}
+	header := data[:headerLen]
+	n := len(encryptedMagic)
+	iterations := binary.BigEndian.Uint32(header[n:])
+	if iterations == 0 || iterations > maxKDFIterations {
+		return nil, errdefs.InvalidParameter(errors.New("invalid encrypted context: unsupported key derivation parameters"))
+	}
+	salt := header[n+4 : n+4+saltLen]
+	nonce := header[n+4+saltLen:]
+	gcm, err := newGCM(passphrase, salt, int(iterations))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, nonce, data[headerLen:], header)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(errors.New("unable to decrypt context: incorrect passphrase, or the data is corrupted"))
+	}
+	return plain, nil
+}
+
+func newGCM(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2.Key(passphrase, salt, iterations, keyLen, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/fvbommel/sortorder"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LayeredStore is a Store that merges one or more read-only context stores
+// (for example, contexts that are distributed to all users of a system)
+// under a writable context store. Contexts in the writable store take
+// precedence over contexts with the same name in the read-only stores, and
+// read-only stores take precedence in the order in which they are given.
+//
+// Contexts in read-only stores cannot be updated or removed.
+type LayeredStore struct {
+	writable *ContextStore
+	readOnly []*ContextStore
+}
+
+// NewLayered creates a store from a writable directory, and zero or more
+// read-only directories, which use the same layout as the writable directory.
+// Read-only directories that do not exist are treated as empty, and
+// directories that cannot be read are ignored with a warning.
+func NewLayered(dir string, readOnlyDirs []string, cfg Config) *LayeredStore {
+	s := &LayeredStore{
+		writable: New(dir, cfg),
+	}
+	for _, d := range readOnlyDirs {
+		if d == "" {
+			continue
+		}
+		if _, err := os.ReadDir(filepath.Join(d, metadataDir)); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Ignoring contexts in %s: %v", d, err)
+			continue
+		}
+		s.readOnly = append(s.readOnly, New(d, cfg))
+	}
+	return s
+}
+
+// List returns all contexts of all stores.
+func (s *LayeredStore) List() ([]Metadata, error) {
+	res, err := s.writable.List()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(res))
+	for _, m := range res {
+		seen[m.Name] = struct{}{}
+	}
+	for _, ro := range s.readOnly {
+		list, err := ro.List()
+		if err != nil {
+			logrus.Warnf("Ignoring contexts in %s: %v", ro.root(), err)
+			continue
+		}
+		for _, m := range list {
+			if _, ok := seen[m.Name]; ok {
+				continue
+			}
+			seen[m.Name] = struct{}{}
+			res = append(res, m)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return sortorder.NaturalLess(res[i].Name, res[j].Name)
+	})
+	return res, nil
+}
+
+// GetMetadata returns the metadata for the context with the given name.
+// It returns an errdefs.ErrNotFound if the context was not found.
+func (s *LayeredStore) GetMetadata(name string) (Metadata, error) {
+	meta, err := s.writable.GetMetadata(name)
+	for _, ro := range s.readOnly {
+		if !errdefs.IsNotFound(err) {
+			break
+		}
+		meta, err = ro.GetMetadata(name)
+	}
+	return meta, err
+}
+
+// ListTLSFiles returns the list of TLS files present for each endpoint in the
+// context.
+func (s *LayeredStore) ListTLSFiles(name string) (map[string]EndpointFiles, error) {
+	st, err := s.storeOf(name)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return s.writable.ListTLSFiles(name)
+		}
+		return nil, err
+	}
+	return st.ListTLSFiles(name)
+}
+
+// GetTLSData reads, and returns the content of the given fileName for an endpoint.
+// It returns an errdefs.ErrNotFound if the file was not found.
+func (s *LayeredStore) GetTLSData(contextName, endpointName, fileName string) ([]byte, error) {
+	st, err := s.storeOf(contextName)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetTLSData(contextName, endpointName, fileName)
+}
+
+// GetStorageInfo returns the paths where the Metadata and TLS data are stored
+// for the context, and whether the context is read-only.
+func (s *LayeredStore) GetStorageInfo(contextName string) StorageInfo {
+	st, err := s.storeOf(contextName)
+	if err != nil {
+		return s.writable.GetStorageInfo(contextName)
+	}
+	info := st.GetStorageInfo(contextName)
+	info.ReadOnly = st != s.writable
+	return info
+}
+
+// CreateOrUpdate creates or updates metadata for the context. It fails if
+// the context is read-only.
+func (s *LayeredStore) CreateOrUpdate(meta Metadata) error {
+	if err := s.checkWritable(meta.Name); err != nil {
+		return err
+	}
+	return s.writable.CreateOrUpdate(meta)
+}
+
+// Remove deletes the context with the given name, if found. It fails if the
+// context is read-only.
+func (s *LayeredStore) Remove(name string) error {
+	if err := s.checkWritable(name); err != nil {
+		return err
+	}
+	return s.writable.Remove(name)
+}
+
+// ResetTLSMaterial removes TLS data for all endpoints in the context and replaces
+// it with the new data. It fails if the context is read-only.
+func (s *LayeredStore) ResetTLSMaterial(name string, data *ContextTLSData) error {
+	if err := s.checkWritable(name); err != nil {
+		return err
+	}
+	return s.writable.ResetTLSMaterial(name, data)
+}
+
+// ResetEndpointTLSMaterial removes TLS data for the given context and endpoint,
+// and replaces it with the new data. It fails if the context is read-only.
+func (s *LayeredStore) ResetEndpointTLSMaterial(contextName string, endpointName string, data *EndpointTLSData) error {
+	if err := s.checkWritable(contextName); err != nil {
+		return err
+	}
+	return s.writable.ResetEndpointTLSMaterial(contextName, endpointName, data)
+}
+
+// storeOf returns the store that holds the context with the given name.
+// It returns an errdefs.ErrNotFound if the context was not found.
+func (s *LayeredStore) storeOf(name string) (*ContextStore, error) {
+	_, err := s.writable.GetMetadata(name)
+	if err == nil || !errdefs.IsNotFound(err) {
+		return s.writable, err
+	}
+	for _, ro := range s.readOnly {
+		if _, err := ro.GetMetadata(name); err == nil {
+			return ro, nil
+		} else if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// checkWritable returns an error if the context with the given name is
+// stored in a read-only store.
+func (s *LayeredStore) checkWritable(name string) error {
+	st, err := s.storeOf(name)
+	if err != nil || st == s.writable {
+		// Contexts that do not exist yet are created in the writable store.
+		return nil
+	}
+	return errdefs.Forbidden(errors.Errorf("context %q is read-only: it is provided by %s", name, st.root()))
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestLayeredStore(t *testing.T) {
+	userDir, systemDir, otherDir := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, c := range []struct {
+		dir  string
+		name string
+		bar  string
+	}{
+		{dir: userDir, name: "mine", bar: "user"},
+		{dir: userDir, name: "shadowed", bar: "user"},
+		{dir: systemDir, name: "shared", bar: "system"},
+		{dir: systemDir, name: "shadowed", bar: "system"},
+		{dir: otherDir, name: "shared", bar: "other"},
+		{dir: otherDir, name: "other", bar: "other"},
+	} {
+		s := New(c.dir, testCfg)
+		assert.NilError(t, s.CreateOrUpdate(Metadata{
+			Name:      c.name,
+			Metadata:  context{Bar: c.bar},
+			Endpoints: map[string]any{"ep1": endpoint{Foo: c.bar}},
+		}))
+		assert.NilError(t, s.ResetTLSMaterial(c.name, &ContextTLSData{
+			Endpoints: map[string]EndpointTLSData{"ep1": {Files: map[string][]byte{"ca.pem": []byte(c.bar)}}},
+		}))
+	}
+
+	s := NewLayered(userDir, []string{systemDir, "", otherDir, filepath.Join(t.TempDir(), "missing")}, testCfg)
+
+	list, err := s.List()
+	assert.NilError(t, err)
+	names := make(map[string]string, len(list))
+	for _, m := range list {
+		names[m.Name] = m.Metadata.(context).Bar
+	}
+	assert.Check(t, is.DeepEqual(names, map[string]string{
+		"mine":     "user",
+		"other":    "other",
+		"shadowed": "user",
+		"shared":   "system",
+	}))
+
+	meta, err := s.GetMetadata("shared")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(meta.Metadata.(context).Bar, "system"))
+	data, err := s.GetTLSData("shared", "ep1", "ca.pem")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(data), "system"))
+	files, err := s.ListTLSFiles("shared")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(files, map[string]EndpointFiles{"ep1": {"ca.pem"}}))
+
+	_, err = s.GetMetadata("missing")
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	assert.Check(t, !s.GetStorageInfo("mine").ReadOnly)
+	assert.Check(t, !s.GetStorageInfo("shadowed").ReadOnly)
+	assert.Check(t, !s.GetStorageInfo("missing").ReadOnly)
+	info := s.GetStorageInfo("other")
+	assert.Check(t, info.ReadOnly)
+	assert.Check(t, is.Equal(filepath.Dir(info.MetadataPath), filepath.Join(otherDir, metadataDir)))
+
+	err = s.CreateOrUpdate(Metadata{Name: "shared", Metadata: context{Bar: "updated"}})
+	assert.Check(t, errdefs.IsForbidden(err))
+	assert.Check(t, is.ErrorContains(err, `context "shared" is read-only`))
+	assert.Check(t, errdefs.IsForbidden(s.Remove("other")))
+	assert.Check(t, errdefs.IsForbidden(s.ResetTLSMaterial("other", nil)))
+	assert.Check(t, errdefs.IsForbidden(s.ResetEndpointTLSMaterial("other", "ep1", nil)))
+
+	// New contexts, and contexts in the writable store can be updated.
+	assert.NilError(t, s.CreateOrUpdate(Metadata{Name: "new", Metadata: context{Bar: "user"}}))
+	assert.NilError(t, s.CreateOrUpdate(Metadata{Name: "shadowed", Metadata: context{Bar: "updated"}}))
+	assert.NilError(t, s.Remove("mine"))
+
+	// Removing a context from the writable store reveals the read-only
+	// context with the same name.
+	assert.NilError(t, s.Remove("shadowed"))
+	meta, err = s.GetMetadata("shadowed")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(meta.Metadata.(context).Bar, "system"))
+}
+
+func TestLayeredStoreUnreadable(t *testing.T) {
+	userDir, systemDir, unreadableDir := t.TempDir(), t.TempDir(), t.TempDir()
+	assert.NilError(t, New(systemDir, testCfg).CreateOrUpdate(Metadata{Name: "shared", Metadata: context{Bar: "system"}}))
+	// The metadata directory cannot be read if it's a file.
+	assert.NilError(t, os.WriteFile(filepath.Join(unreadableDir, metadataDir), nil, 0o644))
+
+	s := NewLayered(userDir, []string{unreadableDir, systemDir}, testCfg)
+	list, err := s.List()
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(list, 1))
+	assert.Check(t, is.Equal(list[0].Name, "shared"))
+
+	_, err = s.GetMetadata("new")
+	assert.Check(t, errdefs.IsNotFound(err))
+	assert.NilError(t, s.CreateOrUpdate(Metadata{Name: "new", Metadata: context{Bar: "user"}}))
+}
@@ -82,6 +82,10 @@ type Metadata struct {
 type StorageInfo struct {
 	MetadataPath string
 	TLSPath      string
+
+	// ReadOnly indicates that the context is stored in a read-only store
+	// (see LayeredStore), and cannot be updated or removed.
+	ReadOnly bool `json:",omitempty"`
 }
 
 // EndpointTLSData represents tls data for a given endpoint
@@ -217,6 +221,11 @@ func (s *ContextStore) GetStorageInfo(contextName string) StorageInfo {
 	}
 }
 
+// root returns the directory of the store.
+func (s *ContextStore) root() string {
+	return filepath.Dir(s.meta.root)
+}
+
 // ValidateContextName checks a context name is valid.
 func ValidateContextName(name string) error {
 	if name == "" {
@@ -320,12 +329,23 @@ func getImportContentType(r *bufio.Reader) (string, error) {
 	if err != nil && err != io.EOF {
 		return "", err
 	}
+	if bytes.HasPrefix(head, []byte(encryptedMagic)) {
+		return encryptedType, nil
+	}
 
 	return http.DetectContentType(head), nil
 }
 
-// Import imports an exported context into a store
+// Import imports an exported context into a store. Encrypted contexts are
+// rejected; use ImportWithPassphrase to import encrypted contexts.
 func Import(name string, s Writer, reader io.Reader) error {
+	return ImportWithPassphrase(name, s, reader, nil)
+}
+
+// ImportWithPassphrase imports an exported context into a store. If the
+// exported context is encrypted (see ExportEncrypted), the passphrase
+// function is called to get the passphrase to decrypt it.
+func ImportWithPassphrase(name string, s Writer, reader io.Reader, passphrase func() ([]byte, error)) error {
 	// Buffered reader will not advance the buffer, needed to determine content type
 	r := bufio.NewReader(reader)
 
@@ -334,6 +354,24 @@ func Import(name string, s Writer, reader io.Reader) error {
 		return err
 	}
 	switch importContentType {
+	case encryptedType:
+		if passphrase == nil {
+			return errdefs.InvalidParameter(errors.New("context is encrypted: a passphrase is required to import it"))
+		}
+		data, err := io.ReadAll(&LimitedReader{R: r, N: maxEncryptedSizeToImport})
+		if err != nil {
+			return err
+		}
+		p, err := passphrase()
+		if err != nil {
+			return err
+		}
+		plain, err := decrypt(data, p)
+		if err != nil {
+			return err
+		}
+		// Encrypted contexts cannot be nested.
+		return Import(name, s, bytes.NewReader(plain))
 	case zipType:
 		return importZip(name, s, r)
 	default:
@@ -0,0 +1,369 @@
+// Package logfilter implements client-side filtering and pretty-printing of
+// container and service logs.
+package logfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/internal/tui"
+	"github.com/morikuni/aec"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// Options holds the options for filtering logs.
+type Options struct {
+	// Grep is a regular expression that lines must match.
+	Grep string
+
+	// Level is the minimum level of lines to show.
+	Level string
+
+	// JSONFields are "key=value" pairs that JSON lines must match. Nested
+	// fields are selected using dots (for example, "http.status=500").
+	JSONFields []string
+
+	// PrettyJSON renders JSON lines in a human-readable format.
+	PrettyJSON bool
+}
+
+// InstallFlags adds the flags for the filtering options to the given flag-set.
+func (o *Options) InstallFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Grep, "grep", "", "Only show lines matching a regular expression")
+	flags.StringVar(&o.Level, "level", "", `Only show lines with at least the given level ("trace", "debug", "info", "warn", "error", "fatal")`)
+	flags.StringArrayVar(&o.JSONFields, "json-field", nil, "Only show JSON lines where a field has the given value (key=value)")
+	flags.BoolVar(&o.PrettyJSON, "pretty-json", false, "Print JSON lines in a human-readable format")
+}
+
+// Enabled returns whether any filtering or formatting is enabled.
+func (o Options) Enabled() bool {
+	return o.Grep != "" || o.Level != "" || len(o.JSONFields) > 0 || o.PrettyJSON
+}
+
+type field struct {
+	path  []string
+	value string
+}
+
+// Filter filters and formats log lines.
+type Filter struct {
+	grep   *regexp.Regexp
+	level  int
+	fields []field
+	pretty bool
+}
+
+// New creates a new Filter from the given options.
+func New(o Options) (*Filter, error) {
+	f := &Filter{level: -1, pretty: o.PrettyJSON}
+	if o.Grep != "" {
+		re, err := regexp.Compile(o.Grep)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid --grep expression")
+		}
+		f.grep = re
+	}
+	if o.Level != "" {
+		lvl, ok := parseLevel(o.Level)
+		if !ok {
+			return nil, errors.Errorf("invalid --level: %q", o.Level)
+		}
+		f.level = lvl
+	}
+	for _, kv := range o.JSONFields {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, errors.Errorf("invalid --json-field: %q: must be formatted as key=value", kv)
+		}
+		f.fields = append(f.fields, field{path: strings.Split(k, "."), value: v})
+	}
+	return f, nil
+}
+
+// Writer returns a writer that filters and formats the log lines written
+// to it before writing them to out. Colors are only used if out is a terminal.
+func (f *Filter) Writer(out *streams.Out) *Writer {
+	return &Writer{f: f, out: out, output: tui.NewOutput(out)}
+}
+
+// Writer is an [io.Writer] that filters and formats log lines. Incomplete
+// lines are buffered until they are terminated, or until [Writer.Flush] is
+// called.
+type Writer struct {
+	f      *Filter
+	out    io.Writer
+	output tui.Output
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		if err := w.writeLine(w.buf[:idx+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the remaining incomplete line, if any.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := w.buf
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *Writer) writeLine(line []byte) error {
+	out, ok := w.f.apply(line, w.output)
+	if !ok {
+		return nil
+	}
+	_, err := w.out.Write(out)
+	return err
+}
+
+// Apply filters and formats a single line, and returns the (formatted) line,
+// and whether it should be printed. Lines that are prefixed by the CLI, such
+// as with the name of the container when merging the logs of containers,
+// should be filtered before the prefix is added, so that the prefix is not
+// matched by the filter.
+func (f *Filter) Apply(line []byte, output tui.Output) ([]byte, bool) {
+	return f.apply(line, output)
+}
+
+// apply filters a single line, and returns the (formatted) line, and whether
+// it should be printed.
+func (f *Filter) apply(line []byte, output tui.Output) ([]byte, bool) {
+	if f.grep != nil && !f.grep.Match(line) {
+		return nil, false
+	}
+
+	prefix, obj := splitJSON(line)
+	if obj == nil {
+		if len(f.fields) > 0 {
+			return nil, false
+		}
+		if f.level >= 0 {
+			lvl, ok := textLevel(line)
+			if !ok || lvl < f.level {
+				return nil, false
+			}
+		}
+		return line, true
+	}
+
+	for _, fl := range f.fields {
+		v, ok := lookup(obj, fl.path)
+		if !ok || stringValue(v) != fl.value {
+			return nil, false
+		}
+	}
+	if f.level >= 0 {
+		lvl, ok := jsonLevel(obj)
+		if !ok || lvl < f.level {
+			return nil, false
+		}
+	}
+	if !f.pretty {
+		return line, true
+	}
+	return append(prefix, []byte(prettyPrint(obj, output)+"\n")...), true
+}
+
+// splitJSON splits a line into the prefix added by the CLI or daemon (such as
+// timestamps, or the name of the container), and the JSON object that follows
+// it. It returns a nil object if the line does not end with a JSON object.
+func splitJSON(line []byte) ([]byte, map[string]interface{}) {
+	idx := bytes.IndexByte(line, '{')
+	if idx < 0 {
+		return nil, nil
+	}
+	msg := bytes.TrimSpace(line[idx:])
+	if len(msg) == 0 || msg[len(msg)-1] != '}' {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil || dec.More() {
+		return nil, nil
+	}
+	return append([]byte(nil), line[:idx]...), obj
+}
+
+func lookup(obj map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = obj
+	for _, p := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func stringValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+var (
+	levelKeys = []string{"level", "lvl", "severity", "log.level"}
+	timeKeys  = []string{"time", "ts", "timestamp", "@timestamp"}
+	msgKeys   = []string{"msg", "message"}
+)
+
+var levels = map[string]int{
+	"trace":    0,
+	"debug":    1,
+	"info":     2,
+	"notice":   2,
+	"warn":     3,
+	"warning":  3,
+	"error":    4,
+	"err":      4,
+	"fatal":    5,
+	"critical": 5,
+	"crit":     5,
+	"panic":    6,
+}
+
+// levelNames are the canonical names of levels, indexed by level.
+var levelNames = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+
+func parseLevel(s string) (int, bool) {
+	lvl, ok := levels[strings.ToLower(s)]
+	return lvl, ok
+}
+
+// jsonLevel returns the level of a JSON log line. Numeric levels are
+// interpreted as used by bunyan and pino (10 = trace, ..., 60 = fatal).
+func jsonLevel(obj map[string]interface{}) (int, bool) {
+	for _, k := range levelKeys {
+		v, ok := obj[k]
+		if !ok {
+			continue
+		}
+		if n, ok := v.(json.Number); ok {
+			i, err := n.Int64()
+			if err != nil {
+				return 0, false
+			}
+			return int(i/10) - 1, true
+		}
+		return parseLevel(stringValue(v))
+	}
+	return 0, false
+}
+
+var textLevelRe = regexp.MustCompile(`\blevel="?([a-zA-Z]+)`)
+
+// textLevel returns the level of a non-JSON log line in logfmt format.
+func textLevel(line []byte) (int, bool) {
+	m := textLevelRe.FindSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	return parseLevel(string(m[1]))
+}
+
+func levelColor(lvl int) aec.ANSI {
+	switch {
+	case lvl >= 4:
+		return aec.RedF
+	case lvl == 3:
+		return aec.YellowF
+	case lvl == 2:
+		return aec.CyanF
+	default:
+		return tui.ColorTertiary
+	}
+}
+
+// prettyPrint renders a JSON log line as "TIME LEVEL message key=value ...".
+func prettyPrint(obj map[string]interface{}, output tui.Output) string {
+	used := make(map[string]bool)
+	first := func(keys []string) (string, bool) {
+		for _, k := range keys {
+			if v, ok := obj[k]; ok {
+				used[k] = true
+				return stringValue(v), true
+			}
+		}
+		return "", false
+	}
+
+	var parts []string
+	if t, ok := first(timeKeys); ok {
+		parts = append(parts, output.Color(tui.ColorTertiary).Apply(t))
+	}
+	if l, ok := first(levelKeys); ok {
+		clr := tui.ColorSecondary
+		if lvl, known := jsonLevel(obj); known {
+			clr = levelColor(lvl)
+			if lvl >= 0 && lvl < len(levelNames) {
+				// Use the name of numeric levels.
+				l = levelNames[lvl]
+			}
+		}
+		parts = append(parts, output.Color(clr).Apply(fmt.Sprintf("%-5s", strings.ToUpper(l))))
+	}
+	if m, ok := first(msgKeys); ok {
+		parts = append(parts, output.Color(tui.ColorPrimary).Apply(m))
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		if !used[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := stringValue(obj[k])
+		if _, isString := obj[k].(string); isString && strings.ContainsAny(v, " \t\"=") {
+			v = strconv.Quote(v)
+		}
+		parts = append(parts, output.Color(tui.ColorTertiary).Apply(k+"=")+v)
+	}
+	return strings.Join(parts, " ")
+}
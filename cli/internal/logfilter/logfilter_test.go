@@ -0,0 +1,109 @@
+// FIXME(thaJeztah): remove once we are a module; the go:build directive prevents go from downgrading language version to go1.16:
+//go:build go1.22
+
+package logfilter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/docker/cli/cli/streams"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+const testLogs = `plain text line
+time="2025-01-01T00:00:00Z" level=warning msg="disk almost full"
+{"time":"2025-01-01T00:00:01Z","level":"info","msg":"request handled","http":{"status":200},"path":"/healthz"}
+{"time":"2025-01-01T00:00:02Z","level":"error","msg":"request failed","http":{"status":500},"error":"connection refused"}
+2025-01-01T00:00:03.000000000Z {"level":50,"msg":"pino error"}
+`
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		doc      string
+		options  Options
+		expected string
+	}{
+		{
+			doc:      "no filters",
+			expected: testLogs,
+		},
+		{
+			doc:     "grep",
+			options: Options{Grep: `request (handled|failed)`},
+			expected: `{"time":"2025-01-01T00:00:01Z","level":"info","msg":"request handled","http":{"status":200},"path":"/healthz"}
+{"time":"2025-01-01T00:00:02Z","level":"error","msg":"request failed","http":{"status":500},"error":"connection refused"}
+`,
+		},
+		{
+			doc:     "level",
+			options: Options{Level: "warn"},
+			expected: `time="2025-01-01T00:00:00Z" level=warning msg="disk almost full"
+{"time":"2025-01-01T00:00:02Z","level":"error","msg":"request failed","http":{"status":500},"error":"connection refused"}
+2025-01-01T00:00:03.000000000Z {"level":50,"msg":"pino error"}
+`,
+		},
+		{
+			doc:     "nested json field",
+			options: Options{JSONFields: []string{"http.status=500"}},
+			expected: `{"time":"2025-01-01T00:00:02Z","level":"error","msg":"request failed","http":{"status":500},"error":"connection refused"}
+`,
+		},
+		{
+			doc:     "pretty json",
+			options: Options{PrettyJSON: true},
+			expected: `plain text line
+time="2025-01-01T00:00:00Z" level=warning msg="disk almost full"
+2025-01-01T00:00:01Z INFO  request handled http={"status":200} path=/healthz
+2025-01-01T00:00:02Z ERROR request failed error="connection refused" http={"status":500}
+2025-01-01T00:00:03.000000000Z ERROR pino error
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			f, err := New(tc.options)
+			assert.NilError(t, err)
+
+			var buf bytes.Buffer
+			w := f.Writer(streams.NewOut(&buf))
+
+			// Write in small chunks to verify that lines are reassembled.
+			in := []byte(testLogs)
+			for len(in) > 0 {
+				n := min(7, len(in))
+				_, err := w.Write(in[:n])
+				assert.NilError(t, err)
+				in = in[n:]
+			}
+			assert.NilError(t, w.Flush())
+			assert.Check(t, is.Equal(buf.String(), tc.expected))
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	f, err := New(Options{Grep: "partial"})
+	assert.NilError(t, err)
+
+	var buf bytes.Buffer
+	w := f.Writer(streams.NewOut(&buf))
+	_, err = io.WriteString(w, "a partial line")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(buf.String(), ""))
+	assert.NilError(t, w.Flush())
+	assert.Check(t, is.Equal(buf.String(), "a partial line"))
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	_, err := New(Options{Grep: "("})
+	assert.Check(t, is.ErrorContains(err, "invalid --grep expression"))
+
+	_, err = New(Options{Level: "loud"})
+	assert.Check(t, is.Error(err, `invalid --level: "loud"`))
+
+	_, err = New(Options{JSONFields: []string{"status"}})
+	assert.Check(t, is.Error(err, `invalid --json-field: "status": must be formatted as key=value`))
+}
@@ -21,11 +21,13 @@
 type OAuthAPI interface {
 	GetDeviceCode(ctx context.Context, audience string) (State, error)
 	WaitForDeviceToken(ctx context.Context, state State) (TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (TokenResponse, error)
 	RevokeToken(ctx context.Context, refreshToken string) error
 	GetAutoPAT(ctx context.Context, audience string, res TokenResponse) (string, error)
 }
 
-// API represents API interactions with Auth0.
+// API represents API interactions with Auth0, or with another OAuth 2.0
+// tenant that supports the device authorization grant (RFC 8628).
 type API struct {
 	// TenantURL is the base used for each request to Auth0.
 	TenantURL string
@@ -33,6 +35,33 @@ type API struct {
 	ClientID string
 	// Scopes are the scopes that are requested during the device auth flow.
 	Scopes []string
+
+	// DeviceAuthorizationURL, TokenURL, and RevocationURL are the endpoints
+	// of the tenant. If empty, the Auth0 endpoints under TenantURL are used.
+	DeviceAuthorizationURL string
+	TokenURL               string
+	RevocationURL          string
+}
+
+func (a API) deviceAuthorizationURL() string {
+	if a.DeviceAuthorizationURL != "" {
+		return a.DeviceAuthorizationURL
+	}
+	return a.TenantURL + "/oauth/device/code"
+}
+
+func (a API) tokenURL() string {
+	if a.TokenURL != "" {
+		return a.TokenURL
+	}
+	return a.TenantURL + "/oauth/token"
+}
+
+func (a API) revocationURL() string {
+	if a.RevocationURL != "" || a.TenantURL == "" {
+		return a.RevocationURL
+	}
+	return a.TenantURL + "/oauth/revoke"
 }
 
 // TokenResponse represents the response of the /oauth/token route.
@@ -55,12 +84,15 @@ type TokenResponse struct {
 func (a API) GetDeviceCode(ctx context.Context, audience string) (State, error) {
 	data := url.Values{
 		"client_id": {a.ClientID},
-		"audience":  {audience},
 		"scope":     {strings.Join(a.Scopes, " ")},
 	}
+	if audience != "" {
+		// the audience parameter is not part of RFC 8628, but is required
+		// by Auth0 to issue an access token for the given API.
+		data.Set("audience", audience)
+	}
 
-	deviceCodeURL := a.TenantURL + "/oauth/device/code"
-	resp, err := postForm(ctx, deviceCodeURL, strings.NewReader(data.Encode()))
+	resp, err := postForm(ctx, a.deviceAuthorizationURL(), strings.NewReader(data.Encode()))
 	if err != nil {
 		return State{}, err
 	}
@@ -125,7 +157,13 @@ func (a API) WaitForDeviceToken(ctx context.Context, state State) (TokenResponse
 			}
 
 			if res.Error != nil {
-				if *res.Error == "authorization_pending" {
+				switch *res.Error {
+				case "authorization_pending":
+					continue
+				case "slow_down":
+					// the tenant asks us to poll less often; increase the
+					// interval by 5 seconds, as specified in RFC 8628.
+					state.Interval = int(state.IntervalDuration()/time.Second) + 5
 					continue
 				}
 
@@ -165,9 +203,7 @@ func (a API) getDeviceToken(ctx context.Context, state State) (TokenResponse, er
 		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 		"device_code": {state.DeviceCode},
 	}
-	oauthTokenURL := a.TenantURL + "/oauth/token"
-
-	resp, err := postForm(ctx, oauthTokenURL, strings.NewReader(data.Encode()))
+	resp, err := postForm(ctx, a.tokenURL(), strings.NewReader(data.Encode()))
 	if err != nil {
 		return TokenResponse{}, fmt.Errorf("failed to get tokens: %w", err)
 	}
@@ -187,15 +223,50 @@ func (a API) getDeviceToken(ctx context.Context, state State) (TokenResponse, er
 	return res, nil
 }
 
+// Refresh uses the refresh token to get new access/refresh tokens for the user
+// from the tenant. Depending on the tenant, the refresh token that is returned
+// may be empty, in which case the current refresh token remains valid.
+func (a API) Refresh(ctx context.Context, refreshToken string) (TokenResponse, error) {
+	data := url.Values{
+		"client_id":     {a.ClientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	resp, err := postForm(ctx, a.tokenURL(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to refresh tokens: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return TokenResponse{}, tryDecodeOAuthError(resp)
+	}
+
+	var res TokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return res, nil
+}
+
 // RevokeToken revokes a refresh token with the tenant so that it can no longer
-// be used to get new tokens.
+// be used to get new tokens. It does nothing if the tenant has no revocation
+// endpoint.
 func (a API) RevokeToken(ctx context.Context, refreshToken string) error {
+	revokeURL := a.revocationURL()
+	if revokeURL == "" {
+		return nil
+	}
+
 	data := url.Values{
 		"client_id": {a.ClientID},
 		"token":     {refreshToken},
 	}
 
-	revokeURL := a.TenantURL + "/oauth/revoke"
 	resp, err := postForm(ctx, revokeURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return err
@@ -218,12 +289,16 @@ func postForm(ctx context.Context, reqURL string, data io.Reader) (*http.Respons
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	cliVersion := strings.ReplaceAll(version.Version, ".", "_")
-	req.Header.Set("User-Agent", fmt.Sprintf("docker-cli:%s:%s-%s", cliVersion, runtime.GOOS, runtime.GOARCH))
+	req.Header.Set("User-Agent", userAgent())
 
 	return http.DefaultClient.Do(req)
 }
 
+func userAgent() string {
+	cliVersion := strings.ReplaceAll(version.Version, ".", "_")
+	return fmt.Sprintf("docker-cli:%s:%s-%s", cliVersion, runtime.GOOS, runtime.GOARCH)
+}
+
 func (API) GetAutoPAT(ctx context.Context, audience string, res TokenResponse) (string, error) {
 	patURL := audience + "/v2/access-tokens/desktop-generate"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, patURL, nil)
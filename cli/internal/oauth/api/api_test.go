@@ -53,6 +53,32 @@ func TestGetDeviceCode(t *testing.T) {
 		assert.Equal(t, path, "/oauth/device/code")
 	})
 
+	t.Run("device authorization endpoint", func(t *testing.T) {
+		t.Parallel()
+		var hasAudience bool
+		var path string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			_, hasAudience = r.Form["audience"]
+			path = r.URL.Path
+
+			_, _ = w.Write([]byte(`{"device_code":"aDeviceCode","user_code":"aUserCode","verification_uri":"https://example.com/activate"}`))
+		}))
+		defer ts.Close()
+		api := API{
+			ClientID:               "aClientID",
+			DeviceAuthorizationURL: ts.URL + "/device/authorize",
+		}
+
+		state, err := api.GetDeviceCode(context.Background(), "")
+		assert.NilError(t, err)
+
+		assert.Equal(t, path, "/device/authorize")
+		assert.Equal(t, hasAudience, false)
+		assert.Equal(t, state.CodeEntryURI(), "https://example.com/activate")
+		assert.Equal(t, state.BrowserURI(), "https://example.com/activate")
+	})
+
 	t.Run("error w/ description", func(t *testing.T) {
 		t.Parallel()
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -241,6 +267,64 @@ func TestWaitForDeviceToken(t *testing.T) {
 	})
 }
 
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		expectedToken := TokenResponse{
+			AccessToken:  "a-new-access-token",
+			RefreshToken: "a-new-refresh-token",
+			ExpiresIn:    3600,
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/oauth/token", r.URL.Path)
+			assert.Equal(t, r.FormValue("client_id"), "aClientID")
+			assert.Equal(t, r.FormValue("grant_type"), "refresh_token")
+			assert.Equal(t, r.FormValue("refresh_token"), "v1.a-refresh-token")
+
+			jsonToken, err := json.Marshal(expectedToken)
+			assert.NilError(t, err)
+
+			_, _ = w.Write(jsonToken)
+		}))
+		defer ts.Close()
+		api := API{
+			TenantURL: ts.URL,
+			ClientID:  "aClientID",
+			Scopes:    []string{"bork", "meow"},
+		}
+
+		token, err := api.Refresh(context.Background(), "v1.a-refresh-token")
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, token, expectedToken)
+	})
+
+	t.Run("error w/ description", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			jsonState, err := json.Marshal(TokenResponse{
+				ErrorDescription: "invalid refresh token",
+			})
+			assert.NilError(t, err)
+
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write(jsonState)
+		}))
+		defer ts.Close()
+		api := API{
+			TenantURL: ts.URL,
+			ClientID:  "aClientID",
+			Scopes:    []string{"bork", "meow"},
+		}
+
+		_, err := api.Refresh(context.Background(), "v1.a-refresh-token")
+		assert.ErrorContains(t, err, "invalid refresh token")
+	})
+}
+
 func TestRevoke(t *testing.T) {
 	t.Parallel()
 
@@ -265,6 +349,17 @@ func TestRevoke(t *testing.T) {
 		assert.NilError(t, err)
 	})
 
+	t.Run("no revocation endpoint", func(t *testing.T) {
+		t.Parallel()
+		api := API{
+			ClientID: "aClientID",
+			TokenURL: "https://example.com/token",
+		}
+
+		err := api.RevokeToken(context.Background(), "v1.a-refresh-token")
+		assert.NilError(t, err)
+	})
+
 	t.Run("unexpected response", func(t *testing.T) {
 		t.Parallel()
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProviderMetadata is the metadata of an OpenID Connect provider that is
+// needed for the device authorization grant (RFC 8628).
+type ProviderMetadata struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint,omitempty"`
+}
+
+// Discover fetches the metadata of the OpenID Connect provider with the given
+// issuer URL from its discovery document, as specified in OpenID Connect
+// Discovery 1.0. The issuer must use https, except for loopback addresses.
+func Discover(ctx context.Context, issuer string) (ProviderMetadata, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return ProviderMetadata{}, fmt.Errorf("invalid issuer URL: %s", issuer)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && isLoopback(u.Hostname())) {
+		return ProviderMetadata{}, fmt.Errorf("invalid issuer URL: %s: issuer must use https", issuer)
+	}
+
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return ProviderMetadata{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ProviderMetadata{}, fmt.Errorf("failed to get OpenID Connect configuration: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return ProviderMetadata{}, fmt.Errorf("failed to get OpenID Connect configuration: unexpected response from %s: %s", discoveryURL, resp.Status)
+	}
+
+	var metadata ProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return ProviderMetadata{}, fmt.Errorf("failed to decode OpenID Connect configuration: %w", err)
+	}
+
+	// the issuer in the discovery document must be identical to the issuer
+	// URL that was used to fetch it, to prevent impersonation.
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return ProviderMetadata{}, fmt.Errorf("issuer %q in OpenID Connect configuration does not match %q", metadata.Issuer, issuer)
+	}
+	if metadata.DeviceAuthorizationEndpoint == "" {
+		return ProviderMetadata{}, errors.New("OpenID Connect provider does not support the device authorization grant")
+	}
+	if metadata.TokenEndpoint == "" {
+		return ProviderMetadata{}, errors.New("OpenID Connect configuration has no token endpoint")
+	}
+	return metadata, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
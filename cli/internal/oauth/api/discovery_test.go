@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(t *testing.T, metadata func(issuer string) ProviderMetadata) *httptest.Server {
+		t.Helper()
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Check(t, is.Equal(r.Method, http.MethodGet))
+			if r.URL.Path != "/tenant/.well-known/openid-configuration" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(metadata(ts.URL + "/tenant"))
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(issuer string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:                      issuer,
+				DeviceAuthorizationEndpoint: issuer + "/device",
+				TokenEndpoint:               issuer + "/token",
+			}
+		})
+
+		metadata, err := Discover(context.Background(), ts.URL+"/tenant/")
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(metadata, ProviderMetadata{
+			Issuer:                      ts.URL + "/tenant",
+			DeviceAuthorizationEndpoint: ts.URL + "/tenant/device",
+			TokenEndpoint:               ts.URL + "/tenant/token",
+		}))
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:                      "https://idp.example.com",
+				DeviceAuthorizationEndpoint: "https://idp.example.com/device",
+				TokenEndpoint:               "https://idp.example.com/token",
+			}
+		})
+
+		_, err := Discover(context.Background(), ts.URL+"/tenant")
+		assert.Check(t, is.ErrorContains(err, `issuer "https://idp.example.com" in OpenID Connect configuration does not match`))
+	})
+
+	t.Run("no device authorization endpoint", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(issuer string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:        issuer,
+				TokenEndpoint: issuer + "/token",
+			}
+		})
+
+		_, err := Discover(context.Background(), ts.URL+"/tenant")
+		assert.Check(t, is.Error(err, "OpenID Connect provider does not support the device authorization grant"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, nil)
+
+		_, err := Discover(context.Background(), ts.URL)
+		assert.Check(t, is.ErrorContains(err, "404 Not Found"))
+	})
+
+	t.Run("insecure issuer", func(t *testing.T) {
+		t.Parallel()
+		_, err := Discover(context.Background(), "http://idp.example.com")
+		assert.Check(t, is.Error(err, "invalid issuer URL: http://idp.example.com: issuer must use https"))
+	})
+}
@@ -1,21 +1,51 @@
 package api
 
 import (
+	"strings"
 	"time"
 )
 
+// defaultInterval is the interval between polling events if the tenant does
+// not specify one, as specified in RFC 8628.
+const defaultInterval = 5 * time.Second
+
 // State represents the state of exchange after submitting.
 type State struct {
 	DeviceCode      string `json:"device_code"`
 	UserCode        string `json:"user_code"`
 	VerificationURI string `json:"verification_uri_complete"`
-	ExpiresIn       int    `json:"expires_in"`
-	Interval        int    `json:"interval"`
+	// BaseVerificationURI is the URI at which the user enters the user code.
+	// Unlike VerificationURI, it does not include the user code, and it is
+	// always returned by tenants that implement RFC 8628.
+	BaseVerificationURI string `json:"verification_uri,omitempty"`
+	ExpiresIn           int    `json:"expires_in"`
+	Interval            int    `json:"interval"`
+}
+
+// CodeEntryURI returns the URI at which the user enters the user code.
+func (s State) CodeEntryURI() string {
+	if s.BaseVerificationURI != "" {
+		return s.BaseVerificationURI
+	}
+	return strings.Split(s.VerificationURI, "?")[0]
+}
+
+// BrowserURI returns the URI to open in the user's browser. It includes the
+// user code if the tenant supports it, so that the user does not have to
+// enter it.
+func (s State) BrowserURI() string {
+	if s.VerificationURI != "" {
+		return s.VerificationURI
+	}
+	return s.BaseVerificationURI
 }
 
 // IntervalDuration returns the duration that should be waited between each auth
 // polling event.
 func (s State) IntervalDuration() time.Duration {
+	if s.Interval <= 0 {
+		return defaultInterval
+	}
 	return time.Second * time.Duration(s.Interval)
 }
 
@@ -14,6 +14,13 @@ type Claims struct {
 
 	// Scope is the scopes for the claims as a string that is space delimited.
 	Scope string `json:"scope,omitempty"`
+
+	// PreferredUsername is the standard OpenID Connect claim for the user's
+	// username.
+	PreferredUsername string `json:"preferred_username,omitempty"`
+
+	// Email is the standard OpenID Connect claim for the user's email address.
+	Email string `json:"email,omitempty"`
 }
 
 // DomainClaims represents a custom claim data set that doesn't change the spec
@@ -8,6 +8,7 @@
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/cli/cli/config/types"
@@ -31,6 +32,15 @@ type OAuthManager struct {
 	clientID    string
 	api         api.OAuthAPI
 	openBrowser func(string) error
+
+	// serverAddress is the registry that the credentials are for. If empty,
+	// the credentials are for Docker Hub.
+	serverAddress string
+	// issuer is the issuer URL of the OpenID Connect provider, if any.
+	issuer string
+	// expiry is when the access token expires, according to the tenant. It is
+	// used if the access token is opaque, instead of a JWT.
+	expiry time.Time
 }
 
 // OAuthManagerOptions are the options used for New to create a new auth manager.
@@ -42,6 +52,18 @@ type OAuthManagerOptions struct {
 	Tenant      string
 	DeviceName  string
 	OpenBrowser func(string) error
+
+	// ServerAddress is the registry to authenticate to. It defaults to Docker
+	// Hub. For other registries, the access token is used as password, instead
+	// of creating a Hub PAT.
+	ServerAddress string
+	// Provider holds the endpoints of the tenant. If not set, the Auth0
+	// endpoints of the Tenant are used.
+	Provider api.ProviderMetadata
+	// Issuer is the issuer URL of the OpenID Connect provider. If set, it is
+	// stored with the refresh token, and the refresh token is only used with
+	// the same issuer.
+	Issuer string
 }
 
 func New(options OAuthManagerOptions) *OAuthManager {
@@ -59,17 +81,27 @@ func New(options OAuthManagerOptions) *OAuthManager {
 		openBrowser = browser.OpenURL
 	}
 
+	var tenantURL string
+	if options.Tenant != "" {
+		tenantURL = "https://" + options.Tenant
+	}
+
 	return &OAuthManager{
 		clientID: options.ClientID,
 		audience: options.Audience,
 		tenant:   options.Tenant,
 		store:    options.Store,
 		api: api.API{
-			TenantURL: "https://" + options.Tenant,
-			ClientID:  options.ClientID,
-			Scopes:    scopes,
+			TenantURL:              tenantURL,
+			ClientID:               options.ClientID,
+			Scopes:                 scopes,
+			DeviceAuthorizationURL: options.Provider.DeviceAuthorizationEndpoint,
+			TokenURL:               options.Provider.TokenEndpoint,
+			RevocationURL:          options.Provider.RevocationEndpoint,
 		},
-		openBrowser: openBrowser,
+		openBrowser:   openBrowser,
+		serverAddress: options.ServerAddress,
+		issuer:        options.Issuer,
 	}
 }
 
@@ -79,7 +111,8 @@ func New(options OAuthManagerOptions) *OAuthManager {
 // printing instructions to the provided writer and attempting to open the
 // browser for the user to authenticate.
 // After the user completes the browser login, LoginDevice uses the retrieved
-// tokens to create a Hub PAT which is returned to the caller.
+// tokens to create a Hub PAT which is returned to the caller. For registries
+// other than Docker Hub, the access token is returned as password instead.
 // The retrieved tokens are stored in the credentials store (under a separate
 // key), and the refresh token is concatenated with the client ID.
 func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.AuthConfig, error) {
@@ -103,9 +136,13 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	default:
 		out = tui.NewOutput(streams.NewOut(w))
 	}
-	out.PrintNote("To sign in with credentials on the command line, use 'docker login -u <username>'\n")
+	loginCmd := "docker login -u <username>"
+	if !m.isHub() {
+		loginCmd += " " + m.serverAddress
+	}
+	out.PrintNote("To sign in with credentials on the command line, use '%s'\n", loginCmd)
 	_, _ = fmt.Fprintf(w, "\nYour one-time device confirmation code is: "+aec.Bold.Apply("%s\n"), state.UserCode)
-	_, _ = fmt.Fprintf(w, aec.Bold.Apply("Press ENTER")+" to open your browser or submit your device code here: "+aec.Underline.Apply("%s\n"), strings.Split(state.VerificationURI, "?")[0])
+	_, _ = fmt.Fprintf(w, aec.Bold.Apply("Press ENTER")+" to open your browser or submit your device code here: "+aec.Underline.Apply("%s\n"), state.CodeEntryURI())
 
 	tokenResChan := make(chan api.TokenResponse)
 	waitForTokenErrChan := make(chan error)
@@ -120,8 +157,11 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 
 	go func() {
 		reader := bufio.NewReader(os.Stdin)
-		_, _ = reader.ReadString('\n')
-		_ = m.openBrowser(state.VerificationURI)
+		if _, err := reader.ReadString('\n'); err != nil {
+			// stdin was closed, or is not a terminal
+			return
+		}
+		_ = m.openBrowser(state.BrowserURI())
 	}()
 
 	_, _ = fmt.Fprint(w, "\nWaiting for authentication in the browser…\n")
@@ -134,6 +174,96 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	case tokenRes = <-tokenResChan:
 	}
 
+	return m.credentialsFromTokens(ctx, tokenRes)
+}
+
+// Logout fetches the refresh token from the store and revokes it
+// with the configured oauth tenant. The stored access and refresh
+// tokens are then erased from the store.
+// If the refresh token is not found in the store, an error is not
+// returned.
+func (m *OAuthManager) Logout(ctx context.Context) error {
+	refreshToken, err := m.getStoredRefreshToken()
+	if errors.Is(err, ErrIssuerMismatch) {
+		// the token was issued by another provider, so erase it from the
+		// store, but don't send it to this provider to revoke it.
+		if err := m.eraseTokensFromStore(); err != nil {
+			return fmt.Errorf("failed to erase tokens: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		// the token wasn't stored by the CLI, so don't revoke it
+		// or erase it from the store/error
+		return nil
+	}
+	// erase the token from the store first, that way
+	// if the revoke fails, the user can try to logout again
+	if err := m.eraseTokensFromStore(); err != nil {
+		return fmt.Errorf("failed to erase tokens: %w", err)
+	}
+	if err := m.api.RevokeToken(ctx, refreshToken); err != nil {
+		return fmt.Errorf("credentials erased successfully, but there was a failure to revoke the OAuth refresh token with the tenant: %w", err)
+	}
+	return nil
+}
+
+// ErrNoRefreshToken is returned by Refresh if no refresh token was stored by
+// LoginDevice, for example, because the user logged in with a password.
+var ErrNoRefreshToken = errors.New("no refresh token found: run 'docker login' to authenticate again")
+
+// ErrIssuerMismatch is returned by Refresh if the refresh token was obtained
+// from another OpenID Connect provider than the one the manager uses, for
+// example, because the provider in the configuration file was changed.
+var ErrIssuerMismatch = errors.New("the refresh token was not issued by the configured OpenID Connect provider: run 'docker login' to authenticate again")
+
+// Refresh fetches the refresh token from the store, and uses it to get new
+// tokens from the oauth tenant. The new tokens are stored in the credentials
+// store, and used to create a new Hub PAT which is returned to the caller.
+// For registries other than Docker Hub, the new access token is returned as
+// password instead.
+func (m *OAuthManager) Refresh(ctx context.Context) (*types.AuthConfig, error) {
+	refreshToken, err := m.getStoredRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	tokenRes, err := m.api.Refresh(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh tokens: %w", err)
+	}
+	if tokenRes.RefreshToken == "" {
+		// the tenant did not rotate the refresh token
+		tokenRes.RefreshToken = refreshToken
+	}
+
+	return m.credentialsFromTokens(ctx, tokenRes)
+}
+
+// credentialsFromTokens stores the tokens in the credentials store, and
+// returns the credentials for the registry that are obtained with them.
+func (m *OAuthManager) credentialsFromTokens(ctx context.Context, tokenRes api.TokenResponse) (*types.AuthConfig, error) {
+	if !m.isHub() {
+		username, err := m.usernameFromTokens(tokenRes)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.storeTokensInStore(tokenRes, username); err != nil {
+			return nil, fmt.Errorf("failed to store tokens: %w", err)
+		}
+		return &types.AuthConfig{
+			Username:      username,
+			Password:      tokenRes.AccessToken,
+			ServerAddress: m.serverAddress,
+		}, nil
+	}
+
 	claims, err := oauth.GetClaims(tokenRes.AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token claims: %w", err)
@@ -156,59 +286,150 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	}, nil
 }
 
-// Logout fetches the refresh token from the store and revokes it
-// with the configured oauth tenant. The stored access and refresh
-// tokens are then erased from the store.
-// If the refresh token is not found in the store, an error is not
-// returned.
-func (m *OAuthManager) Logout(ctx context.Context) error {
-	refreshConfig, err := m.store.Get(refreshTokenKey)
-	if err != nil {
-		return err
+// usernameFromTokens returns the username from the standard claims of the ID
+// token, or of the access token if it is a JWT. If neither has a username,
+// the username that was stored with the previous tokens is used, as tenants
+// may not return an ID token when refreshing tokens.
+func (m *OAuthManager) usernameFromTokens(tokenRes api.TokenResponse) (string, error) {
+	for _, token := range []string{tokenRes.IDToken, tokenRes.AccessToken} {
+		if token == "" {
+			continue
+		}
+		claims, err := oauth.GetClaims(token)
+		if err != nil {
+			continue
+		}
+		for _, username := range []string{claims.PreferredUsername, claims.Email, claims.Subject} {
+			if username != "" {
+				return username, nil
+			}
+		}
 	}
-	if refreshConfig.Password == "" {
-		return nil
+	if refreshConfig, err := m.store.Get(m.tokenKey(refreshTokenSuffix)); err == nil && refreshConfig.Username != "" {
+		return refreshConfig.Username, nil
 	}
-	parts := strings.Split(refreshConfig.Password, "..")
-	if len(parts) != 2 {
-		// the token wasn't stored by the CLI, so don't revoke it
-		// or erase it from the store/error
-		return nil
+	return "", errors.New("failed to get username: the tenant returned no ID token with a username")
+}
+
+// Expiry returns the time at which the access token in the store expires,
+// or a zero time if there is no access token, or the token does not expire.
+// The Hub PAT returned by LoginDevice and Refresh is created from the access
+// token, and should be refreshed when the access token expires.
+func (m *OAuthManager) Expiry() (time.Time, error) {
+	accessConfig, err := m.store.Get(m.tokenKey(accessTokenSuffix))
+	if err != nil {
+		return time.Time{}, err
 	}
-	// erase the token from the store first, that way
-	// if the revoke fails, the user can try to logout again
-	if err := m.eraseTokensFromStore(); err != nil {
-		return fmt.Errorf("failed to erase tokens: %w", err)
+	if accessConfig.Password == "" {
+		return time.Time{}, nil
 	}
-	if err := m.api.RevokeToken(ctx, parts[0]); err != nil {
-		return fmt.Errorf("credentials erased successfully, but there was a failure to revoke the OAuth refresh token with the tenant: %w", err)
+	claims, err := oauth.GetClaims(accessConfig.Password)
+	if err != nil {
+		if !m.expiry.IsZero() {
+			// the access token is opaque
+			return m.expiry, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to parse token claims: %w", err)
 	}
-	return nil
+	if claims.Expiry == nil {
+		return m.expiry, nil
+	}
+	return claims.Expiry.Time(), nil
 }
 
 const (
-	accessTokenKey  = registry.IndexServer + "access-token"
-	refreshTokenKey = registry.IndexServer + "refresh-token"
+	accessTokenSuffix  = "access-token"
+	refreshTokenSuffix = "refresh-token"
 )
 
+// isHub returns whether the manager authenticates to Docker Hub.
+func (m *OAuthManager) isHub() bool {
+	return m.serverAddress == "" || m.serverAddress == registry.IndexServer
+}
+
+// tokenKey returns the key under which the token with the given suffix is
+// stored in the credentials store, such as "https://index.docker.io/v1/access-token"
+// for Docker Hub, or "registry.example.com/_oauth/access-token" for other
+// registries (see [credentials.OAuthTokenKey]).
+func (m *OAuthManager) tokenKey(suffix string) string {
+	if m.isHub() {
+		return registry.IndexServer + suffix
+	}
+	return credentials.OAuthTokenKey(m.serverAddress, suffix)
+}
+
+// IsTokenKey returns whether key is a key under which an auth manager stores
+// its tokens in the credentials store, rather than the credentials for a
+// registry.
+func IsTokenKey(key string) bool {
+	switch key {
+	case registry.IndexServer + accessTokenSuffix, registry.IndexServer + refreshTokenSuffix:
+		return true
+	}
+	return credentials.IsOAuthTokenKey(key)
+}
+
 func (m *OAuthManager) storeTokensInStore(tokens api.TokenResponse, username string) error {
+	if tokens.ExpiresIn > 0 {
+		m.expiry = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+	}
 	return errors.Join(
 		m.store.Store(types.AuthConfig{
 			Username:      username,
 			Password:      tokens.AccessToken,
-			ServerAddress: accessTokenKey,
+			ServerAddress: m.tokenKey(accessTokenSuffix),
 		}),
 		m.store.Store(types.AuthConfig{
 			Username:      username,
-			Password:      tokens.RefreshToken + ".." + m.clientID,
-			ServerAddress: refreshTokenKey,
+			Password:      m.refreshTokenSecret(tokens.RefreshToken),
+			ServerAddress: m.tokenKey(refreshTokenSuffix),
 		}),
 	)
 }
 
+// refreshTokenSecret returns the secret under which the refresh token is
+// stored: the refresh token concatenated with the client ID, and the issuer of
+// the OpenID Connect provider, if any.
+func (m *OAuthManager) refreshTokenSecret(refreshToken string) string {
+	secret := refreshToken + ".." + m.clientID
+	if m.issuer != "" {
+		secret += ".." + m.issuer
+	}
+	return secret
+}
+
+// getStoredRefreshToken returns the refresh token from the store, or an empty
+// string if the store has no refresh token that was stored by the CLI. It
+// returns ErrIssuerMismatch if the refresh token was issued by another
+// OpenID Connect provider.
+func (m *OAuthManager) getStoredRefreshToken() (string, error) {
+	refreshConfig, err := m.store.Get(m.tokenKey(refreshTokenSuffix))
+	if err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(refreshConfig.Password, "..", 3)
+	switch {
+	case len(parts) < 2:
+		return "", nil
+	case m.issuer == "" && len(parts) == 3:
+		return "", nil
+	case m.issuer != "" && (len(parts) != 3 || parts[2] != m.issuer):
+		return "", ErrIssuerMismatch
+	}
+	return parts[0], nil
+}
+
+// EraseTokens erases the tokens that an auth manager stored for the registry
+// at serverAddress from the store, without revoking them. It is used to log
+// out if the provider that issued the tokens can't be reached.
+func EraseTokens(store credentials.Store, serverAddress string) error {
+	m := &OAuthManager{store: store, serverAddress: serverAddress}
+	return m.eraseTokensFromStore()
+}
+
 func (m *OAuthManager) eraseTokensFromStore() error {
 	return errors.Join(
-		m.store.Erase(accessTokenKey),
-		m.store.Erase(refreshTokenKey),
+		m.store.Erase(m.tokenKey(accessTokenSuffix)),
+		m.store.Erase(m.tokenKey(refreshTokenSuffix)),
 	)
 }
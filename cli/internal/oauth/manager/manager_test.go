@@ -297,6 +297,124 @@ func TestLogout(t *testing.T) {
 	})
 }
 
+func TestRefresh(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var receivedRefreshToken, receivedAccessToken string
+		a := &testAPI{
+			refresh: func(token string) (api.TokenResponse, error) {
+				receivedRefreshToken = token
+				return api.TokenResponse{
+					AccessToken:  validToken,
+					RefreshToken: "a-new-refresh-token",
+				}, nil
+			},
+			getAutoPAT: func(audience string, res api.TokenResponse) (string, error) {
+				receivedAccessToken = res.AccessToken
+				return "a-new-pat", nil
+			},
+		}
+		store := newStore(map[string]types.AuthConfig{
+			"https://index.docker.io/v1/refresh-token": {
+				Password: "a-refresh-token..client-id",
+			},
+		})
+		manager := OAuthManager{
+			clientID: "client-id",
+			store:    credentials.NewFileStore(store),
+			api:      a,
+		}
+
+		authConfig, err := manager.Refresh(context.Background())
+		assert.NilError(t, err)
+
+		assert.Equal(t, receivedRefreshToken, "a-refresh-token")
+		assert.Equal(t, receivedAccessToken, validToken)
+		assert.DeepEqual(t, authConfig, &types.AuthConfig{
+			Username:      "bork!",
+			Password:      "a-new-pat",
+			ServerAddress: "https://index.docker.io/v1/",
+		})
+		assert.Equal(t, store.configs["https://index.docker.io/v1/access-token"].Password, validToken)
+		assert.Equal(t, store.configs["https://index.docker.io/v1/refresh-token"].Password, "a-new-refresh-token..client-id")
+	})
+
+	t.Run("refresh token not rotated", func(t *testing.T) {
+		a := &testAPI{
+			refresh: func(token string) (api.TokenResponse, error) {
+				return api.TokenResponse{AccessToken: validToken}, nil
+			},
+		}
+		store := newStore(map[string]types.AuthConfig{
+			"https://index.docker.io/v1/refresh-token": {
+				Password: "a-refresh-token..client-id",
+			},
+		})
+		manager := OAuthManager{
+			clientID: "client-id",
+			store:    credentials.NewFileStore(store),
+			api:      a,
+		}
+
+		_, err := manager.Refresh(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, store.configs["https://index.docker.io/v1/refresh-token"].Password, "a-refresh-token..client-id")
+	})
+
+	t.Run("no refresh token", func(t *testing.T) {
+		var triedRefresh bool
+		a := &testAPI{
+			refresh: func(token string) (api.TokenResponse, error) {
+				triedRefresh = true
+				return api.TokenResponse{}, nil
+			},
+		}
+		manager := OAuthManager{
+			store: credentials.NewFileStore(newStore(map[string]types.AuthConfig{})),
+			api:   a,
+		}
+
+		_, err := manager.Refresh(context.Background())
+		assert.ErrorIs(t, err, ErrNoRefreshToken)
+		assert.Check(t, !triedRefresh)
+	})
+
+	t.Run("error refreshing token", func(t *testing.T) {
+		a := &testAPI{
+			refresh: func(token string) (api.TokenResponse, error) {
+				return api.TokenResponse{}, errors.New("invalid refresh token")
+			},
+		}
+		store := newStore(map[string]types.AuthConfig{
+			"https://index.docker.io/v1/refresh-token": {
+				Password: "a-refresh-token..client-id",
+			},
+		})
+		manager := OAuthManager{
+			store: credentials.NewFileStore(store),
+			api:   a,
+		}
+
+		_, err := manager.Refresh(context.Background())
+		assert.ErrorContains(t, err, "failed to refresh tokens: invalid refresh token")
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	store := newStore(map[string]types.AuthConfig{})
+	manager := OAuthManager{
+		store: credentials.NewFileStore(store),
+	}
+
+	expiry, err := manager.Expiry()
+	assert.NilError(t, err)
+	assert.Check(t, expiry.IsZero())
+
+	store.configs["https://index.docker.io/v1/access-token"] = types.AuthConfig{Password: validToken}
+	expiry, err = manager.Expiry()
+	assert.NilError(t, err)
+	assert.Equal(t, expiry, time.Unix(1719506539, 0))
+}
+
 var _ api.OAuthAPI = &testAPI{}
 
 type testAPI struct {
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/internal/oauth/api"
+)
+
+// OIDCOptions are the options used by NewOIDCManager to create an auth manager
+// for a registry that authenticates with an OpenID Connect provider.
+type OIDCOptions struct {
+	Store         credentials.Store
+	ServerAddress string
+	Issuer        string
+	ClientID      string
+	Scopes        []string
+	Audience      string
+	OpenBrowser   func(string) error
+}
+
+// NewOIDCManager creates an auth manager for a registry that authenticates
+// with an OpenID Connect provider that supports the device authorization grant
+// (RFC 8628). The endpoints of the provider are discovered from its issuer URL.
+// The refresh token is stored with the issuer URL, and is not sent to other
+// providers.
+func NewOIDCManager(ctx context.Context, options OIDCOptions) (*OAuthManager, error) {
+	provider, err := api.Discover(ctx, options.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	return New(OAuthManagerOptions{
+		Store:         options.Store,
+		Audience:      options.Audience,
+		ClientID:      options.ClientID,
+		Scopes:        options.Scopes,
+		OpenBrowser:   options.OpenBrowser,
+		ServerAddress: options.ServerAddress,
+		Provider:      provider,
+		Issuer:        options.Issuer,
+	}), nil
+}
@@ -0,0 +1,51 @@
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/go-units"
+	"github.com/spf13/pflag"
+)
+
+// DryRunOptions holds the options for listing the objects that would be
+// removed by a prune command, without removing them.
+type DryRunOptions struct {
+	Enabled bool
+	Format  string
+}
+
+// InstallFlags adds the "--dry-run" and "--format" flags to the given flag-set.
+func (o *DryRunOptions) InstallFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Enabled, "dry-run", false, "Show what would be removed, without removing anything")
+	flags.StringVar(&o.Format, "format", "", `Format the output of --dry-run ("table", "json", or a Go template)`)
+}
+
+// Validate returns an error if the options are invalid.
+func (o DryRunOptions) Validate() error {
+	if o.Format != "" && !o.Enabled {
+		return errors.New("--format is only supported with --dry-run")
+	}
+	return nil
+}
+
+// WriteDryRun writes the candidates using the given format. When using the
+// table format, the table is followed by the total reclaimable space. IDs are
+// not truncated when using the JSON format.
+func WriteDryRun(out io.Writer, format string, candidates []Candidate) error {
+	f := NewFormat(format)
+	ctx := formatter.Context{
+		Output: out,
+		Format: f,
+		Trunc:  !f.IsJSON(),
+	}
+	if err := FormatWrite(ctx, candidates); err != nil {
+		return err
+	}
+	if ctx.Format.IsTable() {
+		_, _ = fmt.Fprintln(out, "Total reclaimable space:", units.HumanSize(float64(TotalSize(candidates))))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package prune
+
+import (
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultTableFormat = "table {{.Type}}\t{{.ID}}\t{{.Name}}\t{{.Size}}"
+
+	typeHeader = "TYPE"
+	idHeader   = "ID"
+)
+
+// NewFormat returns a format for rendering prune candidates.
+func NewFormat(source string) formatter.Format {
+	if source == "" || source == formatter.TableFormatKey {
+		return defaultTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// FormatWrite writes the candidates using the given context.
+func FormatWrite(ctx formatter.Context, candidates []Candidate) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, c := range candidates {
+			if err := format(&candidateContext{c: c, trunc: ctx.Trunc}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ctx.Write(newCandidateContext(), render)
+}
+
+type candidateContext struct {
+	formatter.HeaderContext
+	c     Candidate
+	trunc bool
+}
+
+func newCandidateContext() *candidateContext {
+	candidateCtx := candidateContext{}
+	candidateCtx.Header = formatter.SubHeaderContext{
+		"Type": typeHeader,
+		"ID":   idHeader,
+		"Name": formatter.NameHeader,
+		"Size": formatter.SizeHeader,
+	}
+	return &candidateCtx
+}
+
+func (c *candidateContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *candidateContext) Type() string {
+	return string(c.c.Type)
+}
+
+func (c *candidateContext) ID() string {
+	if c.trunc && c.c.Type != TypeVolume {
+		return stringid.TruncateID(c.c.ID)
+	}
+	return c.c.ID
+}
+
+func (c *candidateContext) Name() string {
+	return c.c.Name
+}
+
+func (c *candidateContext) Size() string {
+	return units.HumanSize(float64(c.c.Size))
+}
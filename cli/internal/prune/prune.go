@@ -0,0 +1,717 @@
+// Package prune determines which objects are removed when pruning, so that
+// they can be listed before removing them, and prunes them.
+package prune
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// Type is the type of object that is pruned.
+type Type string
+
+// Types of objects that are pruned.
+const (
+	TypeContainer  Type = "container"
+	TypeNetwork    Type = "network"
+	TypeVolume     Type = "volume"
+	TypeImage      Type = "image"
+	TypeBuildCache Type = "build-cache"
+)
+
+// anonymousLabel is the label set by the daemon on anonymous volumes.
+const anonymousLabel = "com.docker.volume.anonymous"
+
+// Candidate is an object that is removed when pruning.
+type Candidate struct {
+	Type Type
+	ID   string
+	Name string
+
+	// Size is the amount of disk space reclaimed by removing the object.
+	Size int64
+
+	// refs are the references (tags) of an image, which are removed
+	// individually so that images are removed without forcing.
+	refs []string
+}
+
+// Rule describes which objects of a type are pruned.
+type Rule struct {
+	// Filters are the "label", "label!" and "until" filters to apply.
+	Filters filters.Args
+
+	// All prunes all unused images instead of only dangling ones, all unused
+	// volumes instead of only anonymous ones, and all unused build cache.
+	All bool
+
+	// KeepLast keeps the given number of most recently created images of
+	// each repository. Only used for images.
+	KeepLast int
+}
+
+// Policy describes which objects are pruned. Object types without a Rule
+// are not pruned.
+type Policy struct {
+	Containers *Rule
+	Networks   *Rule
+	Volumes    *Rule
+	Images     *Rule
+	BuildCache *Rule
+}
+
+// Plan returns the objects that are removed when pruning using the given
+// policy, in the order in which they must be removed. Containers that are
+// removed by the policy are not considered to be using images or volumes.
+func Plan(ctx context.Context, apiClient client.APIClient, p Policy) ([]Candidate, error) {
+	// The "dangling" filter for images, and the "all" filter for volumes are
+	// equivalent to the All option.
+	if p.Images != nil {
+		r := *p.Images
+		dangling, ok, err := boolFilter(&r, "dangling")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.All = !dangling
+		}
+		p.Images = &r
+	}
+	if p.Volumes != nil {
+		r := *p.Volumes
+		all, ok, err := boolFilter(&r, "all")
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.All = all
+		}
+		p.Volumes = &r
+	}
+
+	now := time.Now()
+	matchers := make(map[*Rule]matcher)
+	for _, r := range []struct {
+		typ  Type
+		rule *Rule
+	}{
+		{TypeContainer, p.Containers},
+		{TypeNetwork, p.Networks},
+		{TypeVolume, p.Volumes},
+		{TypeImage, p.Images},
+	} {
+		if r.rule == nil {
+			continue
+		}
+		m, err := newMatcher(r.typ, r.rule.Filters, now)
+		if err != nil {
+			return nil, err
+		}
+		matchers[r.rule] = m
+	}
+	var bcMatcher buildCacheMatcher
+	if p.BuildCache != nil {
+		var err error
+		bcMatcher, err = newBuildCacheMatcher(p.BuildCache.Filters, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var duTypes []types.DiskUsageObject
+	if p.Containers != nil || p.Volumes != nil || p.Images != nil {
+		duTypes = append(duTypes, types.ContainerObject)
+	}
+	if p.Volumes != nil {
+		duTypes = append(duTypes, types.VolumeObject)
+	}
+	if p.Images != nil {
+		duTypes = append(duTypes, types.ImageObject)
+	}
+	if p.BuildCache != nil {
+		duTypes = append(duTypes, types.BuildCacheObject)
+	}
+	var du types.DiskUsage
+	if len(duTypes) > 0 {
+		var err error
+		du, err = apiClient.DiskUsage(ctx, types.DiskUsageOptions{Types: duTypes})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []Candidate
+	removed := make(map[string]bool)
+	if p.Containers != nil {
+		for _, c := range planContainers(du.Containers, matchers[p.Containers]) {
+			removed[c.ID] = true
+			candidates = append(candidates, c)
+		}
+	}
+	if p.Networks != nil {
+		c, err := planNetworks(ctx, apiClient, matchers[p.Networks])
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c...)
+	}
+	if p.Volumes != nil {
+		candidates = append(candidates, planVolumes(du.Volumes, du.Containers, removed, *p.Volumes, matchers[p.Volumes])...)
+	}
+	if p.Images != nil {
+		candidates = append(candidates, planImages(du.Images, du.Containers, removed, *p.Images, matchers[p.Images])...)
+	}
+	if p.BuildCache != nil {
+		candidates = append(candidates, planBuildCache(du.BuildCache, *p.BuildCache, bcMatcher)...)
+	}
+	return candidates, nil
+}
+
+// TotalSize returns the disk space reclaimed by removing the candidates.
+func TotalSize(candidates []Candidate) uint64 {
+	var total uint64
+	for _, c := range candidates {
+		if c.Size > 0 {
+			total += uint64(c.Size)
+		}
+	}
+	return total
+}
+
+func planContainers(containers []*container.Summary, m matcher) []Candidate {
+	var candidates []Candidate
+	for _, c := range containers {
+		switch c.State {
+		case "running", "paused", "restarting":
+			continue
+		}
+		if !m.match(c.Labels, time.Unix(c.Created, 0)) {
+			continue
+		}
+		var name string
+		if len(c.Names) > 0 {
+			name = c.Names[0][1:]
+		}
+		candidates = append(candidates, Candidate{Type: TypeContainer, ID: c.ID, Name: name, Size: c.SizeRw})
+	}
+	return candidates
+}
+
+// predefinedNetworks are the networks created by the daemon, which are never
+// pruned.
+var predefinedNetworks = map[string]bool{
+	network.NetworkBridge:  true,
+	network.NetworkHost:    true,
+	network.NetworkNone:    true,
+	network.NetworkDefault: true,
+	network.NetworkNat:     true,
+}
+
+func planNetworks(ctx context.Context, apiClient client.APIClient, m matcher) ([]Candidate, error) {
+	networks, err := apiClient.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Candidate
+	for _, nw := range networks {
+		if predefinedNetworks[nw.Name] || nw.Ingress || nw.ConfigOnly {
+			continue
+		}
+		if !m.match(nw.Labels, nw.Created) {
+			continue
+		}
+		// The list of networks does not include the containers and services
+		// using them.
+		inspect, err := apiClient.NetworkInspect(ctx, nw.ID, network.InspectOptions{Verbose: nw.Scope == "swarm"})
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if len(inspect.Containers) > 0 || len(inspect.Services) > 0 {
+			continue
+		}
+		candidates = append(candidates, Candidate{Type: TypeNetwork, ID: nw.ID, Name: nw.Name})
+	}
+	return candidates, nil
+}
+
+func planVolumes(volumes []*volume.Volume, containers []*container.Summary, removed map[string]bool, r Rule, m matcher) []Candidate {
+	inUse := make(map[string]bool)
+	for _, c := range containers {
+		if removed[c.ID] {
+			continue
+		}
+		for _, mp := range c.Mounts {
+			if mp.Type == mount.TypeVolume {
+				inUse[mp.Name] = true
+			}
+		}
+	}
+
+	var candidates []Candidate
+	for _, v := range volumes {
+		if inUse[v.Name] {
+			continue
+		}
+		_, anonymous := v.Labels[anonymousLabel]
+		if !anonymous && !r.All {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		if !m.match(v.Labels, created) {
+			continue
+		}
+		c := Candidate{Type: TypeVolume, ID: v.Name}
+		if !anonymous {
+			c.Name = v.Name
+		}
+		if v.UsageData != nil && v.UsageData.Size > 0 {
+			c.Size = v.UsageData.Size
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+func planImages(images []*image.Summary, containers []*container.Summary, removed map[string]bool, r Rule, m matcher) []Candidate {
+	inUse := make(map[string]bool)
+	for _, c := range containers {
+		if !removed[c.ID] {
+			inUse[c.ImageID] = true
+		}
+	}
+
+	keep := make(map[string]bool)
+	if r.KeepLast > 0 {
+		sorted := append([]*image.Summary(nil), images...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Created > sorted[j].Created
+		})
+		count := make(map[string]int)
+		for _, img := range sorted {
+			for _, repo := range repositories(tags(img)) {
+				if count[repo] < r.KeepLast {
+					keep[img.ID] = true
+				}
+				count[repo]++
+			}
+		}
+	}
+
+	var candidates []Candidate
+	for _, img := range images {
+		if inUse[img.ID] || keep[img.ID] {
+			continue
+		}
+		refs := tags(img)
+		if len(refs) > 0 && !r.All && r.KeepLast == 0 {
+			// Only dangling images are pruned by default.
+			continue
+		}
+		if !m.match(img.Labels, time.Unix(img.Created, 0)) {
+			continue
+		}
+		size := img.Size
+		if img.SharedSize > 0 {
+			size -= img.SharedSize
+		}
+		c := Candidate{Type: TypeImage, ID: img.ID, Size: size, refs: refs}
+		if len(refs) > 0 {
+			c.Name = refs[0]
+			if len(refs) > 1 {
+				c.Name += fmt.Sprintf(" (+%d)", len(refs)-1)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// tags returns the tags of an image, excluding the "<none>:<none>" placeholder
+// used by old daemons for dangling images.
+func tags(img *image.Summary) []string {
+	var refs []string
+	for _, t := range img.RepoTags {
+		if t != "<none>:<none>" {
+			refs = append(refs, t)
+		}
+	}
+	return refs
+}
+
+// repositories returns the (unique) repositories of the given tags.
+func repositories(refs []string) []string {
+	var repos []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		named, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			continue
+		}
+		repo := named.Name()
+		if !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+func planBuildCache(records []*types.BuildCache, r Rule, m buildCacheMatcher) []Candidate {
+	var candidates []Candidate
+	for _, bc := range records {
+		if bc.InUse {
+			continue
+		}
+		// Only build cache that is not shared, and not internal is pruned
+		// by default.
+		if !r.All && (bc.Shared || bc.Type == "internal" || bc.Type == "frontend") {
+			continue
+		}
+		if !m.match(bc) {
+			continue
+		}
+		candidates = append(candidates, Candidate{Type: TypeBuildCache, ID: bc.ID, Name: bc.Description, Size: bc.Size})
+	}
+	return candidates
+}
+
+// Report describes the objects of a type that were removed when pruning.
+type Report struct {
+	Type Type
+
+	// Deleted describes the objects that were removed, as printed by the
+	// prune command for the object type.
+	Deleted []string
+
+	// SpaceReclaimed is the disk space that was reclaimed.
+	SpaceReclaimed uint64
+}
+
+// Prune prunes the objects using the given policy. Objects are pruned using
+// the daemon's prune endpoints with the filters of each rule, so that the
+// daemon determines which objects are unused, and reports what it removed.
+// The daemon cannot keep the most recent images of each repository, so images
+// of a rule that sets KeepLast are removed individually instead, using the
+// images in candidates, as returned by [Plan]. Pruning continues if an object
+// type cannot be pruned; all errors that occurred are returned.
+func Prune(ctx context.Context, apiClient client.APIClient, p Policy, candidates []Candidate) ([]Report, error) {
+	var (
+		reports []Report
+		errs    []error
+	)
+	add := func(typ Type, report Report, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune %s: %w", typ, err))
+		}
+		if len(report.Deleted) > 0 {
+			report.Type = typ
+			reports = append(reports, report)
+		}
+	}
+	if p.Containers != nil {
+		report, err := apiClient.ContainersPrune(ctx, p.Containers.Filters)
+		add(TypeContainer, Report{Deleted: report.ContainersDeleted, SpaceReclaimed: report.SpaceReclaimed}, err)
+	}
+	if p.Networks != nil {
+		report, err := apiClient.NetworksPrune(ctx, p.Networks.Filters)
+		add(TypeNetwork, Report{Deleted: report.NetworksDeleted}, err)
+	}
+	if p.Volumes != nil {
+		f := p.Volumes.Filters.Clone()
+		// API < v1.42 removes all volumes (anonymous and named) by default.
+		if p.Volumes.All && !f.Contains("all") && versions.GreaterThanOrEqualTo(apiClient.ClientVersion(), "1.42") {
+			f.Add("all", "true")
+		}
+		report, err := apiClient.VolumesPrune(ctx, f)
+		add(TypeVolume, Report{Deleted: report.VolumesDeleted, SpaceReclaimed: report.SpaceReclaimed}, err)
+	}
+	if p.Images != nil {
+		report, err := pruneImages(ctx, apiClient, *p.Images, candidates)
+		add(TypeImage, report, err)
+	}
+	if p.BuildCache != nil {
+		report, err := apiClient.BuildCachePrune(ctx, types.BuildCachePruneOptions{
+			All:     p.BuildCache.All,
+			Filters: p.BuildCache.Filters,
+		})
+		var r Report
+		if report != nil {
+			r = Report{Deleted: report.CachesDeleted, SpaceReclaimed: report.SpaceReclaimed}
+		}
+		add(TypeBuildCache, r, err)
+	}
+	return reports, errors.Join(errs...)
+}
+
+func pruneImages(ctx context.Context, apiClient client.APIClient, r Rule, candidates []Candidate) (Report, error) {
+	if r.KeepLast == 0 {
+		f := r.Filters.Clone()
+		if !f.Contains("dangling") {
+			f.Add("dangling", strconv.FormatBool(!r.All))
+		}
+		report, err := apiClient.ImagesPrune(ctx, f)
+		return Report{Deleted: imagesDeleted(report.ImagesDeleted), SpaceReclaimed: report.SpaceReclaimed}, err
+	}
+
+	var (
+		report Report
+		errs   []error
+	)
+	for _, c := range candidates {
+		if c.Type != TypeImage {
+			continue
+		}
+		refs := c.refs
+		if len(refs) == 0 {
+			refs = []string{c.ID}
+		}
+		var err error
+		for _, ref := range refs {
+			var deleted []image.DeleteResponse
+			deleted, err = apiClient.ImageRemove(ctx, ref, image.RemoveOptions{PruneChildren: true})
+			report.Deleted = append(report.Deleted, imagesDeleted(deleted)...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove image %s: %w", ref, err))
+				break
+			}
+		}
+		if err == nil && c.Size > 0 {
+			report.SpaceReclaimed += uint64(c.Size)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// imagesDeleted describes the images that were untagged and deleted in the
+// same way as "docker image prune".
+func imagesDeleted(deleted []image.DeleteResponse) []string {
+	out := make([]string, 0, len(deleted))
+	for _, st := range deleted {
+		if st.Untagged != "" {
+			out = append(out, "untagged: "+st.Untagged)
+		} else {
+			out = append(out, "deleted: "+st.Deleted)
+		}
+	}
+	return out
+}
+
+// boolFilter removes the boolean filter with the given key from the rule's
+// filters, and returns its value, and whether the filter was set.
+func boolFilter(r *Rule, key string) (value bool, ok bool, err error) {
+	values := r.Filters.Get(key)
+	if len(values) == 0 {
+		return false, false, nil
+	}
+	if len(values) > 1 {
+		return false, false, fmt.Errorf("more than one %s filter specified", key)
+	}
+	value, err = strconv.ParseBool(values[0])
+	if err != nil {
+		return false, false, fmt.Errorf("invalid filter '%s=%s'", key, values[0])
+	}
+	r.Filters = r.Filters.Clone()
+	r.Filters.Del(key, values[0])
+	return value, true, nil
+}
+
+// supportedFilters are the filters that are supported by the daemon when
+// pruning objects of each type, other than the "dangling" filter for images,
+// and the "all" filter for volumes, which are handled by [Plan].
+var supportedFilters = map[Type][]string{
+	TypeContainer: {"label", "label!", "until"},
+	TypeNetwork:   {"label", "label!", "until"},
+	TypeVolume:    {"label", "label!"},
+	TypeImage:     {"label", "label!", "until"},
+}
+
+// matcher matches objects against the "label", "label!" and "until" filters.
+type matcher struct {
+	labels    []string
+	notLabels []string
+	until     time.Time
+}
+
+func newMatcher(typ Type, f filters.Args, now time.Time) (matcher, error) {
+	for _, k := range f.Keys() {
+		if !isSupportedFilter(typ, k) {
+			return matcher{}, fmt.Errorf("unsupported filter %q for %s", k, typ)
+		}
+	}
+	m := matcher{labels: f.Get("label"), notLabels: f.Get("label!")}
+	if until := f.Get("until"); len(until) > 0 {
+		if len(until) > 1 {
+			return matcher{}, errors.New("more than one until filter specified")
+		}
+		ts, err := timetypes.GetTimestamp(until[0], now)
+		if err != nil {
+			return matcher{}, err
+		}
+		sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+		if err != nil {
+			return matcher{}, err
+		}
+		m.until = time.Unix(sec, nsec)
+	}
+	return m, nil
+}
+
+func isSupportedFilter(typ Type, key string) bool {
+	for _, k := range supportedFilters[typ] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns whether an object with the given labels, and creation time
+// matches the filters. Objects for which the creation time is unknown do not
+// match an "until" filter.
+func (m matcher) match(labels map[string]string, created time.Time) bool {
+	if !m.until.IsZero() && (created.IsZero() || !created.Before(m.until)) {
+		return false
+	}
+	for _, l := range m.labels {
+		if !hasLabel(labels, l) {
+			return false
+		}
+	}
+	for _, l := range m.notLabels {
+		if hasLabel(labels, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLabel returns whether labels contains the given "key" or "key=value".
+func hasLabel(labels map[string]string, label string) bool {
+	k, v, hasValue := strings.Cut(label, "=")
+	actual, ok := labels[k]
+	return ok && (!hasValue || actual == v)
+}
+
+// buildCacheMatcher matches build cache records against the filters that are
+// supported by the daemon when pruning build cache. The daemon ignores other
+// filters, such as the "label" filters that are passed by "docker system
+// prune", so they are ignored here as well.
+type buildCacheMatcher struct {
+	now       time.Time
+	unusedFor time.Duration
+	id        *regexp.Regexp
+	fields    map[string]string
+}
+
+// buildCacheFields are the fields of build cache records that can be used
+// as a filter. The "id" filter is a regular expression; other fields must
+// match exactly.
+var buildCacheFields = []string{"id", "parent", "type", "description", "inuse", "shared", "private"}
+
+func newBuildCacheMatcher(f filters.Args, now time.Time) (buildCacheMatcher, error) {
+	m := buildCacheMatcher{now: now, fields: make(map[string]string)}
+
+	// "unused-for" is a deprecated alias for the "until" filter.
+	until, unusedFor := f.Get("until"), f.Get("unused-for")
+	if len(until) > 0 && len(unusedFor) > 0 {
+		return buildCacheMatcher{}, errors.New(`conflicting filters "until" and "unused-for"`)
+	}
+	key := "until"
+	if len(unusedFor) > 0 {
+		key, until = "unused-for", unusedFor
+	}
+	switch len(until) {
+	case 0:
+	case 1:
+		d, err := time.ParseDuration(until[0])
+		if err != nil {
+			return buildCacheMatcher{}, fmt.Errorf("%q filter expects a duration (e.g., '24h'): %w", key, err)
+		}
+		m.unusedFor = d
+	default:
+		return buildCacheMatcher{}, fmt.Errorf("more than one %s filter specified", key)
+	}
+
+	for _, k := range buildCacheFields {
+		values := f.Get(k)
+		if len(values) == 0 {
+			continue
+		}
+		if len(values) > 1 {
+			return buildCacheMatcher{}, fmt.Errorf("more than one %s filter specified", k)
+		}
+		if k == "id" {
+			re, err := regexp.Compile(values[0])
+			if err != nil {
+				return buildCacheMatcher{}, fmt.Errorf("invalid filter 'id=%s': %w", values[0], err)
+			}
+			m.id = re
+			continue
+		}
+		m.fields[k] = values[0]
+	}
+	return m, nil
+}
+
+// match returns whether the build cache record matches the filters. Build
+// cache is pruned based on when it was last used.
+func (m buildCacheMatcher) match(bc *types.BuildCache) bool {
+	if m.unusedFor > 0 {
+		lastUsed := bc.CreatedAt
+		if bc.LastUsedAt != nil {
+			lastUsed = *bc.LastUsedAt
+		}
+		if lastUsed.After(m.now.Add(-m.unusedFor)) {
+			return false
+		}
+	}
+	if m.id != nil && !m.id.MatchString(bc.ID) {
+		return false
+	}
+	for k, v := range m.fields {
+		var ok bool
+		switch k {
+		case "parent":
+			ok = bc.Parent == v
+			for _, p := range bc.Parents {
+				ok = ok || p == v
+			}
+		case "type":
+			ok = bc.Type == v
+		case "description":
+			ok = bc.Description == v
+		case "inuse":
+			ok = strconv.FormatBool(bc.InUse) == v
+		case "shared":
+			ok = strconv.FormatBool(bc.Shared) == v
+		case "private":
+			ok = strconv.FormatBool(!bc.Shared) == v
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
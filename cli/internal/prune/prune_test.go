@@ -0,0 +1,329 @@
+package prune
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type fakeClient struct {
+	client.Client
+	version   string
+	diskUsage types.DiskUsage
+	networks  []network.Summary
+	removed   []string
+	pruned    map[Type]filters.Args
+}
+
+func (c *fakeClient) ClientVersion() string {
+	return c.version
+}
+
+func (c *fakeClient) DiskUsage(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+	return c.diskUsage, nil
+}
+
+func (c *fakeClient) NetworkList(context.Context, network.ListOptions) ([]network.Summary, error) {
+	return c.networks, nil
+}
+
+func (c *fakeClient) NetworkInspect(_ context.Context, id string, _ network.InspectOptions) (network.Inspect, error) {
+	for _, nw := range c.networks {
+		if nw.ID == id {
+			return nw, nil
+		}
+	}
+	return network.Inspect{}, nil
+}
+
+func (c *fakeClient) ImageRemove(_ context.Context, ref string, _ image.RemoveOptions) ([]image.DeleteResponse, error) {
+	c.removed = append(c.removed, "image:"+ref)
+	if strings.HasPrefix(ref, "sha256:") {
+		return []image.DeleteResponse{{Deleted: ref}}, nil
+	}
+	return []image.DeleteResponse{{Untagged: ref}}, nil
+}
+
+func (c *fakeClient) prune(typ Type, f filters.Args) {
+	if c.pruned == nil {
+		c.pruned = make(map[Type]filters.Args)
+	}
+	c.pruned[typ] = f
+}
+
+func (c *fakeClient) ContainersPrune(_ context.Context, f filters.Args) (container.PruneReport, error) {
+	c.prune(TypeContainer, f)
+	return container.PruneReport{ContainersDeleted: []string{"exited"}, SpaceReclaimed: 100}, nil
+}
+
+func (c *fakeClient) NetworksPrune(_ context.Context, f filters.Args) (network.PruneReport, error) {
+	c.prune(TypeNetwork, f)
+	return network.PruneReport{}, nil
+}
+
+func (c *fakeClient) VolumesPrune(_ context.Context, f filters.Args) (volume.PruneReport, error) {
+	c.prune(TypeVolume, f)
+	return volume.PruneReport{VolumesDeleted: []string{"anon"}, SpaceReclaimed: 20}, nil
+}
+
+func (c *fakeClient) ImagesPrune(_ context.Context, f filters.Args) (image.PruneReport, error) {
+	c.prune(TypeImage, f)
+	return image.PruneReport{ImagesDeleted: []image.DeleteResponse{{Deleted: "sha256:dangling"}}, SpaceReclaimed: 10}, nil
+}
+
+func (c *fakeClient) BuildCachePrune(_ context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
+	c.prune(TypeBuildCache, opts.Filters)
+	return nil, errors.New("build cache is unavailable")
+}
+
+func ids(candidates []Candidate) []string {
+	var out []string
+	for _, c := range candidates {
+		out = append(out, string(c.Type)+":"+c.ID)
+	}
+	return out
+}
+
+func testDiskUsage() types.DiskUsage {
+	day := int64(24 * time.Hour / time.Second)
+	now := time.Now().Unix()
+	return types.DiskUsage{
+		Containers: []*container.Summary{
+			{ID: "running", Names: []string{"/web"}, State: "running", ImageID: "sha256:app-v3", Created: now - 10*day, Mounts: []container.MountPoint{{Type: mount.TypeVolume, Name: "data"}}},
+			{ID: "exited", Names: []string{"/old"}, State: "exited", ImageID: "sha256:app-v1", Created: now - 10*day, SizeRw: 100, Mounts: []container.MountPoint{{Type: mount.TypeVolume, Name: "anon"}}},
+		},
+		Images: []*image.Summary{
+			{ID: "sha256:app-v1", RepoTags: []string{"app:v1"}, Created: now - 3*day, Size: 1000, SharedSize: 400},
+			{ID: "sha256:app-v2", RepoTags: []string{"app:v2"}, Created: now - 2*day, Size: 1000},
+			{ID: "sha256:app-v3", RepoTags: []string{"app:v3"}, Created: now - 1*day, Size: 1000},
+			{ID: "sha256:app-v4", RepoTags: []string{"app:v4", "app:latest"}, Created: now, Size: 1000},
+			{ID: "sha256:dangling", RepoTags: []string{"<none>:<none>"}, Created: now - 5*day, Size: 10},
+		},
+		Volumes: []*volume.Volume{
+			{Name: "data", UsageData: &volume.UsageData{Size: 50}},
+			{Name: "anon", Labels: map[string]string{anonymousLabel: ""}, UsageData: &volume.UsageData{Size: 20}},
+			{Name: "keep", Labels: map[string]string{"keep": "true"}, UsageData: &volume.UsageData{Size: 30}},
+			{Name: "unused", UsageData: &volume.UsageData{Size: 40}},
+		},
+		BuildCache: []*types.BuildCache{
+			{ID: "recent", Type: "regular", Size: 5, CreatedAt: time.Now()},
+			{ID: "old", Type: "regular", Size: 6, CreatedAt: time.Now().Add(-96 * time.Hour)},
+			{ID: "in-use", Type: "regular", Size: 7, InUse: true, CreatedAt: time.Now().Add(-96 * time.Hour)},
+		},
+	}
+}
+
+func TestPlanDefaults(t *testing.T) {
+	apiClient := &fakeClient{
+		diskUsage: testDiskUsage(),
+		networks: []network.Summary{
+			{ID: "bridge-id", Name: "bridge"},
+			{ID: "unused-net", Name: "unused"},
+			{ID: "used-net", Name: "used", Containers: map[string]network.EndpointResource{"running": {}}},
+		},
+	}
+	candidates, err := Plan(context.Background(), apiClient, Policy{
+		Containers: &Rule{},
+		Networks:   &Rule{},
+		Volumes:    &Rule{},
+		Images:     &Rule{},
+		BuildCache: &Rule{},
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(ids(candidates), []string{
+		"container:exited",
+		"network:unused-net",
+		"volume:anon",
+		"image:sha256:dangling",
+		"build-cache:recent",
+		"build-cache:old",
+	}))
+	assert.Check(t, is.Equal(TotalSize(candidates), uint64(100+20+10+5+6)))
+}
+
+func TestPlanPolicy(t *testing.T) {
+	apiClient := &fakeClient{diskUsage: testDiskUsage()}
+	candidates, err := Plan(context.Background(), apiClient, Policy{
+		Containers: &Rule{},
+		Images:     &Rule{KeepLast: 2},
+		Volumes:    &Rule{All: true, Filters: filters.NewArgs(filters.Arg("label!", "keep=true"))},
+		BuildCache: &Rule{Filters: filters.NewArgs(filters.Arg("until", "72h"))},
+	})
+	assert.NilError(t, err)
+
+	// The two most recent images of "app" are kept, and app:v1 is no longer
+	// in use after removing the exited container.
+	assert.Check(t, is.DeepEqual(ids(candidates), []string{
+		"container:exited",
+		"volume:anon",
+		"volume:unused",
+		"image:sha256:app-v1",
+		"image:sha256:app-v2",
+		"image:sha256:dangling",
+		"build-cache:old",
+	}))
+	assert.Check(t, is.Equal(candidates[3].Size, int64(600)))
+	assert.Check(t, is.Equal(candidates[3].Name, "app:v1"))
+}
+
+func TestPlanInvalidFilter(t *testing.T) {
+	_, err := Plan(context.Background(), &fakeClient{}, Policy{Images: &Rule{Filters: filters.NewArgs(filters.Arg("reference", "app"))}})
+	assert.Check(t, is.Error(err, `unsupported filter "reference" for image`))
+
+	_, err = Plan(context.Background(), &fakeClient{}, Policy{Volumes: &Rule{Filters: filters.NewArgs(filters.Arg("until", "24h"))}})
+	assert.Check(t, is.Error(err, `unsupported filter "until" for volume`))
+}
+
+func TestPlanBuildCacheFilters(t *testing.T) {
+	now := time.Now()
+	apiClient := &fakeClient{diskUsage: types.DiskUsage{
+		BuildCache: []*types.BuildCache{
+			{ID: "abc123", Type: "regular", Description: "[1/2] FROM alpine", CreatedAt: now.Add(-96 * time.Hour)},
+			{ID: "abc456", Type: "regular", Parents: []string{"abc123"}, CreatedAt: now.Add(-96 * time.Hour), LastUsedAt: &now},
+			{ID: "def789", Type: "source.local", CreatedAt: now.Add(-96 * time.Hour)},
+			{ID: "shared", Type: "regular", Shared: true, CreatedAt: now.Add(-96 * time.Hour)},
+			{ID: "internal", Type: "internal", CreatedAt: now.Add(-96 * time.Hour)},
+		},
+	}}
+
+	tests := []struct {
+		doc      string
+		rule     Rule
+		expected []string
+	}{
+		{
+			doc:      "default",
+			expected: []string{"build-cache:abc123", "build-cache:abc456", "build-cache:def789"},
+		},
+		{
+			doc:      "all",
+			rule:     Rule{All: true},
+			expected: []string{"build-cache:abc123", "build-cache:abc456", "build-cache:def789", "build-cache:shared", "build-cache:internal"},
+		},
+		{
+			doc:      "unused-for",
+			rule:     Rule{Filters: filters.NewArgs(filters.Arg("unused-for", "24h"))},
+			expected: []string{"build-cache:abc123", "build-cache:def789"},
+		},
+		{
+			doc:      "id",
+			rule:     Rule{Filters: filters.NewArgs(filters.Arg("id", "^abc"))},
+			expected: []string{"build-cache:abc123", "build-cache:abc456"},
+		},
+		{
+			doc:      "parent",
+			rule:     Rule{Filters: filters.NewArgs(filters.Arg("parent", "abc123"))},
+			expected: []string{"build-cache:abc456"},
+		},
+		{
+			doc:      "type and description",
+			rule:     Rule{Filters: filters.NewArgs(filters.Arg("type", "regular"), filters.Arg("description", "[1/2] FROM alpine"))},
+			expected: []string{"build-cache:abc123"},
+		},
+		{
+			doc:      "shared",
+			rule:     Rule{All: true, Filters: filters.NewArgs(filters.Arg("shared", "true"))},
+			expected: []string{"build-cache:shared"},
+		},
+		{
+			// The daemon ignores label filters for build cache.
+			doc:      "label",
+			rule:     Rule{Filters: filters.NewArgs(filters.Arg("label", "foo=bar"))},
+			expected: []string{"build-cache:abc123", "build-cache:abc456", "build-cache:def789"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			rule := tc.rule
+			candidates, err := Plan(context.Background(), apiClient, Policy{BuildCache: &rule})
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(ids(candidates), tc.expected))
+		})
+	}
+
+	_, err := Plan(context.Background(), apiClient, Policy{BuildCache: &Rule{Filters: filters.NewArgs(filters.Arg("until", "24h"), filters.Arg("unused-for", "24h"))}})
+	assert.Check(t, is.Error(err, `conflicting filters "until" and "unused-for"`))
+
+	_, err = Plan(context.Background(), apiClient, Policy{BuildCache: &Rule{Filters: filters.NewArgs(filters.Arg("until", "2025-01-01"))}})
+	assert.Check(t, is.ErrorContains(err, `"until" filter expects a duration (e.g., '24h')`))
+}
+
+func TestPlanBoolFilters(t *testing.T) {
+	apiClient := &fakeClient{diskUsage: testDiskUsage()}
+	candidates, err := Plan(context.Background(), apiClient, Policy{
+		Images:  &Rule{Filters: filters.NewArgs(filters.Arg("dangling", "false"), filters.Arg("until", "36h"))},
+		Volumes: &Rule{Filters: filters.NewArgs(filters.Arg("all", "true"))},
+	})
+	assert.NilError(t, err)
+	// The "anon" volume, and app:v1 image are in use by the exited container.
+	assert.Check(t, is.DeepEqual(ids(candidates), []string{
+		"volume:keep",
+		"volume:unused",
+		"image:sha256:app-v2",
+		"image:sha256:dangling",
+	}))
+
+	_, err = Plan(context.Background(), apiClient, Policy{Images: &Rule{Filters: filters.NewArgs(filters.Arg("dangling", "maybe"))}})
+	assert.Check(t, is.Error(err, "invalid filter 'dangling=maybe'"))
+}
+
+func TestPrune(t *testing.T) {
+	apiClient := &fakeClient{version: "1.47"}
+	reports, err := Prune(context.Background(), apiClient, Policy{
+		Containers: &Rule{Filters: filters.NewArgs(filters.Arg("until", "24h"))},
+		Networks:   &Rule{},
+		Volumes:    &Rule{All: true},
+		Images:     &Rule{All: true},
+		BuildCache: &Rule{},
+	}, nil)
+	assert.Check(t, is.Error(err, "failed to prune build-cache: build cache is unavailable"))
+
+	// The objects are selected by the daemon, and networks for which no
+	// objects were removed are omitted.
+	assert.Check(t, is.DeepEqual(reports, []Report{
+		{Type: TypeContainer, Deleted: []string{"exited"}, SpaceReclaimed: 100},
+		{Type: TypeVolume, Deleted: []string{"anon"}, SpaceReclaimed: 20},
+		{Type: TypeImage, Deleted: []string{"deleted: sha256:dangling"}, SpaceReclaimed: 10},
+	}))
+	assert.Check(t, is.DeepEqual(apiClient.pruned[TypeContainer].Get("until"), []string{"24h"}))
+	assert.Check(t, is.DeepEqual(apiClient.pruned[TypeVolume].Get("all"), []string{"true"}))
+	assert.Check(t, is.DeepEqual(apiClient.pruned[TypeImage].Get("dangling"), []string{"false"}))
+	assert.Check(t, is.Len(apiClient.removed, 0))
+}
+
+func TestPruneKeepLast(t *testing.T) {
+	apiClient := &fakeClient{version: "1.47"}
+	reports, err := Prune(context.Background(), apiClient, Policy{Images: &Rule{KeepLast: 1}}, []Candidate{
+		{Type: TypeContainer, ID: "exited"},
+		{Type: TypeImage, ID: "sha256:app", Size: 600, refs: []string{"app:v1", "app:latest"}},
+		{Type: TypeImage, ID: "sha256:dangling", Size: 10},
+	})
+	assert.NilError(t, err)
+
+	// The daemon cannot keep the most recent images, so the planned images
+	// are removed individually.
+	assert.Check(t, is.Len(apiClient.pruned, 0))
+	assert.Check(t, is.DeepEqual(apiClient.removed, []string{
+		"image:app:v1",
+		"image:app:latest",
+		"image:sha256:dangling",
+	}))
+	assert.Check(t, is.DeepEqual(reports, []Report{{
+		Type:           TypeImage,
+		Deleted:        []string{"untagged: app:v1", "untagged: app:latest", "deleted: sha256:dangling"},
+		SpaceReclaimed: 610,
+	}}))
+}
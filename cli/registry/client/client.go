@@ -3,6 +3,7 @@
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -12,6 +13,7 @@
 	"github.com/docker/distribution"
 	distributionclient "github.com/docker/distribution/registry/client"
 	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/registry"
 	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -24,6 +26,7 @@ type RegistryClient interface {
 	GetManifestList(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error)
 	MountBlob(ctx context.Context, source reference.Canonical, target reference.Named) error
 	PutManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error)
+	GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error)
 }
 
 // NewRegistryClient returns a new RegistryClient with a resolver
@@ -35,8 +38,9 @@ func NewRegistryClient(resolver AuthConfigResolver, userAgent string, insecure b
 	}
 }
 
-// AuthConfigResolver returns Auth Configuration for an index
-type AuthConfigResolver func(ctx context.Context, index *registrytypes.IndexInfo) registrytypes.AuthConfig
+// AuthConfigResolver returns Auth Configuration for a repository. The
+// repository's index is available through repoInfo.Index.
+type AuthConfigResolver func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig
 
 // PutManifestOptions is the data sent to push a manifest
 type PutManifestOptions struct {
@@ -151,7 +155,7 @@ func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Na
 
 func (c *client) getHTTPTransportForRepoEndpoint(ctx context.Context, repoEndpoint repositoryEndpoint) (http.RoundTripper, error) {
 	httpTransport, err := getHTTPTransport(
-		c.authConfigResolver(ctx, repoEndpoint.info.Index),
+		c.authConfigResolver(ctx, repoEndpoint.info),
 		repoEndpoint.endpoint,
 		repoEndpoint.Name(),
 		c.userAgent,
@@ -186,6 +190,33 @@ func (c *client) GetManifestList(ctx context.Context, ref reference.Named) ([]ma
 	return result, err
 }
 
+// GetBlob returns a reader for the content of a blob in the repository of
+// the reference. The content is not verified against the digest; callers
+// must verify it while reading.
+func (c *client) GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+	var result io.ReadCloser
+	fetch := func(ctx context.Context, repo distribution.Repository, ref reference.Named) (bool, error) {
+		blobs := repo.Blobs(ctx)
+		if _, err := blobs.Stat(ctx, dgst); err != nil {
+			if errors.Is(err, distribution.ErrBlobUnknown) {
+				return false, nil
+			}
+			return false, err
+		}
+		rc, err := blobs.Open(ctx, dgst)
+		if err != nil {
+			return false, err
+		}
+		result = rc
+		return true, nil
+	}
+
+	if err := c.iterateEndpoints(ctx, ref, fetch); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func getManifestOptionsFromReference(ref reference.Named) (digest.Digest, []distribution.ManifestServiceOption, error) {
 	if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
 		tag := tagged.Tag()
@@ -300,6 +300,19 @@ type ImageRefAndAuth struct {
 func GetImageReferencesAndAuth(ctx context.Context,
 	authResolver func(ctx context.Context, index *registrytypes.IndexInfo) registrytypes.AuthConfig,
 	imgName string,
+) (ImageRefAndAuth, error) {
+	return GetImageReferencesAndRepositoryAuth(ctx, func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig {
+		return authResolver(ctx, repoInfo.Index)
+	}, imgName)
+}
+
+// GetImageReferencesAndRepositoryAuth is like [GetImageReferencesAndAuth], but
+// resolves the auth information for the repository of the image, instead of
+// its registry, so that credentials that are scoped to a repository path can
+// be used.
+func GetImageReferencesAndRepositoryAuth(ctx context.Context,
+	authResolver func(ctx context.Context, repoInfo *registry.RepositoryInfo) registrytypes.AuthConfig,
+	imgName string,
 ) (ImageRefAndAuth, error) {
 	ref, err := reference.ParseNormalizedNamed(imgName)
 	if err != nil {
@@ -312,7 +325,7 @@ func GetImageReferencesAndAuth(ctx context.Context,
 		return ImageRefAndAuth{}, err
 	}
 
-	authConfig := authResolver(ctx, repoInfo.Index)
+	authConfig := authResolver(ctx, repoInfo)
 	return ImageRefAndAuth{
 		original:   imgName,
 		authConfig: &authConfig,
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/docker/cli/cli"
 	pluginmanager "github.com/docker/cli/cli-plugins/manager"
 	"github.com/docker/cli/cli/command"
+	"github.com/google/shlex"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -14,19 +19,28 @@
 	keyBuilderAlias = "builder"
 )
 
-var allowedAliases = map[string]struct{}{
-	keyBuilderAlias: {},
-}
-
 func processAliases(dockerCli command.Cli, cmd *cobra.Command, args, osArgs []string) ([]string, []string, []string, error) {
 	var err error
 	var envs []string
 	aliasMap := dockerCli.ConfigFile().Aliases
 	aliases := make([][2][]string, 0, len(aliasMap))
+	userAliases := make(map[string]string, len(aliasMap))
+
+	names := make([]string, 0, len(aliasMap))
+	for k := range aliasMap {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 
-	for k, v := range aliasMap {
-		if _, ok := allowedAliases[k]; !ok {
-			return args, osArgs, envs, errors.Errorf("not allowed to alias %q (allowed: %#v)", k, allowedAliases)
+	for _, k := range names {
+		v := aliasMap[k]
+		if k != keyBuilderAlias {
+			if err := validateUserAlias(cmd, k, v); err != nil {
+				_, _ = fmt.Fprintf(dockerCli.Err(), "WARNING: ignoring alias %q: %v\n", k, err)
+				continue
+			}
+			userAliases[k] = v
+			continue
 		}
 		if c, _, err := cmd.Find(strings.Split(v, " ")); err == nil {
 			if !pluginmanager.IsPluginCommand(c) {
@@ -36,7 +50,13 @@ func processAliases(dockerCli command.Cli, cmd *cobra.Command, args, osArgs []st
 		aliases = append(aliases, [2][]string{{k}, {v}})
 	}
 
-	args, osArgs, envs, err = processBuilder(dockerCli, cmd, args, os.Args)
+	addUserAliasCommands(cmd, userAliases)
+	args, osArgs, err = expandUserAliases(userAliases, args, osArgs)
+	if err != nil {
+		return args, osArgs, envs, err
+	}
+
+	args, osArgs, envs, err = processBuilder(dockerCli, cmd, args, osArgs)
 	if err != nil {
 		return args, os.Args, envs, err
 	}
@@ -52,3 +72,175 @@ func processAliases(dockerCli command.Cli, cmd *cobra.Command, args, osArgs []st
 
 	return args, osArgs, envs, nil
 }
+
+// validateUserAlias validates a user-defined alias. Aliases cannot shadow
+// builtin commands.
+func validateUserAlias(cmd *cobra.Command, name, value string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+		return errors.Errorf("invalid alias name %q", name)
+	}
+	if c, _, err := cmd.Find([]string{name}); err == nil && c != cmd && !pluginmanager.IsPluginCommand(c) {
+		return errors.Errorf("not allowed to alias builtin command %q", name)
+	}
+	if strings.TrimSpace(value) == "" {
+		return errors.Errorf("invalid alias %q: no command specified", name)
+	}
+	return nil
+}
+
+// expandUserAliases expands the user-defined alias in the command position of
+// args (and the corresponding osArgs), including aliases that expand to other
+// aliases. When completing commands, the alias is only expanded if it is not
+// the word that is being completed, and does not use placeholders.
+func expandUserAliases(aliases map[string]string, args, osArgs []string) ([]string, []string, error) {
+	if len(aliases) == 0 || len(args) == 0 {
+		return args, osArgs, nil
+	}
+	pos := 0
+	completing := args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd
+	if completing {
+		pos = 1
+		if len(args) < 3 {
+			return args, osArgs, nil
+		}
+	}
+
+	expanded := args[pos:]
+	var seen []string
+	for {
+		value, ok := aliases[expanded[0]]
+		if !ok {
+			break
+		}
+		for _, name := range seen {
+			if name == expanded[0] {
+				return args, osArgs, errors.Errorf("alias loop detected: %s -> %s", strings.Join(seen, " -> "), name)
+			}
+		}
+		seen = append(seen, expanded[0])
+		words, err := shlex.Split(value)
+		if err != nil {
+			return args, osArgs, errors.Wrapf(err, "invalid alias %q", expanded[0])
+		}
+		if completing && hasPlaceholders(words) {
+			return args, osArgs, nil
+		}
+		expanded, err = expandAlias(expanded[0], words, expanded[1:])
+		if err != nil {
+			return args, osArgs, err
+		}
+		if len(expanded) == 0 {
+			return args, osArgs, errors.Errorf("invalid alias %q: no command specified", seen[len(seen)-1])
+		}
+	}
+	if len(seen) == 0 {
+		return args, osArgs, nil
+	}
+
+	newArgs := append(append([]string{}, args[:pos]...), expanded...)
+
+	// args are the trailing arguments of osArgs, after the global options.
+	if n := len(osArgs) - len(args); n >= 0 && isSuffix(osArgs, args) {
+		osArgs = append(append([]string{}, osArgs[:n]...), newArgs...)
+	}
+	return newArgs, osArgs, nil
+}
+
+// expandAlias expands the placeholders in the words of an alias with the
+// given arguments. "$1" to "$9" are replaced with the corresponding argument,
+// "$@" with all arguments, and "$$" with a literal "$". If the alias has no
+// placeholders, the arguments are appended.
+func expandAlias(name string, words, args []string) ([]string, error) {
+	if !hasPlaceholders(words) {
+		return append(append([]string{}, words...), args...), nil
+	}
+	var result []string
+	for _, w := range words {
+		if w == "$@" {
+			result = append(result, args...)
+			continue
+		}
+		var sb strings.Builder
+		for i := 0; i < len(w); i++ {
+			if w[i] != '$' || i == len(w)-1 {
+				sb.WriteByte(w[i])
+				continue
+			}
+			switch c := w[i+1]; {
+			case c == '$':
+				sb.WriteByte('$')
+			case c == '@':
+				sb.WriteString(strings.Join(args, " "))
+			case c >= '1' && c <= '9':
+				n, _ := strconv.Atoi(string(c))
+				if n > len(args) {
+					return nil, errors.Errorf("alias %q requires at least %d argument(s)", name, n)
+				}
+				sb.WriteString(args[n-1])
+			default:
+				sb.WriteByte('$')
+				continue
+			}
+			i++
+		}
+		result = append(result, sb.String())
+	}
+	return result, nil
+}
+
+func hasPlaceholders(words []string) bool {
+	for _, w := range words {
+		for i := 0; i < len(w)-1; i++ {
+			if w[i] != '$' {
+				continue
+			}
+			if c := w[i+1]; c == '@' || (c >= '1' && c <= '9') {
+				return true
+			}
+			if w[i+1] == '$' {
+				i++
+			}
+		}
+	}
+	return false
+}
+
+func isSuffix(s, suffix []string) bool {
+	if len(suffix) > len(s) {
+		return false
+	}
+	for i, v := range suffix {
+		if s[len(s)-len(suffix)+i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// addUserAliasCommands adds a command stub for each user-defined alias, so
+// that aliases are shown in the help output, and can be completed.
+func addUserAliasCommands(cmd *cobra.Command, aliases map[string]string) {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c, _, err := cmd.Find([]string{name}); err == nil && c != cmd {
+			// Aliases of CLI plugins that were already added.
+			continue
+		}
+		cmd.AddCommand(&cobra.Command{
+			Use:                name,
+			Short:              "Alias for \"" + aliases[name] + "\"",
+			DisableFlagParsing: true,
+			Annotations: map[string]string{
+				cli.UserAliasAnnotation: aliases[name],
+			},
+			RunE: func(c *cobra.Command, _ []string) error {
+				// Aliases are expanded before running commands.
+				return errors.Errorf("alias %q was not expanded", c.Name())
+			},
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/flags"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestExpandUserAliases(t *testing.T) {
+	aliases := map[string]string{
+		"lsr":    `container ls --filter status=running --format '{{.Names}}'`,
+		"sh":     `exec -it $1 sh -c "$2"`,
+		"logsf":  `logs --follow $@ --tail 10`,
+		"env":    `run --rm -e NAME=$1 -e PRICE=$$1 alpine env`,
+		"r":      "lsr",
+		"loop-a": "loop-b",
+		"loop-b": "loop-a --all",
+	}
+	testCases := []struct {
+		doc            string
+		args           []string
+		osArgs         []string
+		expectedArgs   []string
+		expectedOsArgs []string
+		expectedErr    string
+	}{
+		{
+			doc:            "no alias",
+			args:           []string{"ps", "-a"},
+			osArgs:         []string{"docker", "ps", "-a"},
+			expectedArgs:   []string{"ps", "-a"},
+			expectedOsArgs: []string{"docker", "ps", "-a"},
+		},
+		{
+			doc:            "arguments are appended",
+			args:           []string{"lsr", "--no-trunc"},
+			osArgs:         []string{"docker", "--debug", "lsr", "--no-trunc"},
+			expectedArgs:   []string{"container", "ls", "--filter", "status=running", "--format", "{{.Names}}", "--no-trunc"},
+			expectedOsArgs: []string{"docker", "--debug", "container", "ls", "--filter", "status=running", "--format", "{{.Names}}", "--no-trunc"},
+		},
+		{
+			doc:            "positional placeholders",
+			args:           []string{"sh", "web", "echo hello"},
+			osArgs:         []string{"docker", "sh", "web", "echo hello"},
+			expectedArgs:   []string{"exec", "-it", "web", "sh", "-c", "echo hello"},
+			expectedOsArgs: []string{"docker", "exec", "-it", "web", "sh", "-c", "echo hello"},
+		},
+		{
+			doc:            "all arguments",
+			args:           []string{"logsf", "web", "db"},
+			osArgs:         []string{"docker", "logsf", "web", "db"},
+			expectedArgs:   []string{"logs", "--follow", "web", "db", "--tail", "10"},
+			expectedOsArgs: []string{"docker", "logs", "--follow", "web", "db", "--tail", "10"},
+		},
+		{
+			doc:            "escaped dollar",
+			args:           []string{"env", "foo"},
+			osArgs:         []string{"docker", "env", "foo"},
+			expectedArgs:   []string{"run", "--rm", "-e", "NAME=foo", "-e", "PRICE=$1", "alpine", "env"},
+			expectedOsArgs: []string{"docker", "run", "--rm", "-e", "NAME=foo", "-e", "PRICE=$1", "alpine", "env"},
+		},
+		{
+			doc:            "alias of alias",
+			args:           []string{"r"},
+			osArgs:         []string{"docker", "r"},
+			expectedArgs:   []string{"container", "ls", "--filter", "status=running", "--format", "{{.Names}}"},
+			expectedOsArgs: []string{"docker", "container", "ls", "--filter", "status=running", "--format", "{{.Names}}"},
+		},
+		{
+			doc:            "completion",
+			args:           []string{"__complete", "lsr", "--"},
+			osArgs:         []string{"docker", "__complete", "lsr", "--"},
+			expectedArgs:   []string{"__complete", "container", "ls", "--filter", "status=running", "--format", "{{.Names}}", "--"},
+			expectedOsArgs: []string{"docker", "__complete", "container", "ls", "--filter", "status=running", "--format", "{{.Names}}", "--"},
+		},
+		{
+			doc:            "completion of alias name",
+			args:           []string{"__complete", "lsr"},
+			osArgs:         []string{"docker", "__complete", "lsr"},
+			expectedArgs:   []string{"__complete", "lsr"},
+			expectedOsArgs: []string{"docker", "__complete", "lsr"},
+		},
+		{
+			doc:            "completion with placeholders",
+			args:           []string{"__complete", "sh", ""},
+			osArgs:         []string{"docker", "__complete", "sh", ""},
+			expectedArgs:   []string{"__complete", "sh", ""},
+			expectedOsArgs: []string{"docker", "__complete", "sh", ""},
+		},
+		{
+			doc:         "missing argument",
+			args:        []string{"sh", "web"},
+			osArgs:      []string{"docker", "sh", "web"},
+			expectedErr: `alias "sh" requires at least 2 argument(s)`,
+		},
+		{
+			doc:         "loop",
+			args:        []string{"loop-a"},
+			osArgs:      []string{"docker", "loop-a"},
+			expectedErr: "alias loop detected: loop-a -> loop-b -> loop-a",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			args, osArgs, err := expandUserAliases(aliases, tc.args, tc.osArgs)
+			if tc.expectedErr != "" {
+				assert.Check(t, is.Error(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(args, tc.expectedArgs))
+			assert.Check(t, is.DeepEqual(osArgs, tc.expectedOsArgs))
+		})
+	}
+}
+
+func TestProcessAliases(t *testing.T) {
+	var b bytes.Buffer
+	dockerCli, err := command.NewDockerCli(
+		command.WithAPIClient(&fakeClient{}),
+		command.WithInputStream(discard),
+		command.WithCombinedStreams(&b),
+	)
+	assert.NilError(t, err)
+	assert.NilError(t, dockerCli.Initialize(flags.NewClientOptions()))
+
+	t.Run("user aliases", func(t *testing.T) {
+		dockerCli.ConfigFile().Aliases = map[string]string{"lsr": "container ls --filter status=running"}
+		tcmd := newDockerCommand(dockerCli)
+		tcmd.SetArgs([]string{"lsr", "-q"})
+		cmd, args, err := tcmd.HandleGlobalFlags()
+		assert.NilError(t, err)
+
+		args, _, _, err = processAliases(dockerCli, cmd, args, []string{"docker", "lsr", "-q"})
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(args, []string{"container", "ls", "--filter", "status=running", "-q"}))
+		assert.Check(t, is.Contains(cmd.UsageString(), "User Aliases:\n  lsr"))
+		assert.Check(t, is.Contains(cmd.UsageString(), `Alias for "container ls --filter status=running"`))
+	})
+
+	t.Run("builtin commands", func(t *testing.T) {
+		for _, name := range []string{"ps", "container", "ls"} {
+			b.Reset()
+			dockerCli.ConfigFile().Aliases = map[string]string{name: "image ls"}
+			tcmd := newDockerCommand(dockerCli)
+			tcmd.SetArgs([]string{name})
+			cmd, args, err := tcmd.HandleGlobalFlags()
+			assert.NilError(t, err)
+
+			args, _, _, err = processAliases(dockerCli, cmd, args, []string{"docker", name})
+			assert.NilError(t, err)
+			if name == "ls" {
+				// "ls" is not a top-level command.
+				assert.Check(t, is.DeepEqual(args, []string{"image", "ls"}))
+				assert.Check(t, is.Equal(b.String(), ""))
+				continue
+			}
+			assert.Check(t, is.DeepEqual(args, []string{name}))
+			assert.Check(t, is.Equal(b.String(), `WARNING: ignoring alias "`+name+`": not allowed to alias builtin command "`+name+`"`+"\n"))
+		}
+	})
+
+	t.Run("invalid aliases are ignored", func(t *testing.T) {
+		b.Reset()
+		dockerCli.ConfigFile().Aliases = map[string]string{
+			"lsr":   "container ls --filter status=running",
+			"empty": " ",
+		}
+		tcmd := newDockerCommand(dockerCli)
+		tcmd.SetArgs([]string{"lsr"})
+		cmd, args, err := tcmd.HandleGlobalFlags()
+		assert.NilError(t, err)
+
+		args, _, _, err = processAliases(dockerCli, cmd, args, []string{"docker", "lsr"})
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(args, []string{"container", "ls", "--filter", "status=running"}))
+		assert.Check(t, is.Equal(b.String(), `WARNING: ignoring alias "empty": invalid alias "empty": no command specified`+"\n"))
+	})
+}
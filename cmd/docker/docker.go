@@ -286,17 +286,48 @@ func setValidateArgs(dockerCli command.Cli, cmd *cobra.Command) {
 	})
 }
 
-func tryPluginRun(ctx context.Context, dockerCli command.Cli, cmd *cobra.Command, subcommand string, envs []string) error {
-	plugincmd, err := pluginmanager.PluginRunCommand(dockerCli, subcommand, cmd)
+// setPreRunHooks wraps the PersistentPreRunE of the root command to invoke
+// the pre-run hooks of plugins, once the flags of the command that is
+// executed have been parsed.
+func setPreRunHooks(dockerCli command.Cli, rootCmd *cobra.Command) {
+	ogPersistentPreRunE := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := ogPersistentPreRunE(cmd, args); err != nil {
+			return err
+		}
+		return pluginmanager.RunCLICommandPreRunHooks(cmd.Context(), dockerCli, rootCmd, cmd, args)
+	}
+}
+
+func tryPluginRun(ctx context.Context, dockerCli *command.DockerCli, cmd *cobra.Command, args []string, envs []string) error {
+	plugincmd, meta, err := pluginmanager.PluginRunCommandWithMetadata(dockerCli, args[0], cmd)
 	if err != nil {
 		return err
 	}
 
+	// Run the pre-run hooks once we know the plugin exists, so that they
+	// are not invoked for commands that are not found.
+	if dockerCli.HooksEnabled() {
+		hookEnvs, err := pluginmanager.RunPluginPreRunHooks(ctx, dockerCli, cmd, args)
+		if err != nil {
+			return err
+		}
+		envs = append(envs, hookEnvs...)
+	}
+
+	// Start a span for the plugin invocation, which plugins can request
+	// over the plugin socket to use as the parent of their own spans.
+	spanCtx, span := dockerCli.TracerProvider().Tracer("github.com/docker/cli").Start(ctx, "plugin "+args[0])
+	defer span.End()
+
 	// Establish the plugin socket, adding it to the environment under a
-	// well-known key if successful.
-	srv, err := socket.NewPluginServer(nil)
+	// well-known key if successful. Besides signalling termination, the
+	// socket is used by plugins to request services from the CLI.
+	// Requests are only accepted on connections that present the token.
+	token := socket.NewRPCToken()
+	srv, err := socket.NewPluginServer(socket.NewRPCHandler(spanCtx, token, pluginmanager.RPCMethods(dockerCli, args[0], meta.Permissions)))
 	if err == nil {
-		plugincmd.Env = append(plugincmd.Env, socket.EnvKey+"="+srv.Addr().String())
+		plugincmd.Env = append(plugincmd.Env, socket.EnvKey+"="+srv.Addr().String(), socket.TokenEnvKey+"="+token)
 	}
 	defer func() {
 		// Close the server when plugin execution is over, so that in case
@@ -421,6 +452,9 @@ func runDocker(ctx context.Context, dockerCli *command.DockerCli) error {
 		fmt.Fprint(dockerCli.Err(), "Warning: Unexpected OTEL error, metrics may not be flushed")
 	}
 
+	if dockerCli.HooksEnabled() {
+		setPreRunHooks(dockerCli, cmd)
+	}
 	dockerCli.InstrumentCobraCommands(ctx, cmd)
 
 	var envs []string
@@ -444,7 +478,7 @@ func runDocker(ctx context.Context, dockerCli *command.DockerCli) error {
 		ccmd, _, err := cmd.Find(args)
 		subCommand = ccmd
 		if err != nil || pluginmanager.IsPluginCommand(ccmd) {
-			err := tryPluginRun(ctx, dockerCli, cmd, args[0], envs)
+			err := tryPluginRun(ctx, dockerCli, cmd, args, envs)
 			if err == nil {
 				if dockerCli.HooksEnabled() && dockerCli.Out().IsTerminal() && ccmd != nil {
 					pluginmanager.RunPluginHooks(ctx, dockerCli, cmd, ccmd, args)
@@ -138,6 +138,11 @@ func (c *FakeCli) CurrentContext() string {
 	return c.currentContext
 }
 
+// EffectiveContext returns the cli context, as the fake cli does not fail over
+func (c *FakeCli) EffectiveContext() string {
+	return c.currentContext
+}
+
 // DockerEndpoint returns the current DockerEndpoint
 func (c *FakeCli) DockerEndpoint() docker.Endpoint {
 	return c.dockerEndpoint